@@ -0,0 +1,128 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/knoxai/gait/pkg/types"
+)
+
+// GetBisectStatus handles GET /api/bisect
+func (h *Handler) GetBisectStatus(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		h.writeErrorResponse(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	status, err := h.git(r).GetBisectStatus()
+	if err != nil {
+		h.writeGitError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	h.writeJSONResponse(w, status)
+}
+
+// BisectStart handles POST /api/bisect/start - {bad, good}
+func (h *Handler) BisectStart(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		h.writeErrorResponse(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req struct {
+		Bad  string   `json:"bad"`
+		Good []string `json:"good"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.writeErrorResponse(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	status, err := h.git(r).BisectStart(req.Bad, req.Good)
+	if err != nil {
+		h.writeGitError(w, err, http.StatusBadRequest)
+		return
+	}
+
+	h.writeJSONResponse(w, status)
+}
+
+// BisectMark handles POST /api/bisect/mark - {term: good|bad|skip, commit}
+func (h *Handler) BisectMark(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		h.writeErrorResponse(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req struct {
+		Term   string `json:"term"`
+		Commit string `json:"commit"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.writeErrorResponse(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	status, err := h.git(r).BisectMark(req.Term, req.Commit)
+	if err != nil {
+		h.writeGitError(w, err, http.StatusBadRequest)
+		return
+	}
+
+	h.writeJSONResponse(w, status)
+}
+
+// BisectReset handles POST /api/bisect/reset
+func (h *Handler) BisectReset(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		h.writeErrorResponse(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if err := h.git(r).BisectReset(); err != nil {
+		h.writeGitError(w, err, http.StatusBadRequest)
+		return
+	}
+
+	h.writeJSONResponse(w, map[string]string{"status": "success"})
+}
+
+// GetBisectLog handles GET /api/bisect/log
+func (h *Handler) GetBisectLog(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		h.writeErrorResponse(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	log, err := h.git(r).GetBisectLog()
+	if err != nil {
+		h.writeGitError(w, err, http.StatusBadRequest)
+		return
+	}
+
+	h.writeJSONResponse(w, map[string]string{"log": log})
+}
+
+// StartBisectRunJob handles POST /api/jobs/bisect-run - {command}. Output lines are
+// streamed as job progress messages; the result is the final bisect status.
+func (h *Handler) StartBisectRunJob(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		h.writeErrorResponse(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req struct {
+		Command string `json:"command"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Command == "" {
+		h.writeErrorResponse(w, "Test command is required", http.StatusBadRequest)
+		return
+	}
+
+	service := h.git(r)
+	h.startJob(w, r, "bisect-run", func(ctx context.Context, progress func(types.JobProgress)) (interface{}, error) {
+		return service.BisectRun(ctx, req.Command, progress)
+	})
+}
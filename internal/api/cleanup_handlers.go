@@ -0,0 +1,62 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/knoxai/gait/pkg/types"
+)
+
+// AnalyzeBranchCleanup handles GET /api/branches/cleanup?base=&staleDays=&remotes=true
+func (h *Handler) AnalyzeBranchCleanup(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		h.writeErrorResponse(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	staleDays := 0
+	if d := r.URL.Query().Get("staleDays"); d != "" {
+		if parsed, err := strconv.Atoi(d); err == nil {
+			staleDays = parsed
+		}
+	}
+	includeRemotes := r.URL.Query().Get("remotes") == "true"
+
+	report, err := h.git(r).AnalyzeBranches(r.URL.Query().Get("base"), staleDays, includeRemotes)
+	if err != nil {
+		h.writeGitError(w, err, http.StatusBadRequest)
+		return
+	}
+
+	h.writeJSONResponse(w, report)
+}
+
+// CleanupBranches handles POST /api/branches/cleanup - bulk delete with optional dry run
+func (h *Handler) CleanupBranches(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		h.writeErrorResponse(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req struct {
+		Branches []types.BranchRef `json:"branches"`
+		Force    bool              `json:"force"`
+		DryRun   bool              `json:"dryRun"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.writeErrorResponse(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	if len(req.Branches) == 0 {
+		h.writeErrorResponse(w, "At least one branch is required", http.StatusBadRequest)
+		return
+	}
+
+	results := h.git(r).DeleteBranches(req.Branches, req.Force, req.DryRun)
+	h.writeJSONResponse(w, map[string]interface{}{
+		"dryRun":  req.DryRun,
+		"results": results,
+	})
+}
@@ -0,0 +1,117 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/knoxai/gait/pkg/types"
+)
+
+// GetCloneInfo handles GET /api/clone-info - whether the repository is shallow, partial,
+// single-branch or sparse
+func (h *Handler) GetCloneInfo(w http.ResponseWriter, r *http.Request) {
+	info, err := h.git(r).GetCloneInfo()
+	if err != nil {
+		h.writeGitError(w, err, http.StatusInternalServerError)
+		return
+	}
+	h.writeJSONResponse(w, info)
+}
+
+// GetSparseCheckout handles GET /api/sparse-checkout
+func (h *Handler) GetSparseCheckout(w http.ResponseWriter, r *http.Request) {
+	sparse, err := h.git(r).GetSparseCheckout()
+	if err != nil {
+		h.writeGitError(w, err, http.StatusInternalServerError)
+		return
+	}
+	h.writeJSONResponse(w, sparse)
+}
+
+// SetSparseCheckout handles PUT /api/sparse-checkout - {patterns, cone} replaces the
+// checked out patterns; cone defaults to true
+func (h *Handler) SetSparseCheckout(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Patterns []string `json:"patterns"`
+		Cone     *bool    `json:"cone"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.writeErrorResponse(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	cone := req.Cone == nil || *req.Cone
+	sparse, err := h.git(r).SetSparseCheckout(req.Patterns, cone)
+	if err != nil {
+		h.writeGitError(w, err, http.StatusBadRequest)
+		return
+	}
+	h.writeJSONResponse(w, sparse)
+}
+
+// AddSparseCheckout handles POST /api/sparse-checkout/add - {patterns}
+func (h *Handler) AddSparseCheckout(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Patterns []string `json:"patterns"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.writeErrorResponse(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	sparse, err := h.git(r).AddSparseCheckout(req.Patterns)
+	if err != nil {
+		h.writeGitError(w, err, http.StatusBadRequest)
+		return
+	}
+	h.writeJSONResponse(w, sparse)
+}
+
+// DisableSparseCheckout handles DELETE /api/sparse-checkout - check out the whole tree
+func (h *Handler) DisableSparseCheckout(w http.ResponseWriter, r *http.Request) {
+	sparse, err := h.git(r).DisableSparseCheckout()
+	if err != nil {
+		h.writeGitError(w, err, http.StatusBadRequest)
+		return
+	}
+	h.writeJSONResponse(w, sparse)
+}
+
+// deepenRequest is the body of the deepen endpoints
+type deepenRequest struct {
+	Remote    string `json:"remote"`
+	Depth     int    `json:"depth"`
+	Unshallow bool   `json:"unshallow"`
+}
+
+// Deepen handles POST /api/deepen - {remote, depth, unshallow} fetches more history into
+// a shallow clone
+func (h *Handler) Deepen(w http.ResponseWriter, r *http.Request) {
+	var req deepenRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.writeErrorResponse(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	result, err := h.git(r).DeepenWithProgress(r.Context(), req.Remote, req.Depth, req.Unshallow, nil)
+	if err != nil {
+		h.writeGitError(w, err, http.StatusBadRequest)
+		return
+	}
+	h.writeJSONResponse(w, result)
+}
+
+// StartDeepenJob handles POST /api/jobs/deepen
+func (h *Handler) StartDeepenJob(w http.ResponseWriter, r *http.Request) {
+	var req deepenRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.writeErrorResponse(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	service := h.git(r)
+	h.startJob(w, r, "deepen", func(ctx context.Context, progress func(types.JobProgress)) (interface{}, error) {
+		return service.DeepenWithProgress(ctx, req.Remote, req.Depth, req.Unshallow, progress)
+	})
+}
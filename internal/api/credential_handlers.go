@@ -0,0 +1,76 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/knoxai/gait/pkg/types"
+)
+
+// writeRemoteError writes the error of a remote operation; see writeGitError
+func (h *Handler) writeRemoteError(w http.ResponseWriter, err error) {
+	h.writeGitError(w, err, http.StatusInternalServerError)
+}
+
+// GetCredentials handles GET /api/credentials - stored credentials without secrets
+func (h *Handler) GetCredentials(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		h.writeErrorResponse(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	credentials, err := h.git(r).ListCredentials()
+	if err != nil {
+		h.writeGitError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	h.writeJSONResponse(w, credentials)
+}
+
+// SaveCredential handles PUT /api/credentials/{remote}
+func (h *Handler) SaveCredential(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "PUT" {
+		h.writeErrorResponse(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req struct {
+		Username   string `json:"username"`
+		Token      string `json:"token"`
+		SSHKeyPath string `json:"sshKeyPath"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.writeErrorResponse(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	credential := types.RemoteCredential{
+		Remote:     mux.Vars(r)["remote"],
+		Username:   req.Username,
+		Token:      req.Token,
+		SSHKeyPath: req.SSHKeyPath,
+	}
+	if err := h.git(r).SaveCredential(credential); err != nil {
+		h.writeGitError(w, err, http.StatusBadRequest)
+		return
+	}
+
+	h.writeJSONResponse(w, map[string]string{"status": "success"})
+}
+
+// DeleteCredential handles DELETE /api/credentials/{remote}
+func (h *Handler) DeleteCredential(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "DELETE" {
+		h.writeErrorResponse(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if err := h.git(r).DeleteCredential(mux.Vars(r)["remote"]); err != nil {
+		h.writeGitError(w, err, http.StatusNotFound)
+		return
+	}
+
+	h.writeJSONResponse(w, map[string]string{"status": "success"})
+}
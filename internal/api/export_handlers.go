@@ -0,0 +1,142 @@
+package api
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/knoxai/gait/internal/git"
+)
+
+// maxBundleUploadSize limits the size of uploaded bundles
+const maxBundleUploadSize = 1 << 30
+
+// exportFilename builds a download filename from the repository name and ref
+func (h *Handler) exportFilename(service *git.Service, ref string, ext string) string {
+	safeRef := strings.NewReplacer("/", "-", "^", "-", "~", "-", ":", "-").Replace(ref)
+	return fmt.Sprintf("%s-%s.%s", filepath.Base(service.GetRepoPath()), safeRef, ext)
+}
+
+// ExportBundle handles GET /api/export/bundle?ref=<branch|tag|commit>
+func (h *Handler) ExportBundle(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		h.writeErrorResponse(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	ref := r.URL.Query().Get("ref")
+	if ref == "" {
+		h.writeErrorResponse(w, "Ref is required", http.StatusBadRequest)
+		return
+	}
+	if _, err := h.git(r).ResolveExportRef(ref); err != nil {
+		h.writeGitError(w, err, http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/x-git-bundle")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", h.exportFilename(h.git(r), ref, "bundle")))
+	if err := h.git(r).WriteBundle(w, ref); err != nil {
+		// Headers are already sent, so the failure can only be logged
+		log.Printf("Bundle export of %s failed: %v", ref, err)
+	}
+}
+
+// ExportArchive handles GET /api/export/archive?ref=<ref>&format=tar.gz|zip&path=<path>
+func (h *Handler) ExportArchive(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		h.writeErrorResponse(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	query := r.URL.Query()
+	ref := query.Get("ref")
+	if ref == "" {
+		h.writeErrorResponse(w, "Ref is required", http.StatusBadRequest)
+		return
+	}
+	if _, err := h.git(r).ResolveExportRef(ref); err != nil {
+		h.writeGitError(w, err, http.StatusNotFound)
+		return
+	}
+
+	format := query.Get("format")
+	contentType := ""
+	switch format {
+	case "", "tar.gz":
+		format = "tar.gz"
+		contentType = "application/gzip"
+	case "zip":
+		contentType = "application/zip"
+	default:
+		h.writeErrorResponse(w, "Format must be tar.gz or zip", http.StatusBadRequest)
+		return
+	}
+
+	filename := h.exportFilename(h.git(r), ref, format)
+	prefix := strings.TrimSuffix(filename, "."+format)
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	if err := h.git(r).WriteArchive(w, ref, format, prefix, query["path"]); err != nil {
+		log.Printf("Archive export of %s failed: %v", ref, err)
+	}
+}
+
+// ImportBundle handles POST /api/import/bundle?remote=<name>
+// The bundle is read from a multipart "bundle" file field or from the raw request body.
+func (h *Handler) ImportBundle(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		h.writeErrorResponse(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxBundleUploadSize)
+
+	var body io.Reader = r.Body
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		file, _, err := r.FormFile("bundle")
+		if err != nil {
+			h.writeErrorResponse(w, "Bundle file is required", http.StatusBadRequest)
+			return
+		}
+		defer file.Close()
+		body = file
+	}
+
+	tmpFile, err := os.CreateTemp("", "gait-import-*.bundle")
+	if err != nil {
+		h.writeGitError(w, err, http.StatusInternalServerError)
+		return
+	}
+	defer os.Remove(tmpFile.Name())
+
+	_, err = io.Copy(tmpFile, body)
+	tmpFile.Close()
+	if err != nil {
+		h.writeErrorResponse(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	verification, err := h.git(r).VerifyBundle(tmpFile.Name())
+	if err != nil {
+		h.writeGitError(w, err, http.StatusBadRequest)
+		return
+	}
+
+	heads, err := h.git(r).FetchFromBundle(tmpFile.Name(), r.URL.Query().Get("remote"))
+	if err != nil {
+		h.writeGitError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	h.writeJSONResponse(w, map[string]interface{}{
+		"status":       "success",
+		"verification": verification,
+		"heads":        heads,
+	})
+}
@@ -2,38 +2,37 @@ package api
 
 import (
 	"encoding/json"
-	"fmt"
+	"errors"
 	"net/http"
-	"os"
-	"path/filepath"
 	"strconv"
-	"strings"
 
 	"github.com/gorilla/mux"
 	"github.com/knoxai/gait/internal/git"
+	"github.com/knoxai/gait/internal/jobs"
 	"github.com/knoxai/gait/internal/web"
 	"github.com/knoxai/gait/pkg/types"
 )
 
 // Handler manages API endpoints
 type Handler struct {
-	gitService    *git.Service
-	repositories  []types.Repository
-	webServer     *web.Server
+	repositories *RepositoryManager
+	webServer    *web.Server
+	jobs         *jobs.Manager
 }
 
-// NewHandler creates a new API handler
-func NewHandler(gitService *git.Service, repositories []types.Repository, webServer *web.Server) *Handler {
+// NewHandler creates a new API handler. Each request operates on the repository
+// selected for it by RepositoryManager.SelectRepository.
+func NewHandler(repositories *RepositoryManager, webServer *web.Server) *Handler {
 	return &Handler{
-		gitService:   gitService,
 		repositories: repositories,
 		webServer:    webServer,
 	}
 }
 
-// SetGitService updates the git service (for repository switching)
-func (h *Handler) SetGitService(service *git.Service) {
-	h.gitService = service
+// git returns the git service of the repository selected for the request, or nil
+// if no repository is selected
+func (h *Handler) git(r *http.Request) *git.Service {
+	return GitServiceFromContext(r.Context())
 }
 
 // SetWebServer updates the web server reference
@@ -56,38 +55,57 @@ func (h *Handler) writeErrorResponse(w http.ResponseWriter, message string, code
 	json.NewEncoder(w).Encode(map[string]string{"error": message})
 }
 
-// GetRepositories handles GET /api/repositories
-func (h *Handler) GetRepositories(w http.ResponseWriter, r *http.Request) {
-	h.writeJSONResponse(w, h.repositories)
-}
-
-// SwitchRepository handles POST /api/repository/switch
-func (h *Handler) SwitchRepository(w http.ResponseWriter, r *http.Request) {
-	if r.Method != "POST" {
-		h.writeErrorResponse(w, "Method not allowed", http.StatusMethodNotAllowed)
-		return
+// writeGitError writes a git.Service error, using 400 for rejected paths and refs, 401
+// for authentication failures, 403 for operations refused on protected branches, 404
+// for missing refs and repositories, 409 for conflicts and 504 for timeouts, each with
+// a machine-readable code
+func (h *Handler) writeGitError(w http.ResponseWriter, err error, code int) {
+	var authErr *git.AuthError
+	var protectedErr *git.ProtectedBranchError
+	var inputErr *git.InvalidInputError
+	var commandErr *git.CommandError
+	body := map[string]string{"error": err.Error()}
+
+	switch {
+	case errors.As(err, &authErr):
+		code = http.StatusUnauthorized
+		body["code"] = authErr.ErrorCode()
+		body["remote"] = authErr.Remote
+	case errors.As(err, &protectedErr):
+		code = http.StatusForbidden
+		body["code"] = protectedErr.ErrorCode()
+		body["branch"] = protectedErr.Branch
+	case errors.As(err, &inputErr):
+		code = http.StatusBadRequest
+		body["code"] = inputErr.ErrorCode()
+		body["field"] = inputErr.Kind
+	case errors.As(err, &commandErr):
+		body["code"] = commandErr.ErrorCode()
+		switch commandErr.Kind {
+		case git.ErrTimeout:
+			code = http.StatusGatewayTimeout
+		case git.ErrNotARepository, git.ErrRefNotFound:
+			code = http.StatusNotFound
+		case git.ErrConflict:
+			code = http.StatusConflict
+		}
 	}
 
-	var req struct {
-		Path string `json:"path"`
-	}
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		h.writeErrorResponse(w, "Invalid request", http.StatusBadRequest)
-		return
-	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(body)
+}
 
-	// Validate repository path
-	if _, err := os.Stat(filepath.Join(req.Path, ".git")); os.IsNotExist(err) {
-		h.writeErrorResponse(w, "Not a Git repository", http.StatusBadRequest)
+// GetCommitsHTML handles GET /api/commits/html - Server-side rendered commits for better performance.
+// ?signature=signed|unsigned|verified|unverified filters the log by signature status.
+func (h *Handler) GetCommitsHTML(w http.ResponseWriter, r *http.Request) {
+	if h.git(r) == nil {
+		if h.webServer != nil {
+			h.webServer.ServeCommitList(w, r, []types.Commit{}, false, 0)
+		}
 		return
 	}
 
-	h.gitService = git.NewService(req.Path)
-	h.writeJSONResponse(w, map[string]string{"status": "success"})
-}
-
-// GetCommitsHTML handles GET /api/commits/html - Server-side rendered commits for better performance
-func (h *Handler) GetCommitsHTML(w http.ResponseWriter, r *http.Request) {
 	limit := 50
 	if l := r.URL.Query().Get("limit"); l != "" {
 		if parsed, err := strconv.Atoi(l); err == nil {
@@ -104,10 +122,11 @@ func (h *Handler) GetCommitsHTML(w http.ResponseWriter, r *http.Request) {
 
 	branch := r.URL.Query().Get("branch")
 	showAll := r.URL.Query().Get("all") == "true"
+	signature := r.URL.Query().Get("signature")
 
-	commits, err := h.gitService.GetCommitsWithOffset(limit, offset, branch, showAll)
+	commits, err := h.git(r).GetCommitsWithSignatureFilter(limit, offset, branch, showAll, signature)
 	if err != nil {
-		h.writeErrorResponse(w, err.Error(), http.StatusInternalServerError)
+		h.writeGitError(w, err, http.StatusBadRequest)
 		return
 	}
 
@@ -122,9 +141,9 @@ func (h *Handler) GetCommitsHTML(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-// GetCommits handles GET /api/commits
+// GetCommits handles GET /api/commits, optionally filtered with ?signature=
 func (h *Handler) GetCommits(w http.ResponseWriter, r *http.Request) {
-	if h.gitService == nil {
+	if h.git(r) == nil {
 		h.writeJSONResponse(w, []types.Commit{})
 		return
 	}
@@ -145,10 +164,11 @@ func (h *Handler) GetCommits(w http.ResponseWriter, r *http.Request) {
 
 	branch := r.URL.Query().Get("branch")
 	showAll := r.URL.Query().Get("all") == "true"
+	signature := r.URL.Query().Get("signature")
 
-	commits, err := h.gitService.GetCommitsWithOffset(limit, offset, branch, showAll)
+	commits, err := h.git(r).GetCommitsWithSignatureFilter(limit, offset, branch, showAll, signature)
 	if err != nil {
-		h.writeErrorResponse(w, err.Error(), http.StatusInternalServerError)
+		h.writeGitError(w, err, http.StatusBadRequest)
 		return
 	}
 
@@ -165,7 +185,7 @@ func (h *Handler) GetAllData(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// If no git service is available, return empty data
-	if h.gitService == nil {
+	if h.git(r) == nil {
 		response := map[string]interface{}{
 			"commits":            []types.Commit{},
 			"branches":           []types.Branch{},
@@ -207,7 +227,7 @@ func (h *Handler) GetAllData(w http.ResponseWriter, r *http.Request) {
 
 		// Launch concurrent fetches
 		go func() {
-			commits, err := h.gitService.GetCommitsWithOffset(limit, 0, "", false)
+			commits, err := h.git(r).GetCommitsWithOffset(limit, 0, "", false)
 			if err != nil {
 				errorChan <- err
 				return
@@ -216,7 +236,7 @@ func (h *Handler) GetAllData(w http.ResponseWriter, r *http.Request) {
 		}()
 
 		go func() {
-			branches, err := h.gitService.GetBranches()
+			branches, err := h.git(r).GetBranches()
 			if err != nil {
 				errorChan <- err
 				return
@@ -225,7 +245,7 @@ func (h *Handler) GetAllData(w http.ResponseWriter, r *http.Request) {
 		}()
 
 		go func() {
-			tags, err := h.gitService.GetTags()
+			tags, err := h.git(r).GetTags()
 			if err != nil {
 				errorChan <- err
 				return
@@ -234,7 +254,7 @@ func (h *Handler) GetAllData(w http.ResponseWriter, r *http.Request) {
 		}()
 
 		go func() {
-			stashes, err := h.gitService.GetStashes()
+			stashes, err := h.git(r).GetStashes()
 			if err != nil {
 				errorChan <- err
 				return
@@ -243,7 +263,7 @@ func (h *Handler) GetAllData(w http.ResponseWriter, r *http.Request) {
 		}()
 
 		go func() {
-			remotes, err := h.gitService.GetRemotes()
+			remotes, err := h.git(r).GetRemotes()
 			if err != nil {
 				errorChan <- err
 				return
@@ -252,7 +272,7 @@ func (h *Handler) GetAllData(w http.ResponseWriter, r *http.Request) {
 		}()
 
 		go func() {
-			uncommittedChanges, err := h.gitService.GetUncommittedChanges()
+			uncommittedChanges, err := h.git(r).GetUncommittedChanges()
 			if err != nil {
 				errorChan <- err
 				return
@@ -294,7 +314,7 @@ func (h *Handler) GetAllData(w http.ResponseWriter, r *http.Request) {
 
 	res := <-resultChan
 	if res.err != nil {
-		h.writeErrorResponse(w, res.err.Error(), http.StatusInternalServerError)
+		h.writeGitError(w, res.err, http.StatusInternalServerError)
 		return
 	}
 
@@ -314,14 +334,14 @@ func (h *Handler) GetAllData(w http.ResponseWriter, r *http.Request) {
 
 // GetBranches handles GET /api/branches
 func (h *Handler) GetBranches(w http.ResponseWriter, r *http.Request) {
-	if h.gitService == nil {
+	if h.git(r) == nil {
 		h.writeJSONResponse(w, []types.Branch{})
 		return
 	}
 
-	branches, err := h.gitService.GetBranches()
+	branches, err := h.git(r).GetBranches()
 	if err != nil {
-		h.writeErrorResponse(w, err.Error(), http.StatusInternalServerError)
+		h.writeGitError(w, err, http.StatusInternalServerError)
 		return
 	}
 
@@ -330,14 +350,14 @@ func (h *Handler) GetBranches(w http.ResponseWriter, r *http.Request) {
 
 // GetTags handles GET /api/tags
 func (h *Handler) GetTags(w http.ResponseWriter, r *http.Request) {
-	if h.gitService == nil {
+	if h.git(r) == nil {
 		h.writeJSONResponse(w, []types.Tag{})
 		return
 	}
 
-	tags, err := h.gitService.GetTags()
+	tags, err := h.git(r).GetTags()
 	if err != nil {
-		h.writeErrorResponse(w, err.Error(), http.StatusInternalServerError)
+		h.writeGitError(w, err, http.StatusInternalServerError)
 		return
 	}
 
@@ -346,14 +366,14 @@ func (h *Handler) GetTags(w http.ResponseWriter, r *http.Request) {
 
 // GetStashes handles GET /api/stashes
 func (h *Handler) GetStashes(w http.ResponseWriter, r *http.Request) {
-	if h.gitService == nil {
+	if h.git(r) == nil {
 		h.writeJSONResponse(w, []types.Stash{})
 		return
 	}
 
-	stashes, err := h.gitService.GetStashes()
+	stashes, err := h.git(r).GetStashes()
 	if err != nil {
-		h.writeErrorResponse(w, err.Error(), http.StatusInternalServerError)
+		h.writeGitError(w, err, http.StatusInternalServerError)
 		return
 	}
 
@@ -362,14 +382,14 @@ func (h *Handler) GetStashes(w http.ResponseWriter, r *http.Request) {
 
 // GetRemotes handles GET /api/remotes
 func (h *Handler) GetRemotes(w http.ResponseWriter, r *http.Request) {
-	if h.gitService == nil {
+	if h.git(r) == nil {
 		h.writeJSONResponse(w, []types.Remote{})
 		return
 	}
 
-	remotes, err := h.gitService.GetRemotes()
+	remotes, err := h.git(r).GetRemotes()
 	if err != nil {
-		h.writeErrorResponse(w, err.Error(), http.StatusInternalServerError)
+		h.writeGitError(w, err, http.StatusInternalServerError)
 		return
 	}
 
@@ -391,8 +411,8 @@ func (h *Handler) CheckoutBranch(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := h.gitService.CheckoutBranch(req.Branch); err != nil {
-		h.writeErrorResponse(w, err.Error(), http.StatusInternalServerError)
+	if err := h.git(r).CheckoutBranch(req.Branch); err != nil {
+		h.writeGitError(w, err, http.StatusInternalServerError)
 		return
 	}
 
@@ -420,8 +440,8 @@ func (h *Handler) CreateBranch(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := h.gitService.CreateBranch(req.BranchName, req.StartPoint); err != nil {
-		h.writeErrorResponse(w, err.Error(), http.StatusInternalServerError)
+	if err := h.git(r).CreateBranch(req.BranchName, req.StartPoint); err != nil {
+		h.writeGitError(w, err, http.StatusInternalServerError)
 		return
 	}
 
@@ -449,8 +469,8 @@ func (h *Handler) DeleteBranch(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := h.gitService.DeleteBranch(req.BranchName, req.Force); err != nil {
-		h.writeErrorResponse(w, err.Error(), http.StatusInternalServerError)
+	if err := h.git(r).DeleteBranch(req.BranchName, req.Force); err != nil {
+		h.writeGitError(w, err, http.StatusInternalServerError)
 		return
 	}
 
@@ -478,8 +498,8 @@ func (h *Handler) MergeBranch(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := h.gitService.MergeBranch(req.BranchName, req.NoFastForward); err != nil {
-		h.writeErrorResponse(w, err.Error(), http.StatusInternalServerError)
+	if err := h.git(r).MergeBranch(req.BranchName, req.NoFastForward); err != nil {
+		h.writeGitError(w, err, http.StatusInternalServerError)
 		return
 	}
 
@@ -499,8 +519,8 @@ func (h *Handler) Fetch(w http.ResponseWriter, r *http.Request) {
 	}
 	json.NewDecoder(r.Body).Decode(&req)
 
-	if err := h.gitService.Fetch(req.Remote, req.Prune); err != nil {
-		h.writeErrorResponse(w, err.Error(), http.StatusInternalServerError)
+	if err := h.git(r).Fetch(req.Remote, req.Prune); err != nil {
+		h.writeRemoteError(w, err)
 		return
 	}
 
@@ -510,9 +530,9 @@ func (h *Handler) Fetch(w http.ResponseWriter, r *http.Request) {
 // GetGait handles GET /api/gait
 func (h *Handler) GetGait(w http.ResponseWriter, r *http.Request) {
 	// Simple gait generation for now
-	commits, err := h.gitService.GetCommits(50, "", false)
+	commits, err := h.git(r).GetCommits(50, "", false)
 	if err != nil {
-		h.writeErrorResponse(w, err.Error(), http.StatusInternalServerError)
+		h.writeGitError(w, err, http.StatusInternalServerError)
 		return
 	}
 
@@ -573,49 +593,16 @@ func (h *Handler) Search(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	results := h.performSearch(req)
-	h.writeJSONResponse(w, results)
-}
-
-// performSearch performs a simple search in commit messages
-func (h *Handler) performSearch(req types.SearchRequest) []types.SearchResult {
-	commits, err := h.gitService.GetCommits(100, "", true)
-	if err != nil {
-		return []types.SearchResult{} // Return empty array on error
+	if h.git(r) == nil {
+		h.writeErrorResponse(w, "No repository selected", http.StatusBadRequest)
+		return
 	}
-
-	results := make([]types.SearchResult, 0)
-	query := strings.ToLower(req.Query)
-
-	for _, commit := range commits {
-		if req.Type == "message" || req.Type == "" {
-			if strings.Contains(strings.ToLower(commit.Message), query) {
-				results = append(results, types.SearchResult{
-					CommitHash: commit.Hash,
-					Type:       "message",
-					Match:      commit.Message,
-					Context:    commit.Message,
-				})
-			}
-		}
-
-		if req.Type == "author" || req.Type == "" {
-			if strings.Contains(strings.ToLower(commit.Author.Name), query) {
-				results = append(results, types.SearchResult{
-					CommitHash: commit.Hash,
-					Type:       "author",
-					Match:      commit.Author.Name,
-					Context:    fmt.Sprintf("%s: %s", commit.Author.Name, commit.Message),
-				})
-			}
-		}
-
-		if len(results) >= req.MaxResults && req.MaxResults > 0 {
-			break
-		}
+	results, err := h.git(r).Search(req)
+	if err != nil {
+		h.writeGitError(w, err, http.StatusBadRequest)
+		return
 	}
-
-	return results
+	h.writeJSONResponse(w, results)
 }
 
 // GetCommitDetails handles GET /api/commit/{hash}
@@ -628,9 +615,9 @@ func (h *Handler) GetCommitDetails(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	commit, err := h.gitService.GetCommitDetails(hash)
+	commit, err := h.git(r).GetCommitDetails(hash)
 	if err != nil {
-		h.writeErrorResponse(w, err.Error(), http.StatusNotFound)
+		h.writeGitError(w, err, http.StatusNotFound)
 		return
 	}
 
@@ -647,9 +634,9 @@ func (h *Handler) GetFileDiff(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	diff, err := h.gitService.GetFileDiff(hash, filePath)
+	diff, err := h.git(r).GetFileDiff(hash, filePath)
 	if err != nil {
-		h.writeErrorResponse(w, err.Error(), http.StatusInternalServerError)
+		h.writeGitError(w, err, http.StatusInternalServerError)
 		return
 	}
 
@@ -666,9 +653,9 @@ func (h *Handler) GetFileContent(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	content, err := h.gitService.GetFileContent(hash, filePath)
+	content, err := h.git(r).GetFileContent(hash, filePath)
 	if err != nil {
-		h.writeErrorResponse(w, err.Error(), http.StatusInternalServerError)
+		h.writeGitError(w, err, http.StatusInternalServerError)
 		return
 	}
 
@@ -702,8 +689,8 @@ func (h *Handler) SaveFileContent(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := h.gitService.SaveFileContent(req.FilePath, req.Content); err != nil {
-		h.writeErrorResponse(w, err.Error(), http.StatusInternalServerError)
+	if err := h.git(r).SaveFileContent(req.FilePath, req.Content); err != nil {
+		h.writeGitError(w, err, http.StatusInternalServerError)
 		return
 	}
 
@@ -734,9 +721,9 @@ func (h *Handler) GetCommitsByTag(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	commits, err := h.gitService.GetCommitsByTagWithOffset(tag, limit, offset)
+	commits, err := h.git(r).GetCommitsByTagWithOffset(tag, limit, offset)
 	if err != nil {
-		h.writeErrorResponse(w, err.Error(), http.StatusInternalServerError)
+		h.writeGitError(w, err, http.StatusInternalServerError)
 		return
 	}
 
@@ -767,9 +754,9 @@ func (h *Handler) GetCommitsByTagHTML(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	commits, err := h.gitService.GetCommitsByTagWithOffset(tag, limit, offset)
+	commits, err := h.git(r).GetCommitsByTagWithOffset(tag, limit, offset)
 	if err != nil {
-		h.writeErrorResponse(w, err.Error(), http.StatusInternalServerError)
+		h.writeGitError(w, err, http.StatusInternalServerError)
 		return
 	}
 
@@ -799,8 +786,8 @@ func (h *Handler) ApplyStash(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := h.gitService.ApplyStash(req.Index); err != nil {
-		h.writeErrorResponse(w, err.Error(), http.StatusInternalServerError)
+	if err := h.git(r).ApplyStash(req.Index); err != nil {
+		h.writeGitError(w, err, http.StatusInternalServerError)
 		return
 	}
 
@@ -822,8 +809,8 @@ func (h *Handler) PopStash(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := h.gitService.PopStash(req.Index); err != nil {
-		h.writeErrorResponse(w, err.Error(), http.StatusInternalServerError)
+	if err := h.git(r).PopStash(req.Index); err != nil {
+		h.writeGitError(w, err, http.StatusInternalServerError)
 		return
 	}
 
@@ -845,8 +832,8 @@ func (h *Handler) DropStash(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := h.gitService.DropStash(req.Index); err != nil {
-		h.writeErrorResponse(w, err.Error(), http.StatusInternalServerError)
+	if err := h.git(r).DropStash(req.Index); err != nil {
+		h.writeGitError(w, err, http.StatusInternalServerError)
 		return
 	}
 
@@ -869,9 +856,9 @@ func (h *Handler) ShowStash(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	stash, err := h.gitService.ShowStash(index)
+	stash, err := h.git(r).ShowStash(index)
 	if err != nil {
-		h.writeErrorResponse(w, err.Error(), http.StatusNotFound)
+		h.writeGitError(w, err, http.StatusNotFound)
 		return
 	}
 
@@ -891,8 +878,8 @@ func (h *Handler) PullFromRemote(w http.ResponseWriter, r *http.Request) {
 	}
 	json.NewDecoder(r.Body).Decode(&req)
 
-	if err := h.gitService.PullFromRemote(req.Remote, req.Branch); err != nil {
-		h.writeErrorResponse(w, err.Error(), http.StatusInternalServerError)
+	if err := h.git(r).PullFromRemote(req.Remote, req.Branch); err != nil {
+		h.writeRemoteError(w, err)
 		return
 	}
 
@@ -913,8 +900,8 @@ func (h *Handler) PushToRemote(w http.ResponseWriter, r *http.Request) {
 	}
 	json.NewDecoder(r.Body).Decode(&req)
 
-	if err := h.gitService.PushToRemote(req.Remote, req.Branch, req.Force); err != nil {
-		h.writeErrorResponse(w, err.Error(), http.StatusInternalServerError)
+	if err := h.git(r).PushToRemote(req.Remote, req.Branch, req.Force); err != nil {
+		h.writeRemoteError(w, err)
 		return
 	}
 
@@ -935,9 +922,9 @@ func (h *Handler) GetRemoteInfo(w http.ResponseWriter, r *http.Request) {
 		h.writeErrorResponse(w, "Remote name required", http.StatusBadRequest)
 		return
 	}
-	remote, err := h.gitService.GetRemoteInfo(remoteName)
+	remote, err := h.git(r).GetRemoteInfo(remoteName)
 	if err != nil {
-		h.writeErrorResponse(w, err.Error(), http.StatusInternalServerError)
+		h.writeRemoteError(w, err)
 		return
 	}
 
@@ -965,8 +952,8 @@ func (h *Handler) RenameBranch(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := h.gitService.RenameBranch(req.OldName, req.NewName); err != nil {
-		h.writeErrorResponse(w, err.Error(), http.StatusInternalServerError)
+	if err := h.git(r).RenameBranch(req.OldName, req.NewName); err != nil {
+		h.writeGitError(w, err, http.StatusInternalServerError)
 		return
 	}
 
@@ -993,8 +980,8 @@ func (h *Handler) CherryPickCommit(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := h.gitService.CherryPickCommit(req.CommitHash); err != nil {
-		h.writeErrorResponse(w, err.Error(), http.StatusInternalServerError)
+	if err := h.git(r).CherryPickCommit(req.CommitHash); err != nil {
+		h.writeGitError(w, err, http.StatusInternalServerError)
 		return
 	}
 
@@ -1022,8 +1009,8 @@ func (h *Handler) RevertCommit(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := h.gitService.RevertCommit(req.CommitHash, req.NoCommit); err != nil {
-		h.writeErrorResponse(w, err.Error(), http.StatusInternalServerError)
+	if err := h.git(r).RevertCommit(req.CommitHash, req.NoCommit); err != nil {
+		h.writeGitError(w, err, http.StatusInternalServerError)
 		return
 	}
 
@@ -1051,8 +1038,8 @@ func (h *Handler) ResetBranch(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := h.gitService.ResetBranch(req.CommitHash, req.ResetType); err != nil {
-		h.writeErrorResponse(w, err.Error(), http.StatusInternalServerError)
+	if err := h.git(r).ResetBranch(req.CommitHash, req.ResetType); err != nil {
+		h.writeGitError(w, err, http.StatusInternalServerError)
 		return
 	}
 
@@ -1080,14 +1067,38 @@ func (h *Handler) RebaseBranch(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := h.gitService.RebaseBranch(req.TargetBranch, req.Interactive); err != nil {
-		h.writeErrorResponse(w, err.Error(), http.StatusInternalServerError)
+	if err := h.git(r).RebaseBranch(req.TargetBranch, req.Interactive); err != nil {
+		h.writeGitError(w, err, http.StatusInternalServerError)
 		return
 	}
 
 	h.writeJSONResponse(w, map[string]string{"status": "success"})
 }
 
+// SyncBranch handles POST /api/branch/sync - pull (rebase or ff-only) then push the current branch
+func (h *Handler) SyncBranch(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		h.writeErrorResponse(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req struct {
+		Mode string `json:"mode"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.writeErrorResponse(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	result, err := h.git(r).SyncBranch(req.Mode)
+	if err != nil {
+		h.writeGitError(w, err, http.StatusBadRequest)
+		return
+	}
+
+	h.writeJSONResponse(w, result)
+}
+
 // CreateTag handles POST /api/tag/create
 func (h *Handler) CreateTag(w http.ResponseWriter, r *http.Request) {
 	if r.Method != "POST" {
@@ -1100,6 +1111,9 @@ func (h *Handler) CreateTag(w http.ResponseWriter, r *http.Request) {
 		CommitHash string `json:"commitHash"`
 		Message    string `json:"message"`
 		Annotated  bool   `json:"annotated"`
+		Sign       bool   `json:"sign"`
+		SigningKey string `json:"signingKey"`
+		Format     string `json:"format"` // gpg, ssh, x509
 	}
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		h.writeErrorResponse(w, "Invalid request", http.StatusBadRequest)
@@ -1111,8 +1125,14 @@ func (h *Handler) CreateTag(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := h.gitService.CreateTag(req.TagName, req.CommitHash, req.Message, req.Annotated); err != nil {
-		h.writeErrorResponse(w, err.Error(), http.StatusInternalServerError)
+	var err error
+	if req.Sign {
+		err = h.git(r).CreateSignedTag(req.TagName, req.CommitHash, req.Message, req.SigningKey, req.Format)
+	} else {
+		err = h.git(r).CreateTag(req.TagName, req.CommitHash, req.Message, req.Annotated)
+	}
+	if err != nil {
+		h.writeGitError(w, err, http.StatusInternalServerError)
 		return
 	}
 
@@ -1133,8 +1153,8 @@ func (h *Handler) DeleteTag(w http.ResponseWriter, r *http.Request) {
 		h.writeErrorResponse(w, "Tag name is required", http.StatusBadRequest)
 		return
 	}
-	if err := h.gitService.DeleteTag(tagName); err != nil {
-		h.writeErrorResponse(w, err.Error(), http.StatusInternalServerError)
+	if err := h.git(r).DeleteTag(tagName); err != nil {
+		h.writeGitError(w, err, http.StatusInternalServerError)
 		return
 	}
 
@@ -1164,17 +1184,17 @@ func (h *Handler) PushTag(w http.ResponseWriter, r *http.Request) {
 
 	var err error
 	if req.All {
-		err = h.gitService.PushAllTags(req.Remote)
+		err = h.git(r).PushAllTags(req.Remote)
 	} else {
 		if req.TagName == "" {
 			h.writeErrorResponse(w, "Tag name is required when not pushing all tags", http.StatusBadRequest)
 			return
 		}
-		err = h.gitService.PushTag(req.Remote, req.TagName)
+		err = h.git(r).PushTag(req.Remote, req.TagName)
 	}
 
 	if err != nil {
-		h.writeErrorResponse(w, err.Error(), http.StatusInternalServerError)
+		h.writeRemoteError(w, err)
 		return
 	}
 
@@ -1195,33 +1215,168 @@ func (h *Handler) GetTagDetails(w http.ResponseWriter, r *http.Request) {
 		h.writeErrorResponse(w, "Tag name is required", http.StatusBadRequest)
 		return
 	}
-	tag, err := h.gitService.GetAnnotatedTagDetails(tagName)
+	tag, err := h.git(r).GetAnnotatedTagDetails(tagName)
 	if err != nil {
-		h.writeErrorResponse(w, err.Error(), http.StatusInternalServerError)
+		h.writeGitError(w, err, http.StatusInternalServerError)
 		return
 	}
 
 	h.writeJSONResponse(w, tag)
 }
 
-// CreateStash handles POST /api/stash/create
-func (h *Handler) CreateStash(w http.ResponseWriter, r *http.Request) {
+// VerifyTag handles GET /api/tag/{tag}/verify
+func (h *Handler) VerifyTag(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		h.writeErrorResponse(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	tagName := mux.Vars(r)["tag"]
+	if tagName == "" {
+		h.writeErrorResponse(w, "Tag name is required", http.StatusBadRequest)
+		return
+	}
+
+	status, err := h.git(r).VerifyTag(tagName)
+	if err != nil {
+		h.writeGitError(w, err, http.StatusNotFound)
+		return
+	}
+
+	h.writeJSONResponse(w, status)
+}
+
+// VerifyCommit handles GET /api/commit/{hash}/verify
+func (h *Handler) VerifyCommit(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		h.writeErrorResponse(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	hash := mux.Vars(r)["hash"]
+	if hash == "" {
+		h.writeErrorResponse(w, "Commit hash is required", http.StatusBadRequest)
+		return
+	}
+
+	status, err := h.git(r).VerifyCommit(hash)
+	if err != nil {
+		h.writeGitError(w, err, http.StatusNotFound)
+		return
+	}
+
+	h.writeJSONResponse(w, status)
+}
+
+// SignatureConfig handles GET and POST /api/signature/config
+func (h *Handler) SignatureConfig(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case "GET":
+		config, err := h.git(r).LoadSignatureConfig()
+		if err != nil {
+			h.writeGitError(w, err, http.StatusInternalServerError)
+			return
+		}
+		h.writeJSONResponse(w, config)
+	case "POST":
+		var config git.SignatureConfig
+		if err := json.NewDecoder(r.Body).Decode(&config); err != nil {
+			h.writeErrorResponse(w, "Invalid request", http.StatusBadRequest)
+			return
+		}
+		if err := h.git(r).SaveSignatureConfig(&config); err != nil {
+			h.writeGitError(w, err, http.StatusBadRequest)
+			return
+		}
+		h.writeJSONResponse(w, map[string]string{"status": "success"})
+	default:
+		h.writeErrorResponse(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// GetReleaseNotes handles GET /api/tag/{tag}/release-notes?format=markdown
+func (h *Handler) GetReleaseNotes(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		h.writeErrorResponse(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	tagName := mux.Vars(r)["tag"]
+	if tagName == "" {
+		h.writeErrorResponse(w, "Tag name is required", http.StatusBadRequest)
+		return
+	}
+
+	notes, err := h.git(r).GenerateReleaseNotes(tagName)
+	if err != nil {
+		h.writeGitError(w, err, http.StatusNotFound)
+		return
+	}
+
+	if r.URL.Query().Get("format") == "markdown" {
+		w.Header().Set("Content-Type", "text/markdown; charset=utf-8")
+		w.Write([]byte(notes.Markdown))
+		return
+	}
+
+	h.writeJSONResponse(w, notes)
+}
+
+// SuggestVersion handles GET /api/version/suggest
+func (h *Handler) SuggestVersion(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		h.writeErrorResponse(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	suggestion, err := h.git(r).SuggestVersionBump()
+	if err != nil {
+		h.writeGitError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	h.writeJSONResponse(w, suggestion)
+}
+
+// CreateVersionTag handles POST /api/version/tag
+func (h *Handler) CreateVersionTag(w http.ResponseWriter, r *http.Request) {
 	if r.Method != "POST" {
 		h.writeErrorResponse(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
 
 	var req struct {
-		Message          string `json:"message"`
-		IncludeUntracked bool   `json:"includeUntracked"`
+		Bump string `json:"bump"` // major, minor, patch; empty uses the suggestion
 	}
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		h.writeErrorResponse(w, "Invalid request", http.StatusBadRequest)
 		return
 	}
 
-	if err := h.gitService.CreateStash(req.Message, req.IncludeUntracked); err != nil {
-		h.writeErrorResponse(w, err.Error(), http.StatusInternalServerError)
+	result, err := h.git(r).CreateVersionTag(req.Bump)
+	if err != nil {
+		h.writeGitError(w, err, http.StatusBadRequest)
+		return
+	}
+
+	h.writeJSONResponse(w, result)
+}
+
+// CreateStash handles POST /api/stash/create
+func (h *Handler) CreateStash(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		h.writeErrorResponse(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req git.StashOptions
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.writeErrorResponse(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.git(r).CreateStashWithOptions(req); err != nil {
+		h.writeGitError(w, err, http.StatusInternalServerError)
 		return
 	}
 
@@ -1249,8 +1404,8 @@ func (h *Handler) CreateBranchFromStash(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	if err := h.gitService.CreateBranchFromStash(req.BranchName, req.StashIndex); err != nil {
-		h.writeErrorResponse(w, err.Error(), http.StatusInternalServerError)
+	if err := h.git(r).CreateBranchFromStash(req.BranchName, req.StashIndex); err != nil {
+		h.writeGitError(w, err, http.StatusInternalServerError)
 		return
 	}
 
@@ -1273,9 +1428,9 @@ func (h *Handler) CleanWorkingDirectory(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	output, err := h.gitService.CleanWorkingDirectory(req.DryRun, req.IncludeDirectories)
+	output, err := h.git(r).CleanWorkingDirectory(req.DryRun, req.IncludeDirectories)
 	if err != nil {
-		h.writeErrorResponse(w, err.Error(), http.StatusInternalServerError)
+		h.writeGitError(w, err, http.StatusInternalServerError)
 		return
 	}
 
@@ -1292,14 +1447,14 @@ func (h *Handler) GetUncommittedChanges(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	if h.gitService == nil {
+	if h.git(r) == nil {
 		h.writeJSONResponse(w, []types.FileChange{})
 		return
 	}
 
-	changes, err := h.gitService.GetUncommittedChanges()
+	changes, err := h.git(r).GetUncommittedChanges()
 	if err != nil {
-		h.writeErrorResponse(w, err.Error(), http.StatusInternalServerError)
+		h.writeGitError(w, err, http.StatusInternalServerError)
 		return
 	}
 
@@ -1326,8 +1481,8 @@ func (h *Handler) StageFile(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := h.gitService.StageFile(req.FilePath); err != nil {
-		h.writeErrorResponse(w, err.Error(), http.StatusInternalServerError)
+	if err := h.git(r).StageFile(req.FilePath); err != nil {
+		h.writeGitError(w, err, http.StatusInternalServerError)
 		return
 	}
 
@@ -1354,8 +1509,8 @@ func (h *Handler) UnstageFile(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := h.gitService.UnstageFile(req.FilePath); err != nil {
-		h.writeErrorResponse(w, err.Error(), http.StatusInternalServerError)
+	if err := h.git(r).UnstageFile(req.FilePath); err != nil {
+		h.writeGitError(w, err, http.StatusInternalServerError)
 		return
 	}
 
@@ -1382,8 +1537,8 @@ func (h *Handler) DiscardFileChanges(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := h.gitService.DiscardFileChanges(req.FilePath); err != nil {
-		h.writeErrorResponse(w, err.Error(), http.StatusInternalServerError)
+	if err := h.git(r).DiscardFileChanges(req.FilePath); err != nil {
+		h.writeGitError(w, err, http.StatusInternalServerError)
 		return
 	}
 
@@ -1398,9 +1553,10 @@ func (h *Handler) CreateCommit(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var req struct {
-		Message string `json:"message"`
-		Amend   bool   `json:"amend"`
-		Signoff bool   `json:"signoff"`
+		Message  string `json:"message"`
+		Amend    bool   `json:"amend"`
+		Signoff  bool   `json:"signoff"`
+		Override bool   `json:"override"` // skip lint when the policy allows it
 	}
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		h.writeErrorResponse(w, "Invalid request", http.StatusBadRequest)
@@ -1412,9 +1568,24 @@ func (h *Handler) CreateCommit(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	commitHash, err := h.gitService.CreateCommit(req.Message)
+	commitHash, err := h.git(r).CreateCommitWithOptions(req.Message, git.CommitOptions{
+		Amend:    req.Amend,
+		Signoff:  req.Signoff,
+		SkipLint: req.Override,
+	})
 	if err != nil {
-		h.writeErrorResponse(w, err.Error(), http.StatusInternalServerError)
+		var lintErr *git.CommitLintError
+		if errors.As(err, &lintErr) {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"error":       "Commit message violates the commit policy",
+				"violations":  lintErr.Violations,
+				"overridable": lintErr.Overridable,
+			})
+			return
+		}
+		h.writeGitError(w, err, http.StatusInternalServerError)
 		return
 	}
 
@@ -1422,4 +1593,58 @@ func (h *Handler) CreateCommit(w http.ResponseWriter, r *http.Request) {
 		"status":     "success",
 		"commitHash": commitHash,
 	})
-} 
\ No newline at end of file
+}
+
+// LintCommitMessage handles POST /api/commit/lint
+func (h *Handler) LintCommitMessage(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		h.writeErrorResponse(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req struct {
+		Message string `json:"message"`
+		Signoff bool   `json:"signoff"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.writeErrorResponse(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	violations, err := h.git(r).LintCommitMessage(req.Message, req.Signoff)
+	if err != nil {
+		h.writeGitError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	h.writeJSONResponse(w, map[string]interface{}{
+		"valid":      len(violations) == 0,
+		"violations": violations,
+	})
+}
+
+// CommitLintConfig handles GET and POST /api/commit/lint/config
+func (h *Handler) CommitLintConfig(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case "GET":
+		config, err := h.git(r).LoadCommitLintConfig()
+		if err != nil {
+			h.writeGitError(w, err, http.StatusInternalServerError)
+			return
+		}
+		h.writeJSONResponse(w, config)
+	case "POST":
+		var config git.CommitLintConfig
+		if err := json.NewDecoder(r.Body).Decode(&config); err != nil {
+			h.writeErrorResponse(w, "Invalid request", http.StatusBadRequest)
+			return
+		}
+		if err := h.git(r).SaveCommitLintConfig(&config); err != nil {
+			h.writeGitError(w, err, http.StatusBadRequest)
+			return
+		}
+		h.writeJSONResponse(w, map[string]string{"status": "success"})
+	default:
+		h.writeErrorResponse(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
@@ -0,0 +1,221 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/knoxai/gait/internal/jobs"
+	"github.com/knoxai/gait/pkg/types"
+)
+
+// SetJobManager sets the manager used to run background remote operations
+func (h *Handler) SetJobManager(manager *jobs.Manager) {
+	h.jobs = manager
+}
+
+// startJob starts a background job and responds with 202 and the new job
+func (h *Handler) startJob(w http.ResponseWriter, r *http.Request, jobType string, fn jobs.Func) {
+	if h.jobs == nil {
+		h.writeErrorResponse(w, "Background jobs are not available", http.StatusServiceUnavailable)
+		return
+	}
+	if h.git(r) == nil {
+		h.writeErrorResponse(w, "No repository selected", http.StatusBadRequest)
+		return
+	}
+
+	path := h.git(r).GetRepoPath()
+	job := h.jobs.Start(jobType, repositoryID(path), path, fn)
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Location", "/api/jobs/"+job.ID)
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(job)
+}
+
+// StartFetchJob handles POST /api/jobs/fetch
+func (h *Handler) StartFetchJob(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		h.writeErrorResponse(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req struct {
+		Remote string `json:"remote"`
+		Prune  bool   `json:"prune"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.writeErrorResponse(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	service := h.git(r)
+	h.startJob(w, r, "fetch", func(ctx context.Context, progress func(types.JobProgress)) (interface{}, error) {
+		return service.FetchWithProgress(ctx, req.Remote, req.Prune, progress)
+	})
+}
+
+// StartPullJob handles POST /api/jobs/pull
+func (h *Handler) StartPullJob(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		h.writeErrorResponse(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req struct {
+		Remote string `json:"remote"`
+		Branch string `json:"branch"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.writeErrorResponse(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	service := h.git(r)
+	h.startJob(w, r, "pull", func(ctx context.Context, progress func(types.JobProgress)) (interface{}, error) {
+		return service.PullWithProgress(ctx, req.Remote, req.Branch, progress)
+	})
+}
+
+// StartPushJob handles POST /api/jobs/push
+func (h *Handler) StartPushJob(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		h.writeErrorResponse(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req struct {
+		Remote string `json:"remote"`
+		Branch string `json:"branch"`
+		Force  bool   `json:"force"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.writeErrorResponse(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	service := h.git(r)
+	h.startJob(w, r, "push", func(ctx context.Context, progress func(types.JobProgress)) (interface{}, error) {
+		return service.PushWithProgress(ctx, req.Remote, req.Branch, req.Force, progress)
+	})
+}
+
+// jobVisible reports whether a request may see and cancel a job: it must be a job of
+// the repository the request selects, or a job that runs in no repository, such as a
+// clone
+func (h *Handler) jobVisible(r *http.Request, job types.Job) bool {
+	if job.RepositoryID == "" {
+		return true
+	}
+	service := h.git(r)
+	return service != nil && job.RepositoryID == repositoryID(service.GetRepoPath())
+}
+
+// findJob returns a job the request may see, by ID
+func (h *Handler) findJob(r *http.Request, id string) (types.Job, bool) {
+	if h.jobs == nil {
+		return types.Job{}, false
+	}
+	job, ok := h.jobs.Get(id)
+	if !ok || !h.jobVisible(r, job) {
+		return types.Job{}, false
+	}
+	return job, true
+}
+
+// ListJobs handles GET /api/jobs - the jobs of the selected repository and clones
+func (h *Handler) ListJobs(w http.ResponseWriter, r *http.Request) {
+	visible := make([]types.Job, 0)
+	if h.jobs != nil {
+		for _, job := range h.jobs.List() {
+			if h.jobVisible(r, job) {
+				visible = append(visible, job)
+			}
+		}
+	}
+	h.writeJSONResponse(w, visible)
+}
+
+// GetJob handles GET /api/jobs/{id}
+func (h *Handler) GetJob(w http.ResponseWriter, r *http.Request) {
+	job, ok := h.findJob(r, mux.Vars(r)["id"])
+	if !ok {
+		h.writeErrorResponse(w, "Job not found", http.StatusNotFound)
+		return
+	}
+	h.writeJSONResponse(w, job)
+}
+
+// CancelJob handles DELETE /api/jobs/{id}
+func (h *Handler) CancelJob(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "DELETE" {
+		h.writeErrorResponse(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	id := mux.Vars(r)["id"]
+	if _, ok := h.findJob(r, id); !ok {
+		h.writeErrorResponse(w, "Job not found", http.StatusNotFound)
+		return
+	}
+
+	if err := h.jobs.Cancel(id); err != nil {
+		h.writeErrorResponse(w, err.Error(), http.StatusConflict)
+		return
+	}
+	h.writeJSONResponse(w, map[string]string{"status": "cancelling"})
+}
+
+// StreamJobEvents handles GET /api/jobs/{id}/events - Server-Sent Events with
+// "progress" events while the job runs and a final "done" event with its result
+func (h *Handler) StreamJobEvents(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	if _, ok := h.findJob(r, id); !ok {
+		h.writeErrorResponse(w, "Job not found", http.StatusNotFound)
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		h.writeErrorResponse(w, "Streaming not supported", http.StatusInternalServerError)
+		return
+	}
+
+	updates, unsubscribe, err := h.jobs.Subscribe(id)
+	if err != nil {
+		h.writeErrorResponse(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	defer unsubscribe()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+
+	writeEvent := func(event string, job types.Job) {
+		data, _ := json.Marshal(job)
+		fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, data)
+		flusher.Flush()
+	}
+
+	if job, ok := h.jobs.Get(id); ok && job.Status == jobs.StatusRunning {
+		writeEvent("progress", job)
+	}
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case job, open := <-updates:
+			if open {
+				writeEvent("progress", job)
+				continue
+			}
+			if final, ok := h.jobs.Get(id); ok {
+				writeEvent("done", final)
+			}
+			return
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/knoxai/gait/internal/git"
+)
+
+// maxPatchUploadSize limits the size of uploaded patches and mailboxes
+const maxPatchUploadSize = 32 << 20
+
+// FormatPatch handles GET /api/patch/format?rev=<commit or range>
+func (h *Handler) FormatPatch(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		h.writeErrorResponse(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	revision := r.URL.Query().Get("rev")
+	if revision == "" {
+		h.writeErrorResponse(w, "Revision is required", http.StatusBadRequest)
+		return
+	}
+
+	patch, err := h.git(r).FormatPatch(revision)
+	if err != nil {
+		h.writeGitError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	filename := strings.NewReplacer("..", "_", "/", "_", "^", "_", "~", "_").Replace(revision) + ".patch"
+	w.Header().Set("Content-Type", "application/mbox")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	w.Write(patch)
+}
+
+// GetWorkingTreeDiff handles GET /api/patch/diff?staged=true
+func (h *Handler) GetWorkingTreeDiff(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		h.writeErrorResponse(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	staged := r.URL.Query().Get("staged") == "true"
+	diff, err := h.git(r).GetWorkingTreeDiff(staged)
+	if err != nil {
+		h.writeGitError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/x-diff")
+	w.Header().Set("Content-Disposition", `attachment; filename="working-tree.diff"`)
+	w.Write(diff)
+}
+
+// ApplyPatch handles POST /api/patch/apply?mode=auto|apply|am&check=true&threeWay=true
+// The patch is read from a multipart "patch" file field or from the raw request body.
+func (h *Handler) ApplyPatch(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		h.writeErrorResponse(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxPatchUploadSize)
+
+	var patch []byte
+	var err error
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		file, _, formErr := r.FormFile("patch")
+		if formErr != nil {
+			h.writeErrorResponse(w, "Patch file is required", http.StatusBadRequest)
+			return
+		}
+		defer file.Close()
+		patch, err = io.ReadAll(file)
+	} else {
+		patch, err = io.ReadAll(r.Body)
+	}
+	if err != nil {
+		h.writeErrorResponse(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	query := r.URL.Query()
+	var useAm bool
+	switch query.Get("mode") {
+	case "am":
+		useAm = true
+	case "apply":
+		useAm = false
+	case "", "auto":
+		useAm = git.IsMailboxPatch(patch)
+	default:
+		h.writeErrorResponse(w, "Mode must be auto, apply or am", http.StatusBadRequest)
+		return
+	}
+
+	result, err := h.git(r).ApplyPatch(patch, useAm, query.Get("check") == "true", query.Get("threeWay") == "true")
+	if err != nil {
+		h.writeGitError(w, err, http.StatusBadRequest)
+		return
+	}
+
+	if !result.Checked && !result.Applied {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusConflict)
+		json.NewEncoder(w).Encode(result)
+		return
+	}
+
+	h.writeJSONResponse(w, result)
+}
@@ -0,0 +1,58 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/knoxai/gait/internal/git"
+)
+
+// ProtectionConfig handles GET/POST /api/protection/config
+func (h *Handler) ProtectionConfig(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case "GET":
+		config, err := h.git(r).LoadProtectionConfig()
+		if err != nil {
+			h.writeGitError(w, err, http.StatusInternalServerError)
+			return
+		}
+		h.writeJSONResponse(w, config)
+	case "POST":
+		config := git.DefaultProtectionConfig()
+		if err := json.NewDecoder(r.Body).Decode(config); err != nil {
+			h.writeErrorResponse(w, "Invalid request", http.StatusBadRequest)
+			return
+		}
+		if err := h.git(r).SaveProtectionConfig(config); err != nil {
+			h.writeGitError(w, err, http.StatusBadRequest)
+			return
+		}
+		h.writeJSONResponse(w, config)
+	default:
+		h.writeErrorResponse(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// GetBlockedOperations handles GET /api/protection/blocked?limit=N
+func (h *Handler) GetBlockedOperations(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		h.writeErrorResponse(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	limit := 100
+	if l := r.URL.Query().Get("limit"); l != "" {
+		if parsed, err := strconv.Atoi(l); err == nil {
+			limit = parsed
+		}
+	}
+
+	blocked, err := h.git(r).GetBlockedOperations(limit)
+	if err != nil {
+		h.writeGitError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	h.writeJSONResponse(w, blocked)
+}
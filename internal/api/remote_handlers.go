@@ -0,0 +1,152 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+// AddRemote handles POST /api/remotes
+func (h *Handler) AddRemote(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		h.writeErrorResponse(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req struct {
+		Name  string `json:"name"`
+		URL   string `json:"url"`
+		Fetch bool   `json:"fetch"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.writeErrorResponse(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.git(r).AddRemote(req.Name, req.URL, req.Fetch); err != nil {
+		h.writeRemoteError(w, err)
+		return
+	}
+
+	h.writeJSONResponse(w, map[string]string{"status": "success"})
+}
+
+// RenameRemote handles POST /api/remote/{remote}/rename
+func (h *Handler) RenameRemote(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		h.writeErrorResponse(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req struct {
+		NewName string `json:"newName"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.writeErrorResponse(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.git(r).RenameRemote(mux.Vars(r)["remote"], req.NewName); err != nil {
+		h.writeGitError(w, err, http.StatusBadRequest)
+		return
+	}
+
+	h.writeJSONResponse(w, map[string]string{"status": "success"})
+}
+
+// RemoveRemote handles DELETE /api/remote/{remote}
+func (h *Handler) RemoveRemote(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "DELETE" {
+		h.writeErrorResponse(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if err := h.git(r).RemoveRemote(mux.Vars(r)["remote"]); err != nil {
+		h.writeGitError(w, err, http.StatusBadRequest)
+		return
+	}
+
+	h.writeJSONResponse(w, map[string]string{"status": "success"})
+}
+
+// SetRemoteURL handles PUT /api/remote/{remote}/url - {url, push}
+func (h *Handler) SetRemoteURL(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "PUT" {
+		h.writeErrorResponse(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req struct {
+		URL  string `json:"url"`
+		Push bool   `json:"push"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.writeErrorResponse(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.git(r).SetRemoteURL(mux.Vars(r)["remote"], req.URL, req.Push); err != nil {
+		h.writeGitError(w, err, http.StatusBadRequest)
+		return
+	}
+
+	h.writeJSONResponse(w, map[string]string{"status": "success"})
+}
+
+// RemoteRefspecs handles GET/PUT /api/remote/{remote}/refspecs
+func (h *Handler) RemoteRefspecs(w http.ResponseWriter, r *http.Request) {
+	remote := mux.Vars(r)["remote"]
+
+	switch r.Method {
+	case "GET":
+		refspecs, err := h.git(r).GetRemoteRefspecs(remote)
+		if err != nil {
+			h.writeGitError(w, err, http.StatusInternalServerError)
+			return
+		}
+		h.writeJSONResponse(w, map[string]interface{}{"remote": remote, "refspecs": refspecs})
+	case "PUT":
+		var req struct {
+			Refspecs []string `json:"refspecs"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			h.writeErrorResponse(w, "Invalid request", http.StatusBadRequest)
+			return
+		}
+		if err := h.git(r).SetRemoteRefspecs(remote, req.Refspecs); err != nil {
+			h.writeGitError(w, err, http.StatusBadRequest)
+			return
+		}
+		h.writeJSONResponse(w, map[string]interface{}{"remote": remote, "refspecs": req.Refspecs})
+	default:
+		h.writeErrorResponse(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// PruneRemote handles POST /api/remote/{remote}/prune - {dryRun}
+func (h *Handler) PruneRemote(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		h.writeErrorResponse(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req struct {
+		DryRun bool `json:"dryRun"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.writeErrorResponse(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	pruned, err := h.git(r).PruneRemote(mux.Vars(r)["remote"], req.DryRun)
+	if err != nil {
+		h.writeRemoteError(w, err)
+		return
+	}
+
+	h.writeJSONResponse(w, map[string]interface{}{
+		"dryRun": req.DryRun,
+		"pruned": pruned,
+	})
+}
@@ -0,0 +1,132 @@
+package api
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/knoxai/gait/internal/git"
+)
+
+// RepositoryHeader is the request header that selects the repository an API call
+// operates on, by repository ID or path
+const RepositoryHeader = "X-Gait-Repository"
+
+// RepositoryQueryParam selects the repository for requests that cannot set headers,
+// such as WebSockets, EventSource streams and download links
+const RepositoryQueryParam = "repo"
+
+// repositoryPathPrefix prefixes API paths that carry the repository ID, as in
+// /api/repos/{id}/commits
+const repositoryPathPrefix = "/api/repos/"
+
+type repositoryContextKey struct{}
+
+// workspacePathPrefixes are the API paths served without a repository: repository
+// management, sign-in, the audit log, AI and jobs, which check the repository
+// themselves when they need one
+var workspacePathPrefixes = []string{
+	"/api/repositories", "/api/repository/", "/api/auth/", "/api/audit", "/api/ai/", "/api/jobs",
+}
+
+// emptyViewPaths are the API paths whose GET requests answer with empty results when
+// there is no repository, so the UI can show an empty workspace
+var emptyViewPaths = map[string]bool{
+	"/api/all": true, "/api/commits": true, "/api/commits/html": true, "/api/branches": true,
+	"/api/tags": true, "/api/stashes": true, "/api/remotes": true, "/api/uncommitted": true,
+	"/api/settings": true,
+}
+
+// needsRepository reports whether a request is an API call that operates on a
+// repository, which fails when there is none
+func needsRepository(r *http.Request) bool {
+	if !strings.HasPrefix(r.URL.Path, "/api/") {
+		return false
+	}
+	for _, prefix := range workspacePathPrefixes {
+		if strings.HasPrefix(r.URL.Path, prefix) {
+			return false
+		}
+	}
+	return !(r.Method == http.MethodGet && emptyViewPaths[r.URL.Path])
+}
+
+// writeRepositoryError responds with a JSON error for a request whose repository is
+// unknown or missing
+func writeRepositoryError(w http.ResponseWriter, message, code string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusNotFound)
+	json.NewEncoder(w).Encode(map[string]string{"error": message, "code": code})
+}
+
+// repositoryID returns the stable ID of a repository path
+func repositoryID(path string) string {
+	sum := sha256.Sum256([]byte(path))
+	return hex.EncodeToString(sum[:6])
+}
+
+// RepositoryFromRequest returns the repository ID or path a request selects, taken from
+// a /api/repos/{id}/ path prefix, the X-Gait-Repository header or the repo query
+// parameter, in that order. It returns "" when the request selects none.
+func RepositoryFromRequest(r *http.Request) string {
+	if rest, ok := strings.CutPrefix(r.URL.Path, repositoryPathPrefix); ok {
+		if id, _, found := strings.Cut(rest, "/"); found && id != "" {
+			return id
+		}
+	}
+	if id := r.Header.Get(RepositoryHeader); id != "" {
+		return id
+	}
+	return r.URL.Query().Get(RepositoryQueryParam)
+}
+
+// GitServiceFromContext returns the git service stored by SelectRepository, or nil if
+// the request has no repository
+func GitServiceFromContext(ctx context.Context) *git.Service {
+	service, _ := ctx.Value(repositoryContextKey{}).(*git.Service)
+	return service
+}
+
+// SelectRepository resolves the repository each request selects to its pooled git
+// service and stores it in the request context, bound to that context so its git
+// commands stop when the client disconnects. Paths under /api/repos/{id}/ are
+// rewritten to the equivalent /api/ path so every route is reachable either way.
+// Requests that select no repository use the default one; requests that select an
+// unknown repository, or need one when there is no default, fail with 404.
+func (rm *RepositoryManager) SelectRepository(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var service *git.Service
+		if selected := RepositoryFromRequest(r); selected != "" {
+			var err error
+			service, err = rm.Service(selected)
+			if err != nil {
+				writeRepositoryError(w, err.Error(), "repository_not_found")
+				return
+			}
+			rm.MarkOpened(service.GetRepoPath())
+		} else {
+			service = rm.DefaultService()
+		}
+		if service != nil {
+			service = service.WithContext(r.Context())
+		}
+
+		r = r.WithContext(context.WithValue(r.Context(), repositoryContextKey{}, service))
+		if rest, ok := strings.CutPrefix(r.URL.Path, repositoryPathPrefix); ok {
+			if _, path, found := strings.Cut(rest, "/"); found {
+				url := *r.URL
+				url.Path = "/api/" + path
+				url.RawPath = ""
+				r.URL = &url
+			}
+		}
+		if service == nil && needsRepository(r) {
+			writeRepositoryError(w, "No repository selected", "no_repository")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -1,25 +1,64 @@
 package api
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"log"
 	"net/http"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/knoxai/gait/internal/git"
+	"github.com/knoxai/gait/internal/jobs"
 	"github.com/knoxai/gait/pkg/types"
 )
 
-// RepositoryManager handles multiple repository operations
+// openedInterval is how often a repository's last opened time is updated while it is
+// in use, so busy repositories don't rewrite the config on every request
+const openedInterval = time.Minute
+
+// RepositoryManager handles multiple repository operations. It is safe for concurrent
+// use by HTTP handlers.
 type RepositoryManager struct {
+	configPath    string
+	workspacePath string
+	jobs          *jobs.Manager
+
+	// mu guards everything below
+	mu              sync.RWMutex
 	repositories    []types.Repository
-	currentRepo     *git.Service
+	services        map[string]*git.Service
 	currentRepoPath string
-	configPath      string
-	workspacePath   string
+	configModTime   time.Time // modification time of the config when last loaded or saved
+	configSize      int64
+}
+
+// repositoryConfig is the saved form of a managed repository
+type repositoryConfig struct {
+	Name        string     `json:"name"`
+	Path        string     `json:"path"`
+	DisplayName string     `json:"displayName,omitempty"`
+	Group       string     `json:"group,omitempty"`
+	Tags        []string   `json:"tags,omitempty"`
+	LastOpened  *time.Time `json:"lastOpened,omitempty"`
+	Pinned      bool       `json:"pinned,omitempty"`
+	Layout      string     `json:"layout,omitempty"`
+	GitDir      string     `json:"gitDir,omitempty"`
+}
+
+// RepositoryMetadata holds the user-editable metadata of a repository; nil fields are
+// left unchanged
+type RepositoryMetadata struct {
+	DisplayName *string   `json:"displayName,omitempty"`
+	Group       *string   `json:"group,omitempty"`
+	Tags        *[]string `json:"tags,omitempty"`
+	Pinned      *bool     `json:"pinned,omitempty"`
 }
 
 // NewRepositoryManager creates a new repository manager
@@ -29,43 +68,132 @@ func NewRepositoryManager(workspacePath string) *RepositoryManager {
 		repositories:  []types.Repository{},
 		configPath:    configPath,
 		workspacePath: workspacePath,
+		services:      make(map[string]*git.Service),
 	}
 }
 
 // LoadRepositories loads saved repositories from config
 func (rm *RepositoryManager) LoadRepositories() error {
-	if _, err := os.Stat(rm.configPath); os.IsNotExist(err) {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+	return rm.loadLocked()
+}
+
+// loadLocked reads the config into the repository list; rm.mu must be held
+func (rm *RepositoryManager) loadLocked() error {
+	info, err := os.Stat(rm.configPath)
+	if os.IsNotExist(err) {
 		return nil // No config file yet, that's fine
 	}
+	if err != nil {
+		return err
+	}
 
 	data, err := os.ReadFile(rm.configPath)
 	if err != nil {
 		return err
 	}
 
-	return json.Unmarshal(data, &rm.repositories)
+	var saved []repositoryConfig
+	if len(bytes.TrimSpace(data)) > 0 {
+		if err := json.Unmarshal(data, &saved); err != nil {
+			return fmt.Errorf("invalid %s: %v", rm.configPath, err)
+		}
+	}
+
+	repositories := make([]types.Repository, 0, len(saved))
+	for _, repo := range saved {
+		if repo.Path == "" {
+			continue
+		}
+		if repo.Name == "" {
+			repo.Name = filepath.Base(repo.Path)
+		}
+		repositories = append(repositories, types.Repository{
+			Name:        repo.Name,
+			Path:        repo.Path,
+			DisplayName: repo.DisplayName,
+			Group:       repo.Group,
+			Tags:        repo.Tags,
+			LastOpened:  repo.LastOpened,
+			Pinned:      repo.Pinned,
+			Layout:      repo.Layout,
+			GitDir:      repo.GitDir,
+		})
+	}
+	rm.repositories = repositories
+	rm.configModTime = info.ModTime()
+	rm.configSize = info.Size()
+	return nil
+}
+
+// configChangedLocked reports whether the config was changed by something other than
+// this manager since it was last loaded or saved; rm.mu must be held
+func (rm *RepositoryManager) configChangedLocked() bool {
+	info, err := os.Stat(rm.configPath)
+	if err != nil {
+		return false
+	}
+	return !info.ModTime().Equal(rm.configModTime) || info.Size() != rm.configSize
+}
+
+// reloadIfChanged reloads the config if it was edited outside the manager
+func (rm *RepositoryManager) reloadIfChanged() {
+	rm.mu.RLock()
+	changed := rm.configChangedLocked()
+	rm.mu.RUnlock()
+	if !changed {
+		return
+	}
+
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+	rm.refreshLocked()
+}
+
+// refreshLocked reloads the config if it was edited outside the manager, keeping the
+// current list if the edited file can't be read; rm.mu must be held
+func (rm *RepositoryManager) refreshLocked() {
+	if !rm.configChangedLocked() {
+		return
+	}
+	if err := rm.loadLocked(); err != nil {
+		log.Printf("Warning: Failed to reload repositories: %v", err)
+		// Don't retry the same broken file on every call
+		if info, err := os.Stat(rm.configPath); err == nil {
+			rm.configModTime = info.ModTime()
+			rm.configSize = info.Size()
+		}
+	}
 }
 
 // SaveRepositories saves repositories to config
 func (rm *RepositoryManager) SaveRepositories() error {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+	return rm.saveLocked()
+}
+
+// saveLocked writes the repository list to the config; rm.mu must be held
+func (rm *RepositoryManager) saveLocked() error {
 	// Ensure .gait directory exists
 	if err := os.MkdirAll(filepath.Dir(rm.configPath), 0755); err != nil {
 		return err
 	}
 
-	// Create a copy of repositories without the Current field for saving
-	reposToSave := make([]struct {
-		Name string `json:"name"`
-		Path string `json:"path"`
-	}, len(rm.repositories))
-	
+	// IDs and the current marking are derived, so only the config fields are saved
+	reposToSave := make([]repositoryConfig, len(rm.repositories))
 	for i, repo := range rm.repositories {
-		reposToSave[i] = struct {
-			Name string `json:"name"`
-			Path string `json:"path"`
-		}{
-			Name: repo.Name,
-			Path: repo.Path,
+		reposToSave[i] = repositoryConfig{
+			Name:        repo.Name,
+			Path:        repo.Path,
+			DisplayName: repo.DisplayName,
+			Group:       repo.Group,
+			Tags:        repo.Tags,
+			LastOpened:  repo.LastOpened,
+			Pinned:      repo.Pinned,
+			Layout:      repo.Layout,
+			GitDir:      repo.GitDir,
 		}
 	}
 
@@ -74,137 +202,361 @@ func (rm *RepositoryManager) SaveRepositories() error {
 		return err
 	}
 
-	return os.WriteFile(rm.configPath, data, 0644)
+	if err := writeFileAtomic(rm.configPath, data, 0644); err != nil {
+		return err
+	}
+	if info, err := os.Stat(rm.configPath); err == nil {
+		rm.configModTime = info.ModTime()
+		rm.configSize = info.Size()
+	}
+	return nil
 }
 
-// AddRepository adds a local repository to the managed list
-func (rm *RepositoryManager) AddRepository(path string) error {
-	absPath, err := filepath.Abs(path)
+// writeFileAtomic writes data to a temporary file next to path and renames it over
+// path, so readers never see a partially written file
+func writeFileAtomic(path string, data []byte, perm os.FileMode) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp-*")
 	if err != nil {
-		return fmt.Errorf("invalid path: %v", err)
+		return err
 	}
+	tmpPath := tmp.Name()
+	defer os.Remove(tmpPath)
 
-	// Check if it's a valid Git repository
-	if _, err := os.Stat(filepath.Join(absPath, ".git")); os.IsNotExist(err) {
-		return fmt.Errorf("not a Git repository: %s", absPath)
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmpPath, perm); err != nil {
+		return err
 	}
+	return os.Rename(tmpPath, path)
+}
 
-	// Check if already exists
-	for _, repo := range rm.repositories {
-		if repo.Path == absPath {
-			return fmt.Errorf("repository already exists: %s", absPath)
-		}
+// AddRepository adds a local repository to the managed list. The path may be a working
+// tree, a bare repository or a git directory whose core.worktree names its working tree.
+func (rm *RepositoryManager) AddRepository(path string) error {
+	resolved, err := git.ResolveRepository(path)
+	if err != nil {
+		return err
 	}
 
-	repo := types.Repository{
-		Name: filepath.Base(absPath),
-		Path: absPath,
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+	rm.refreshLocked()
+
+	// Check if already exists
+	if _, err := rm.findLocked(resolved.Path); err == nil {
+		return fmt.Errorf("repository already exists: %s", resolved.Path)
 	}
 
-	rm.repositories = append(rm.repositories, repo)
-	return rm.SaveRepositories()
+	rm.repositories = append(rm.repositories, discoveredRepository(*resolved))
+	return rm.saveLocked()
+}
+
+// discoveredRepository converts a resolved repository to a managed one
+func discoveredRepository(repo types.DiscoveredRepository) types.Repository {
+	return types.Repository{
+		Name:   repo.Name,
+		Path:   repo.Path,
+		Layout: repo.Layout,
+		GitDir: repo.GitDir,
+	}
 }
 
-// CloneRepository clones a remote repository
-func (rm *RepositoryManager) CloneRepository(url, name string) error {
+// clonePath returns the workspace directory a clone of url is placed in, named name or
+// else after the last element of url. The name must be a single directory name, so that
+// the clone cannot be placed outside the workspace.
+func (rm *RepositoryManager) clonePath(url, name string) (string, error) {
 	if name == "" {
-		// Extract name from URL
-		parts := strings.Split(strings.TrimSuffix(url, ".git"), "/")
-		name = parts[len(parts)-1]
+		// Extract name from URL, which may also be scp-like, as in git@host:repo.git
+		trimmed := strings.TrimSuffix(strings.TrimRight(url, "/"), ".git")
+		name = trimmed[strings.LastIndexAny(trimmed, `/\:`)+1:]
+	}
+	if cleaned, err := git.CleanPath(name); err != nil || cleaned != name || strings.ContainsAny(name, `/\`) {
+		return "", fmt.Errorf("invalid repository name %q: it must be a single directory name", name)
 	}
 
-	clonePath := filepath.Join(rm.workspacePath, name)
+	path := filepath.Join(rm.workspacePath, name)
+	if rel, err := filepath.Rel(rm.workspacePath, path); err != nil || rel != name {
+		return "", fmt.Errorf("invalid repository name %q: the clone must stay inside the workspace", name)
+	}
+	return path, nil
+}
+
+// CloneRepository clones a remote repository using credential, which may be nil. The
+// clone stops, and its partial directory is removed, when ctx ends.
+func (rm *RepositoryManager) CloneRepository(ctx context.Context, url, name string, credential *types.RemoteCredential, opts types.CloneOptions) error {
+	_, err := rm.CloneRepositoryWithProgress(ctx, url, name, credential, opts, nil)
+	return err
+}
 
-	// Check if directory already exists
-	if _, err := os.Stat(clonePath); !os.IsNotExist(err) {
-		return fmt.Errorf("directory already exists: %s", clonePath)
+// CloneRepositoryWithProgress clones a remote repository reporting progress, and adds it
+// to the managed repositories once the clone completes
+func (rm *RepositoryManager) CloneRepositoryWithProgress(ctx context.Context, url, name string, credential *types.RemoteCredential, opts types.CloneOptions, progress git.ProgressFunc) (*types.RemoteOperationResult, error) {
+	path, err := rm.clonePath(url, name)
+	if err != nil {
+		return nil, err
+	}
+	result, err := git.CloneWithProgress(ctx, url, path, credential, opts, progress)
+	if err != nil {
+		return nil, fmt.Errorf("failed to clone repository: %w", err)
+	}
+	if err := rm.AddRepository(result.Path); err != nil {
+		return result, err
 	}
+	return result, nil
+}
 
-	// Clone the repository
-	cmd := exec.Command("git", "clone", url, clonePath)
-	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("failed to clone repository: %v", err)
+// cloneCredential returns the credential supplied with a clone request, or nil if none was
+func cloneCredential(username, token, sshKeyPath string) *types.RemoteCredential {
+	if token == "" && sshKeyPath == "" {
+		return nil
 	}
+	return &types.RemoteCredential{Remote: "origin", Username: username, Token: token, SSHKeyPath: sshKeyPath}
+}
 
-	// Add to managed repositories
-	return rm.AddRepository(clonePath)
+// SetJobManager sets the manager used to run background clones
+func (rm *RepositoryManager) SetJobManager(manager *jobs.Manager) {
+	rm.jobs = manager
 }
 
 // RemoveRepository removes a repository from the managed list
 func (rm *RepositoryManager) RemoveRepository(path string) error {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+	rm.refreshLocked()
+
 	for i, repo := range rm.repositories {
 		if repo.Path == path {
-			rm.repositories = append(rm.repositories[:i], rm.repositories[i+1:]...)
-			return rm.SaveRepositories()
+			rm.repositories = append(rm.repositories[:i:i], rm.repositories[i+1:]...)
+			delete(rm.services, path)
+			if rm.currentRepoPath == path {
+				rm.currentRepoPath = ""
+			}
+			return rm.saveLocked()
 		}
 	}
 	return fmt.Errorf("repository not found: %s", path)
 }
 
-// GetRepositories returns all managed repositories
+// GetRepositories returns all managed repositories, marking the default one as current
 func (rm *RepositoryManager) GetRepositories() []types.Repository {
+	rm.reloadIfChanged()
+
+	rm.mu.RLock()
+	defer rm.mu.RUnlock()
 	repos := make([]types.Repository, len(rm.repositories))
 	for i, repo := range rm.repositories {
 		repos[i] = repo
+		repos[i].ID = repositoryID(repo.Path)
 		repos[i].Current = (repo.Path == rm.currentRepoPath)
 	}
 	return repos
 }
 
-// SwitchRepository switches to a different repository
-func (rm *RepositoryManager) SwitchRepository(path string) (*git.Service, error) {
-	// Validate repository exists and is managed
-	found := false
-	for _, repo := range rm.repositories {
-		if repo.Path == path {
-			found = true
-			break
+// findLocked returns the index of the managed repository with the given ID or path;
+// rm.mu must be held
+func (rm *RepositoryManager) findLocked(idOrPath string) (int, error) {
+	for i, repo := range rm.repositories {
+		if repo.Path == idOrPath || repositoryID(repo.Path) == idOrPath || (repo.GitDir != "" && repo.GitDir == idOrPath) {
+			return i, nil
 		}
 	}
+	return -1, fmt.Errorf("repository not found in managed list: %s", idOrPath)
+}
+
+// Service returns the pooled git service of the managed repository with the given ID
+// or path. Services are created on first use and shared by all requests for the
+// repository, so concurrent requests for different repositories don't interfere.
+func (rm *RepositoryManager) Service(idOrPath string) (*git.Service, error) {
+	rm.reloadIfChanged()
+
+	rm.mu.RLock()
+	index, err := rm.findLocked(idOrPath)
+	if err != nil {
+		rm.mu.RUnlock()
+		return nil, err
+	}
+	path := rm.repositories[index].Path
+	gitDir := rm.repositories[index].GitDir
+	service, ok := rm.services[path]
+	rm.mu.RUnlock()
+	if ok {
+		return service, nil
+	}
+
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+	if service, ok := rm.services[path]; ok {
+		return service, nil
+	}
+	service = git.NewServiceWithGitDir(path, gitDir)
+	rm.services[path] = service
+	return service, nil
+}
+
+// DefaultService returns the git service used by requests that select no repository,
+// or nil if there is no default repository
+func (rm *RepositoryManager) DefaultService() *git.Service {
+	rm.mu.RLock()
+	path := rm.currentRepoPath
+	rm.mu.RUnlock()
+
+	if path == "" {
+		return nil
+	}
+	service, err := rm.Service(path)
+	if err != nil {
+		return nil
+	}
+	return service
+}
 
-	if !found {
-		return nil, fmt.Errorf("repository not found in managed list: %s", path)
+// SwitchRepository makes a managed repository, given by ID or path, the default for
+// requests that select no repository
+func (rm *RepositoryManager) SwitchRepository(idOrPath string) (*git.Service, error) {
+	service, err := rm.Service(idOrPath)
+	if err != nil {
+		return nil, err
 	}
+	path := service.GetRepoPath()
 
 	// Check if it's still a valid Git repository
-	if _, err := os.Stat(filepath.Join(path, ".git")); os.IsNotExist(err) {
+	if !service.Exists() {
 		return nil, fmt.Errorf("not a Git repository: %s", path)
 	}
 
-	rm.currentRepo = git.NewService(path)
+	rm.mu.Lock()
 	rm.currentRepoPath = path
-	return rm.currentRepo, nil
+	rm.mu.Unlock()
+	rm.MarkOpened(path)
+	return service, nil
 }
 
-// ClearCurrentRepository clears the current repository selection
+// ClearCurrentRepository clears the default repository
 func (rm *RepositoryManager) ClearCurrentRepository() {
-	rm.currentRepo = nil
+	rm.mu.Lock()
 	rm.currentRepoPath = ""
+	rm.mu.Unlock()
+}
+
+// MarkOpened records that a repository is in use. The time is saved at most once per
+// openedInterval for each repository.
+func (rm *RepositoryManager) MarkOpened(path string) {
+	now := time.Now()
+	rm.mu.RLock()
+	index, err := rm.findLocked(path)
+	recent := err == nil && rm.repositories[index].LastOpened != nil && now.Sub(*rm.repositories[index].LastOpened) < openedInterval
+	rm.mu.RUnlock()
+	if err != nil || recent {
+		return
+	}
+
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+	rm.refreshLocked()
+	if index, err = rm.findLocked(path); err != nil {
+		return
+	}
+	rm.repositories[index].LastOpened = &now
+	if err := rm.saveLocked(); err != nil {
+		log.Printf("Warning: Failed to save repositories: %v", err)
+	}
 }
 
-// DiscoverRepositories discovers repositories in the workspace
-func (rm *RepositoryManager) DiscoverRepositories(maxDepth int) error {
-	tempService := git.NewService(rm.workspacePath)
-	discovered, err := tempService.DiscoverRepositories(rm.workspacePath, maxDepth)
+// UpdateMetadata updates the display name, group or pinned state of a repository
+func (rm *RepositoryManager) UpdateMetadata(idOrPath string, metadata RepositoryMetadata) (types.Repository, error) {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+	rm.refreshLocked()
+
+	index, err := rm.findLocked(idOrPath)
 	if err != nil {
-		return err
+		return types.Repository{}, err
+	}
+	repo := &rm.repositories[index]
+	if metadata.DisplayName != nil {
+		repo.DisplayName = strings.TrimSpace(*metadata.DisplayName)
+	}
+	if metadata.Group != nil {
+		repo.Group = strings.TrimSpace(*metadata.Group)
+	}
+	if metadata.Tags != nil {
+		repo.Tags = normalizeTags(*metadata.Tags)
+	}
+	if metadata.Pinned != nil {
+		repo.Pinned = *metadata.Pinned
+	}
+	if err := rm.saveLocked(); err != nil {
+		return types.Repository{}, err
 	}
 
+	updated := *repo
+	updated.ID = repositoryID(repo.Path)
+	updated.Current = repo.Path == rm.currentRepoPath
+	return updated, nil
+}
+
+// PreviewDiscovery finds the repositories in the workspace without adding them, marking
+// those already managed
+func (rm *RepositoryManager) PreviewDiscovery(opts git.DiscoverOptions) ([]types.DiscoveredRepository, error) {
+	discovered, err := git.DiscoverRepositories(rm.workspacePath, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	rm.reloadIfChanged()
+	rm.mu.RLock()
+	defer rm.mu.RUnlock()
+	for i, repo := range discovered {
+		_, err := rm.findLocked(repo.Path)
+		discovered[i].Managed = err == nil
+	}
+	return discovered, nil
+}
+
+// DiscoverRepositories discovers repositories in the workspace and adds those not yet
+// managed; when paths is not empty only the discovered repositories at those paths are
+// added. It returns the repositories added.
+func (rm *RepositoryManager) DiscoverRepositories(opts git.DiscoverOptions, paths []string) ([]types.Repository, error) {
+	discovered, err := git.DiscoverRepositories(rm.workspacePath, opts)
+	if err != nil {
+		return nil, err
+	}
+	selected := make(map[string]bool, len(paths))
+	for _, path := range paths {
+		selected[filepath.Clean(path)] = true
+	}
+
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+	rm.refreshLocked()
+
 	// Add discovered repositories that aren't already managed
+	added := make([]types.Repository, 0)
 	for _, repo := range discovered {
-		exists := false
-		for _, existing := range rm.repositories {
-			if existing.Path == repo.Path {
-				exists = true
-				break
-			}
+		if len(selected) > 0 && !selected[repo.Path] {
+			continue
 		}
-		if !exists {
-			rm.repositories = append(rm.repositories, repo)
+		if _, err := rm.findLocked(repo.Path); err == nil {
+			continue
 		}
+		managed := discoveredRepository(repo)
+		rm.repositories = append(rm.repositories, managed)
+		managed.ID = repositoryID(managed.Path)
+		added = append(added, managed)
 	}
-
-	return rm.SaveRepositories()
+	if len(added) == 0 {
+		return added, nil
+	}
+	return added, rm.saveLocked()
 }
 
 // Repository management HTTP handlers
@@ -239,7 +591,35 @@ func (rm *RepositoryManager) HandleAddRepository(w http.ResponseWriter, r *http.
 	json.NewEncoder(w).Encode(map[string]string{"status": "success"})
 }
 
-// HandleCloneRepository handles POST /api/repositories/clone
+// HandleUpdateRepositoryMetadata handles PUT /api/repositories/metadata -
+// {path, displayName?, group?, tags?, pinned?}
+func (rm *RepositoryManager) HandleUpdateRepositoryMetadata(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "PUT" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req struct {
+		Path string `json:"path"`
+		RepositoryMetadata
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	repo, err := rm.UpdateMetadata(req.Path, req.RepositoryMetadata)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(repo)
+}
+
+// HandleCloneRepository handles POST /api/repositories/clone - {url, name, credentials,
+// branch, singleBranch, depth, filter, sparse}
 func (rm *RepositoryManager) HandleCloneRepository(w http.ResponseWriter, r *http.Request) {
 	if r.Method != "POST" {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
@@ -247,8 +627,12 @@ func (rm *RepositoryManager) HandleCloneRepository(w http.ResponseWriter, r *htt
 	}
 
 	var req struct {
-		URL  string `json:"url"`
-		Name string `json:"name,omitempty"`
+		URL        string `json:"url"`
+		Name       string `json:"name,omitempty"`
+		Username   string `json:"username,omitempty"`
+		Token      string `json:"token,omitempty"`
+		SSHKeyPath string `json:"sshKeyPath,omitempty"`
+		types.CloneOptions
 	}
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		http.Error(w, "Invalid request", http.StatusBadRequest)
@@ -260,7 +644,12 @@ func (rm *RepositoryManager) HandleCloneRepository(w http.ResponseWriter, r *htt
 		return
 	}
 
-	if err := rm.CloneRepository(req.URL, req.Name); err != nil {
+	if err := rm.CloneRepository(r.Context(), req.URL, req.Name, cloneCredential(req.Username, req.Token, req.SSHKeyPath), req.CloneOptions); err != nil {
+		var authErr *git.AuthError
+		if errors.As(err, &authErr) {
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
@@ -269,6 +658,56 @@ func (rm *RepositoryManager) HandleCloneRepository(w http.ResponseWriter, r *htt
 	json.NewEncoder(w).Encode(map[string]string{"status": "success"})
 }
 
+// HandleCloneRepositoryJob handles POST /api/jobs/clone - clone in the background, with
+// the same options as /api/repositories/clone
+func (rm *RepositoryManager) HandleCloneRepositoryJob(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if rm.jobs == nil {
+		http.Error(w, "Background jobs are not available", http.StatusServiceUnavailable)
+		return
+	}
+
+	var req struct {
+		URL        string `json:"url"`
+		Name       string `json:"name,omitempty"`
+		Username   string `json:"username,omitempty"`
+		Token      string `json:"token,omitempty"`
+		SSHKeyPath string `json:"sshKeyPath,omitempty"`
+		types.CloneOptions
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	if req.URL == "" {
+		http.Error(w, "URL is required", http.StatusBadRequest)
+		return
+	}
+	path, err := rm.clonePath(req.URL, req.Name)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		http.Error(w, "Directory already exists: "+path, http.StatusBadRequest)
+		return
+	}
+
+	credential := cloneCredential(req.Username, req.Token, req.SSHKeyPath)
+	job := rm.jobs.Start("clone", "", req.URL, func(ctx context.Context, progress func(types.JobProgress)) (interface{}, error) {
+		return rm.CloneRepositoryWithProgress(ctx, req.URL, req.Name, credential, req.CloneOptions, progress)
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Location", "/api/jobs/"+job.ID)
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(job)
+}
+
 // HandleRemoveRepository handles DELETE /api/repositories/remove
 func (rm *RepositoryManager) HandleRemoveRepository(w http.ResponseWriter, r *http.Request) {
 	if r.Method != "DELETE" {
@@ -317,25 +756,54 @@ func (rm *RepositoryManager) HandleSwitchRepository(w http.ResponseWriter, r *ht
 	json.NewEncoder(w).Encode(map[string]string{"status": "success"})
 }
 
-// HandleDiscoverRepositories handles POST /api/repositories/discover
-func (rm *RepositoryManager) HandleDiscoverRepositories(w http.ResponseWriter, r *http.Request) {
+// discoverRequest is the body of the discovery endpoints
+type discoverRequest struct {
+	MaxDepth int      `json:"maxDepth"`
+	Exclude  []string `json:"exclude"`
+	Paths    []string `json:"paths"`
+}
+
+// decodeDiscoverRequest reads the discovery options of a request, defaulting to a depth
+// of 3
+func decodeDiscoverRequest(r *http.Request) discoverRequest {
+	var req discoverRequest
+	json.NewDecoder(r.Body).Decode(&req)
+	if req.MaxDepth <= 0 {
+		req.MaxDepth = 3
+	}
+	return req
+}
+
+// HandlePreviewDiscovery handles POST /api/repositories/discover/preview - {maxDepth,
+// exclude} - the repositories discovery would find, without adding them
+func (rm *RepositoryManager) HandlePreviewDiscovery(w http.ResponseWriter, r *http.Request) {
 	if r.Method != "POST" {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
 
-	var req struct {
-		MaxDepth int `json:"maxDepth"`
-	}
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		req.MaxDepth = 3 // default
+	req := decodeDiscoverRequest(r)
+	discovered, err := rm.PreviewDiscovery(git.DiscoverOptions{MaxDepth: req.MaxDepth, Exclude: req.Exclude})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
 	}
 
-	if req.MaxDepth <= 0 {
-		req.MaxDepth = 3
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(discovered)
+}
+
+// HandleDiscoverRepositories handles POST /api/repositories/discover - {maxDepth,
+// exclude, paths} - adding the discovered repositories, or only those at paths
+func (rm *RepositoryManager) HandleDiscoverRepositories(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
 	}
 
-	if err := rm.DiscoverRepositories(req.MaxDepth); err != nil {
+	req := decodeDiscoverRequest(r)
+	added, err := rm.DiscoverRepositories(git.DiscoverOptions{MaxDepth: req.MaxDepth, Exclude: req.Exclude}, req.Paths)
+	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -343,6 +811,7 @@ func (rm *RepositoryManager) HandleDiscoverRepositories(w http.ResponseWriter, r
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]interface{}{
 		"status":       "success",
-		"repositories": rm.repositories,
+		"added":        added,
+		"repositories": rm.GetRepositories(),
 	})
 } 
\ No newline at end of file
@@ -0,0 +1,266 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/knoxai/gait/internal/git"
+	"github.com/knoxai/gait/pkg/types"
+)
+
+// maxConcurrentRepositories limits how many repositories a cross-repository view reads
+// at once
+const maxConcurrentRepositories = 8
+
+// normalizeTags trims, de-duplicates and sorts repository tags
+func normalizeTags(tags []string) []string {
+	seen := make(map[string]bool)
+	normalized := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		tag = strings.TrimSpace(tag)
+		if tag == "" || seen[strings.ToLower(tag)] {
+			continue
+		}
+		seen[strings.ToLower(tag)] = true
+		normalized = append(normalized, tag)
+	}
+	sort.Strings(normalized)
+	return normalized
+}
+
+// hasTag reports whether a repository has a tag, ignoring case
+func hasTag(repo types.Repository, tag string) bool {
+	for _, t := range repo.Tags {
+		if strings.EqualFold(t, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// GetGroups returns the named groups of the managed repositories with the tags used
+// in each
+func (rm *RepositoryManager) GetGroups() []types.RepositoryGroup {
+	groups := make(map[string]*types.RepositoryGroup)
+	for _, repo := range rm.GetRepositories() {
+		if repo.Group == "" {
+			continue
+		}
+		group, ok := groups[repo.Group]
+		if !ok {
+			group = &types.RepositoryGroup{Name: repo.Group, Tags: []string{}}
+			groups[repo.Group] = group
+		}
+		group.Repositories++
+		group.Tags = normalizeTags(append(group.Tags, repo.Tags...))
+	}
+
+	result := make([]types.RepositoryGroup, 0, len(groups))
+	for _, group := range groups {
+		result = append(result, *group)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result
+}
+
+// SelectRepositories returns the managed repositories in a group and with a tag; an
+// empty group or tag doesn't filter
+func (rm *RepositoryManager) SelectRepositories(group, tag string) []types.Repository {
+	selected := make([]types.Repository, 0)
+	for _, repo := range rm.GetRepositories() {
+		if group != "" && repo.Group != group {
+			continue
+		}
+		if tag != "" && !hasTag(repo, tag) {
+			continue
+		}
+		selected = append(selected, repo)
+	}
+	return selected
+}
+
+// repositoryName returns the name a repository is shown with
+func repositoryName(repo types.Repository) string {
+	if repo.DisplayName != "" {
+		return repo.DisplayName
+	}
+	return repo.Name
+}
+
+// forEachRepository calls fn concurrently for each repository with its pooled git
+// service bound to ctx, at most maxConcurrentRepositories at a time, and returns the
+// failures
+func (rm *RepositoryManager) forEachRepository(ctx context.Context, repos []types.Repository, fn func(repo types.Repository, service *git.Service) error) []types.RepositoryError {
+	var (
+		wg     sync.WaitGroup
+		mu     sync.Mutex
+		errors = make([]types.RepositoryError, 0)
+		slots  = make(chan struct{}, maxConcurrentRepositories)
+	)
+	for _, repo := range repos {
+		wg.Add(1)
+		go func(repo types.Repository) {
+			defer wg.Done()
+			slots <- struct{}{}
+			defer func() { <-slots }()
+
+			service, err := rm.Service(repo.Path)
+			if err == nil {
+				err = fn(repo, service.WithContext(ctx))
+			}
+			if err != nil {
+				mu.Lock()
+				errors = append(errors, types.RepositoryError{RepositoryID: repo.ID, RepositoryName: repositoryName(repo), Error: err.Error()})
+				mu.Unlock()
+			}
+		}(repo)
+	}
+	wg.Wait()
+	sort.Slice(errors, func(i, j int) bool { return errors[i].RepositoryName < errors[j].RepositoryName })
+	return errors
+}
+
+// RecentCommits returns the most recent commits across repositories, newest first
+func (rm *RepositoryManager) RecentCommits(ctx context.Context, repos []types.Repository, limit int) ([]types.RepositoryCommit, []types.RepositoryError) {
+	var mu sync.Mutex
+	commits := make([]types.RepositoryCommit, 0)
+	errors := rm.forEachRepository(ctx, repos, func(repo types.Repository, service *git.Service) error {
+		repoCommits, err := service.GetCommits(limit, "", true)
+		if err != nil {
+			return err
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		for _, commit := range repoCommits {
+			commits = append(commits, types.RepositoryCommit{RepositoryID: repo.ID, RepositoryName: repositoryName(repo), Commit: commit})
+		}
+		return nil
+	})
+
+	sort.SliceStable(commits, func(i, j int) bool { return commits[i].Date.After(commits[j].Date) })
+	if len(commits) > limit {
+		commits = commits[:limit]
+	}
+	return commits, errors
+}
+
+// UncommittedWork returns the repositories whose working tree has uncommitted changes
+func (rm *RepositoryManager) UncommittedWork(ctx context.Context, repos []types.Repository) ([]types.RepositoryWork, []types.RepositoryError) {
+	var mu sync.Mutex
+	work := make([]types.RepositoryWork, 0)
+	errors := rm.forEachRepository(ctx, repos, func(repo types.Repository, service *git.Service) error {
+		changes, err := service.GetUncommittedChanges()
+		if err != nil {
+			return err
+		}
+		if len(changes) == 0 {
+			return nil
+		}
+		branch := ""
+		if branches, err := service.GetBranches(); err == nil {
+			for _, b := range branches {
+				if b.IsCurrent {
+					branch = b.Name
+					break
+				}
+			}
+		}
+		mu.Lock()
+		work = append(work, types.RepositoryWork{RepositoryID: repo.ID, RepositoryName: repositoryName(repo), Branch: branch, Changes: changes})
+		mu.Unlock()
+		return nil
+	})
+
+	sort.Slice(work, func(i, j int) bool { return work[i].RepositoryName < work[j].RepositoryName })
+	return work, errors
+}
+
+// BehindUpstream returns the repositories with local branches behind their upstream, as
+// of each repository's last fetch
+func (rm *RepositoryManager) BehindUpstream(ctx context.Context, repos []types.Repository) ([]types.RepositoryBehind, []types.RepositoryError) {
+	var mu sync.Mutex
+	behind := make([]types.RepositoryBehind, 0)
+	errors := rm.forEachRepository(ctx, repos, func(repo types.Repository, service *git.Service) error {
+		branches, err := service.GetBranches()
+		if err != nil {
+			return err
+		}
+		behindBranches := make([]types.Branch, 0)
+		for _, branch := range branches {
+			if branch.Behind > 0 {
+				behindBranches = append(behindBranches, branch)
+			}
+		}
+		if len(behindBranches) == 0 {
+			return nil
+		}
+		mu.Lock()
+		behind = append(behind, types.RepositoryBehind{RepositoryID: repo.ID, RepositoryName: repositoryName(repo), Branches: behindBranches})
+		mu.Unlock()
+		return nil
+	})
+
+	sort.Slice(behind, func(i, j int) bool { return behind[i].RepositoryName < behind[j].RepositoryName })
+	return behind, errors
+}
+
+// HandleGetGroups handles GET /api/repositories/groups
+func (rm *RepositoryManager) HandleGetGroups(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(rm.GetGroups())
+}
+
+// HandleRecentCommits handles GET /api/repositories/commits?group=&tag=&limit= - the
+// newest commits across the selected repositories
+func (rm *RepositoryManager) HandleRecentCommits(w http.ResponseWriter, r *http.Request) {
+	limit := 50
+	if l := r.URL.Query().Get("limit"); l != "" {
+		if parsed, err := strconv.Atoi(l); err == nil && parsed > 0 {
+			limit = parsed
+		}
+	}
+	rm.serveView(w, r, func(repos []types.Repository) (interface{}, []types.RepositoryError) {
+		return rm.RecentCommits(r.Context(), repos, limit)
+	})
+}
+
+// HandleUncommittedWork handles GET /api/repositories/uncommitted?group=&tag=
+func (rm *RepositoryManager) HandleUncommittedWork(w http.ResponseWriter, r *http.Request) {
+	rm.serveView(w, r, func(repos []types.Repository) (interface{}, []types.RepositoryError) {
+		return rm.UncommittedWork(r.Context(), repos)
+	})
+}
+
+// HandleBehindUpstream handles GET /api/repositories/behind?group=&tag=
+func (rm *RepositoryManager) HandleBehindUpstream(w http.ResponseWriter, r *http.Request) {
+	rm.serveView(w, r, func(repos []types.Repository) (interface{}, []types.RepositoryError) {
+		return rm.BehindUpstream(r.Context(), repos)
+	})
+}
+
+// serveView computes a cross-repository view over the repositories selected by the
+// group and tag query parameters
+func (rm *RepositoryManager) serveView(w http.ResponseWriter, r *http.Request, compute func(repos []types.Repository) (interface{}, []types.RepositoryError)) {
+	if r.Method != "GET" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	group := r.URL.Query().Get("group")
+	tag := r.URL.Query().Get("tag")
+	repos := rm.SelectRepositories(group, tag)
+	items, errors := compute(repos)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(types.RepositoryView{
+		Group:        group,
+		Tag:          tag,
+		Repositories: len(repos),
+		Items:        items,
+		Errors:       errors,
+	})
+}
@@ -0,0 +1,105 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/knoxai/gait/internal/git"
+	"github.com/knoxai/gait/pkg/types"
+)
+
+// Search runs a search in each selected repository concurrently and merges the results,
+// ranked best first and tagged with the repository they came from
+func (rm *RepositoryManager) Search(ctx context.Context, req types.SearchRequest) (*types.SearchResponse, error) {
+	if strings.TrimSpace(req.Query) == "" {
+		return nil, fmt.Errorf("search query cannot be empty")
+	}
+	if err := git.ValidateSearchRequest(req); err != nil {
+		return nil, err
+	}
+	repos, err := rm.searchRepositories(req)
+	if err != nil {
+		return nil, err
+	}
+	limit := req.MaxResults
+	if limit <= 0 {
+		limit = 100
+		req.MaxResults = limit
+	}
+
+	var mu sync.Mutex
+	results := make([]types.SearchResult, 0)
+	errors := rm.forEachRepository(ctx, repos, func(repo types.Repository, service *git.Service) error {
+		found, err := service.Search(req)
+		if err != nil {
+			return err
+		}
+		for i := range found {
+			found[i].RepositoryID = repo.ID
+			found[i].RepositoryName = repositoryName(repo)
+		}
+		mu.Lock()
+		results = append(results, found...)
+		mu.Unlock()
+		return nil
+	})
+
+	git.SortSearchResults(results)
+	if len(results) > limit {
+		results = results[:limit]
+	}
+	return &types.SearchResponse{Results: results, Repositories: len(repos), Errors: errors}, nil
+}
+
+// searchRepositories returns the repositories a search runs in: those listed by ID or
+// path, otherwise those in the requested group and with the requested tag
+func (rm *RepositoryManager) searchRepositories(req types.SearchRequest) ([]types.Repository, error) {
+	if len(req.Repositories) == 0 {
+		return rm.SelectRepositories(req.Group, req.Tag), nil
+	}
+
+	all := rm.GetRepositories()
+	repos := make([]types.Repository, 0, len(req.Repositories))
+	for _, idOrPath := range req.Repositories {
+		found := false
+		for _, repo := range all {
+			if repo.ID == idOrPath || repo.Path == idOrPath {
+				repos = append(repos, repo)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("repository not found in managed list: %s", idOrPath)
+		}
+	}
+	return repos, nil
+}
+
+// HandleSearch handles POST /api/repositories/search - {query, type|types, maxResults,
+// group?, tag?, repositories?}
+func (rm *RepositoryManager) HandleSearch(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req types.SearchRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	response, err := rm.Search(r.Context(), req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,82 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/knoxai/gait/internal/git"
+	"github.com/knoxai/gait/pkg/types"
+)
+
+// CherryPick handles POST /api/cherry-pick - {commits, range, branch, recordOrigin, mainline, noCommit}
+func (h *Handler) CherryPick(w http.ResponseWriter, r *http.Request) {
+	h.runSequence(w, r, h.git(r).CherryPick)
+}
+
+// Revert handles POST /api/revert - {commits, range, branch, mainline, noCommit}
+func (h *Handler) Revert(w http.ResponseWriter, r *http.Request) {
+	h.runSequence(w, r, h.git(r).Revert)
+}
+
+// runSequence decodes sequence options and runs a cherry-pick or revert with them
+func (h *Handler) runSequence(w http.ResponseWriter, r *http.Request, run func(git.SequenceOptions) (*types.SequenceResult, error)) {
+	if r.Method != "POST" {
+		h.writeErrorResponse(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req git.SequenceOptions
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.writeErrorResponse(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	result, err := run(req)
+	if err != nil {
+		h.writeGitError(w, err, http.StatusBadRequest)
+		return
+	}
+
+	h.writeJSONResponse(w, result)
+}
+
+// GetSequenceStatus handles GET /api/sequence/status
+func (h *Handler) GetSequenceStatus(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		h.writeErrorResponse(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	h.writeJSONResponse(w, h.git(r).GetSequenceStatus())
+}
+
+// ContinueSequence handles POST /api/sequence/continue
+func (h *Handler) ContinueSequence(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		h.writeErrorResponse(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	result, err := h.git(r).ContinueSequence()
+	if err != nil {
+		h.writeGitError(w, err, http.StatusConflict)
+		return
+	}
+
+	h.writeJSONResponse(w, result)
+}
+
+// AbortSequence handles POST /api/sequence/abort
+func (h *Handler) AbortSequence(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		h.writeErrorResponse(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if err := h.git(r).AbortSequence(); err != nil {
+		h.writeGitError(w, err, http.StatusConflict)
+		return
+	}
+
+	h.writeJSONResponse(w, map[string]string{"status": "success"})
+}
@@ -0,0 +1,66 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+// GetStashFileDiff handles GET /api/stash/{stash}/diff?path=...
+func (h *Handler) GetStashFileDiff(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		h.writeErrorResponse(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	index, err := strconv.Atoi(mux.Vars(r)["stash"])
+	if err != nil {
+		h.writeErrorResponse(w, "Invalid stash index", http.StatusBadRequest)
+		return
+	}
+	path := r.URL.Query().Get("path")
+	if path == "" {
+		h.writeErrorResponse(w, "File path required", http.StatusBadRequest)
+		return
+	}
+
+	diff, err := h.git(r).GetStashFileDiff(index, path)
+	if err != nil {
+		h.writeGitError(w, err, http.StatusNotFound)
+		return
+	}
+
+	h.writeJSONResponse(w, diff)
+}
+
+// ApplyStashFile handles POST /api/stash/{stash}/apply-file - {path, overwrite}
+func (h *Handler) ApplyStashFile(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		h.writeErrorResponse(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	index, err := strconv.Atoi(mux.Vars(r)["stash"])
+	if err != nil {
+		h.writeErrorResponse(w, "Invalid stash index", http.StatusBadRequest)
+		return
+	}
+
+	var req struct {
+		Path      string `json:"path"`
+		Overwrite bool   `json:"overwrite"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.writeErrorResponse(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.git(r).ApplyStashFile(index, req.Path, req.Overwrite); err != nil {
+		h.writeGitError(w, err, http.StatusConflict)
+		return
+	}
+
+	h.writeJSONResponse(w, map[string]string{"status": "success"})
+}
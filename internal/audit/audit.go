@@ -0,0 +1,237 @@
+package audit
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// Outcomes of audited operations
+const (
+	OutcomeSuccess  = "success"
+	OutcomeFailure  = "failure"
+	OutcomeDenied   = "denied"   // refused for lack of a role or by branch protection
+	OutcomeAccepted = "accepted" // started as a background job, whose result is a later entry
+)
+
+// Entry is one audited operation
+type Entry struct {
+	ID         int64           `json:"id"`
+	Timestamp  time.Time       `json:"timestamp"`
+	User       string          `json:"user"`
+	AuthMethod string          `json:"authMethod"`
+	RemoteAddr string          `json:"remoteAddr,omitempty"`
+	Repository string          `json:"repository"`
+	Operation  string          `json:"operation"` // method and route, such as "POST /api/branch/reset"
+	Arguments  json.RawMessage `json:"arguments"`
+	Outcome    string          `json:"outcome"`
+	Status     int             `json:"status,omitempty"` // HTTP status of the response
+	Error      string          `json:"error,omitempty"`
+	DurationMs int64           `json:"durationMs"`
+}
+
+// Filter selects audit entries; empty fields match everything
+type Filter struct {
+	User       string
+	Repository string
+	Operation  string // substring of the operation
+	Outcome    string
+	Since      time.Time
+	Until      time.Time
+	Limit      int
+	Offset     int
+}
+
+// DefaultLimit and MaxLimit bound the entries a query returns
+const (
+	DefaultLimit = 100
+	MaxLimit     = 1000
+)
+
+const schema = `
+CREATE TABLE IF NOT EXISTS audit_log (
+	id          INTEGER PRIMARY KEY AUTOINCREMENT,
+	timestamp   TEXT    NOT NULL,
+	user        TEXT    NOT NULL,
+	auth_method TEXT    NOT NULL,
+	remote_addr TEXT    NOT NULL DEFAULT '',
+	repository  TEXT    NOT NULL DEFAULT '',
+	operation   TEXT    NOT NULL,
+	arguments   TEXT    NOT NULL DEFAULT '{}',
+	outcome     TEXT    NOT NULL,
+	status      INTEGER NOT NULL DEFAULT 0,
+	error       TEXT    NOT NULL DEFAULT '',
+	duration_ms INTEGER NOT NULL DEFAULT 0
+);
+CREATE INDEX IF NOT EXISTS audit_log_timestamp ON audit_log (timestamp);
+CREATE INDEX IF NOT EXISTS audit_log_user ON audit_log (user);
+CREATE INDEX IF NOT EXISTS audit_log_repository ON audit_log (repository);
+CREATE TRIGGER IF NOT EXISTS audit_log_no_update BEFORE UPDATE ON audit_log
+BEGIN
+	SELECT RAISE(ABORT, 'the audit log is append-only');
+END;
+CREATE TRIGGER IF NOT EXISTS audit_log_no_delete BEFORE DELETE ON audit_log
+BEGIN
+	SELECT RAISE(ABORT, 'the audit log is append-only');
+END;
+`
+
+// timestampLayout stores timestamps in UTC with a fixed width, so they sort as text
+const timestampLayout = "2006-01-02T15:04:05.000000000Z"
+
+// Log is an append-only audit log kept in a SQLite database. Entries can be added and
+// read but never changed or removed, which triggers in the database enforce.
+type Log struct {
+	db *sql.DB
+	mu sync.Mutex // serializes writes, which SQLite does not run concurrently
+}
+
+// Open opens the audit log at path, creating the database and its directory if needed
+func Open(path string) (*Log, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create audit log directory: %v", err)
+	}
+	db, err := sql.Open("sqlite3", path+"?_busy_timeout=5000&_journal_mode=WAL")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit log: %v", err)
+	}
+	if _, err := db.Exec(schema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to initialize audit log: %v", err)
+	}
+	return &Log{db: db}, nil
+}
+
+// Close closes the audit log database
+func (l *Log) Close() error {
+	return l.db.Close()
+}
+
+// Record appends an entry to the log, stamping it with the current time if it has none
+func (l *Log) Record(entry Entry) error {
+	if entry.Timestamp.IsZero() {
+		entry.Timestamp = time.Now()
+	}
+	if len(entry.Arguments) == 0 {
+		entry.Arguments = json.RawMessage("{}")
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	_, err := l.db.Exec(`INSERT INTO audit_log
+		(timestamp, user, auth_method, remote_addr, repository, operation, arguments, outcome, status, error, duration_ms)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		entry.Timestamp.UTC().Format(timestampLayout), entry.User, entry.AuthMethod, entry.RemoteAddr,
+		entry.Repository, entry.Operation, string(entry.Arguments), entry.Outcome, entry.Status,
+		entry.Error, entry.DurationMs)
+	return err
+}
+
+// where returns the SQL conditions and arguments of a filter
+func (f Filter) where() (string, []interface{}) {
+	conditions := make([]string, 0)
+	args := make([]interface{}, 0)
+	if f.User != "" {
+		conditions = append(conditions, "user = ?")
+		args = append(args, f.User)
+	}
+	if f.Repository != "" {
+		conditions = append(conditions, "repository = ?")
+		args = append(args, f.Repository)
+	}
+	if f.Operation != "" {
+		conditions = append(conditions, "instr(operation, ?) > 0")
+		args = append(args, f.Operation)
+	}
+	if f.Outcome != "" {
+		conditions = append(conditions, "outcome = ?")
+		args = append(args, f.Outcome)
+	}
+	if !f.Since.IsZero() {
+		conditions = append(conditions, "timestamp >= ?")
+		args = append(args, f.Since.UTC().Format(timestampLayout))
+	}
+	if !f.Until.IsZero() {
+		conditions = append(conditions, "timestamp < ?")
+		args = append(args, f.Until.UTC().Format(timestampLayout))
+	}
+	if len(conditions) == 0 {
+		return "", args
+	}
+	return " WHERE " + strings.Join(conditions, " AND "), args
+}
+
+const selectColumns = `SELECT id, timestamp, user, auth_method, remote_addr, repository, operation,
+	arguments, outcome, status, error, duration_ms FROM audit_log`
+
+// Query returns the entries matching filter, newest first
+func (l *Log) Query(filter Filter) ([]Entry, error) {
+	if filter.Limit <= 0 {
+		filter.Limit = DefaultLimit
+	}
+	if filter.Limit > MaxLimit {
+		filter.Limit = MaxLimit
+	}
+	where, args := filter.where()
+	rows, err := l.db.Query(selectColumns+where+" ORDER BY id DESC LIMIT ? OFFSET ?",
+		append(args, filter.Limit, filter.Offset)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := make([]Entry, 0)
+	for rows.Next() {
+		entry, err := scanEntry(rows)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+	return entries, rows.Err()
+}
+
+// Export writes the entries matching filter to w as JSON lines, oldest first. Limit and
+// offset are ignored, so the whole matching log is written.
+func (l *Log) Export(w io.Writer, filter Filter) error {
+	where, args := filter.where()
+	rows, err := l.db.Query(selectColumns+where+" ORDER BY id", args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	encoder := json.NewEncoder(w)
+	for rows.Next() {
+		entry, err := scanEntry(rows)
+		if err != nil {
+			return err
+		}
+		if err := encoder.Encode(entry); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+func scanEntry(rows *sql.Rows) (Entry, error) {
+	var entry Entry
+	var timestamp, arguments string
+	err := rows.Scan(&entry.ID, &timestamp, &entry.User, &entry.AuthMethod, &entry.RemoteAddr,
+		&entry.Repository, &entry.Operation, &arguments, &entry.Outcome, &entry.Status,
+		&entry.Error, &entry.DurationMs)
+	if err != nil {
+		return entry, err
+	}
+	entry.Timestamp, _ = time.Parse(timestampLayout, timestamp)
+	entry.Arguments = json.RawMessage(arguments)
+	return entry, nil
+}
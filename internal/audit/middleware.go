@@ -0,0 +1,338 @@
+package audit
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/knoxai/gait/internal/auth"
+	"github.com/knoxai/gait/internal/jobs"
+	"github.com/knoxai/gait/pkg/types"
+)
+
+// Limits on what an entry keeps of a request and its response
+const (
+	maxBodyBytes     = 64 << 10
+	maxErrorBytes    = 4 << 10
+	maxStringLength  = 256
+	maxArrayElements = 20
+)
+
+// sensitiveKeys are fragments of argument names whose values are never recorded
+var sensitiveKeys = []string{"password", "passphrase", "token", "secret", "privatekey", "credential"}
+
+// RepositoryFunc returns the repository a request operates on
+type RepositoryFunc func(r *http.Request) string
+
+// Middleware is mux middleware that records every request that is not a read, with
+// who sent it, the repository, its arguments and how it turned out. Add it before
+// auth.Authorize so that refused requests are recorded too.
+func (l *Log) Middleware(repository RepositoryFunc) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == "GET" || r.Method == "HEAD" || r.Method == "OPTIONS" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			started := time.Now()
+			entry := Entry{
+				Timestamp:  started,
+				User:       "anonymous",
+				AuthMethod: "none",
+				RemoteAddr: r.RemoteAddr,
+				Operation:  r.Method + " " + r.URL.Path,
+			}
+			if principal := auth.FromContext(r.Context()); principal != nil {
+				entry.User = principal.Name
+				entry.AuthMethod = principal.Method
+			}
+			if route := mux.CurrentRoute(r); route != nil {
+				if template, err := route.GetPathTemplate(); err == nil {
+					entry.Operation = r.Method + " " + template
+				}
+			}
+			if repository != nil {
+				entry.Repository = repository(r)
+			}
+			entry.Arguments = requestArguments(r)
+
+			recorder := &responseRecorder{ResponseWriter: w, status: http.StatusOK}
+			next.ServeHTTP(recorder, r)
+
+			entry.Status = recorder.status
+			entry.DurationMs = time.Since(started).Milliseconds()
+			entry.Outcome, entry.Error = outcome(recorder)
+			if location := recorder.Header().Get("Location"); recorder.status == http.StatusAccepted && location != "" {
+				entry.Error = ""
+				entry.Arguments = withField(entry.Arguments, "job", strings.TrimPrefix(location, "/api/jobs/"))
+			}
+			if err := l.Record(entry); err != nil {
+				log.Printf("Failed to record audit entry for %s: %v", entry.Operation, err)
+			}
+		})
+	}
+}
+
+// RecordJob records how a background job ended, as an entry for the job that refers
+// back to the entry of the request that started it through the job ID
+func (l *Log) RecordJob(job types.Job) {
+	entry := Entry{
+		User:       "system",
+		AuthMethod: "job",
+		Repository: job.Target,
+		Operation:  "job " + job.Type,
+		Error:      job.Error,
+	}
+	entry.Arguments, _ = json.Marshal(map[string]string{"job": job.ID})
+	entry.Outcome = OutcomeFailure
+	if job.Status == jobs.StatusSucceeded {
+		entry.Outcome = OutcomeSuccess
+	}
+	if job.FinishedAt != nil {
+		entry.Timestamp = *job.FinishedAt
+		entry.DurationMs = job.FinishedAt.Sub(job.StartedAt).Milliseconds()
+	}
+	if err := l.Record(entry); err != nil {
+		log.Printf("Failed to record audit entry for job %s: %v", job.ID, err)
+	}
+}
+
+// requestArguments returns the route variables, query parameters and JSON body of a
+// request as one JSON object, with secrets redacted and long values shortened. The body
+// is put back for the handler to read.
+func requestArguments(r *http.Request) json.RawMessage {
+	args := make(map[string]interface{})
+	for key, value := range mux.Vars(r) {
+		args[key] = value
+	}
+	for key, values := range r.URL.Query() {
+		if len(values) == 1 {
+			args[key] = values[0]
+		} else {
+			args[key] = values
+		}
+	}
+
+	if r.Body != nil && r.Body != http.NoBody {
+		body, _ := io.ReadAll(io.LimitReader(r.Body, maxBodyBytes+1))
+		r.Body = struct {
+			io.Reader
+			io.Closer
+		}{io.MultiReader(bytes.NewReader(body), r.Body), r.Body}
+
+		var decoded interface{}
+		switch {
+		case len(body) == 0:
+		case len(body) <= maxBodyBytes && json.Unmarshal(body, &decoded) == nil:
+			if fields, ok := decoded.(map[string]interface{}); ok {
+				for key, value := range fields {
+					args[key] = value
+				}
+			} else {
+				args["body"] = decoded
+			}
+		default:
+			args["body"] = fmt.Sprintf("[%s body, not recorded]", contentType(r))
+		}
+	}
+
+	data, err := json.Marshal(sanitize("", args))
+	if err != nil {
+		return json.RawMessage("{}")
+	}
+	return data
+}
+
+func contentType(r *http.Request) string {
+	if value := r.Header.Get("Content-Type"); value != "" {
+		return strings.TrimSpace(strings.Split(value, ";")[0])
+	}
+	return "binary"
+}
+
+// sanitize redacts the values of sensitive keys and shortens long strings and arrays
+func sanitize(key string, value interface{}) interface{} {
+	lower := strings.ToLower(key)
+	for _, sensitive := range sensitiveKeys {
+		if strings.Contains(lower, sensitive) {
+			return "[redacted]"
+		}
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		clean := make(map[string]interface{}, len(v))
+		for k, item := range v {
+			clean[k] = sanitize(k, item)
+		}
+		return clean
+	case []interface{}:
+		if len(v) > maxArrayElements {
+			return fmt.Sprintf("[%d items]", len(v))
+		}
+		clean := make([]interface{}, len(v))
+		for i, item := range v {
+			clean[i] = sanitize(key, item)
+		}
+		return clean
+	case []string:
+		items := make([]interface{}, len(v))
+		for i, item := range v {
+			items[i] = item
+		}
+		return sanitize(key, items)
+	case string:
+		if len(v) > maxStringLength {
+			return v[:maxStringLength] + "… (" + strconv.Itoa(len(v)) + " bytes)"
+		}
+	}
+	return value
+}
+
+// withField adds a field to a JSON object
+func withField(object json.RawMessage, key string, value interface{}) json.RawMessage {
+	fields := make(map[string]interface{})
+	json.Unmarshal(object, &fields)
+	fields[key] = value
+	data, err := json.Marshal(fields)
+	if err != nil {
+		return object
+	}
+	return data
+}
+
+// outcome classifies a response and returns its error message, if any
+func outcome(recorder *responseRecorder) (string, string) {
+	switch {
+	case recorder.status == http.StatusAccepted:
+		return OutcomeAccepted, ""
+	case recorder.status < 400:
+		return OutcomeSuccess, ""
+	}
+
+	message := strings.TrimSpace(recorder.body.String())
+	var body struct {
+		Error string `json:"error"`
+	}
+	if json.Unmarshal(recorder.body.Bytes(), &body) == nil && body.Error != "" {
+		message = body.Error
+	}
+	if recorder.status == http.StatusForbidden {
+		return OutcomeDenied, message
+	}
+	return OutcomeFailure, message
+}
+
+// responseRecorder passes a response through while noting its status and the start of
+// an error body
+type responseRecorder struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+	body        bytes.Buffer
+}
+
+func (r *responseRecorder) WriteHeader(status int) {
+	if !r.wroteHeader {
+		r.status = status
+		r.wroteHeader = true
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *responseRecorder) Write(data []byte) (int, error) {
+	r.wroteHeader = true
+	if r.status >= 400 && r.body.Len() < maxErrorBytes {
+		remaining := maxErrorBytes - r.body.Len()
+		if len(data) < remaining {
+			remaining = len(data)
+		}
+		r.body.Write(data[:remaining])
+	}
+	return r.ResponseWriter.Write(data)
+}
+
+// Flush lets streamed responses, such as job progress, through the recorder
+func (r *responseRecorder) Flush() {
+	if flusher, ok := r.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// filterFromRequest reads a Filter from query parameters: user, repository, operation,
+// outcome, since and until (RFC 3339), limit and offset
+func filterFromRequest(r *http.Request) (Filter, error) {
+	query := r.URL.Query()
+	filter := Filter{
+		User:       query.Get("user"),
+		Repository: query.Get("repository"),
+		Operation:  query.Get("operation"),
+		Outcome:    query.Get("outcome"),
+	}
+	for name, target := range map[string]*time.Time{"since": &filter.Since, "until": &filter.Until} {
+		if value := query.Get(name); value != "" {
+			parsed, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				return filter, fmt.Errorf("%s must be an RFC 3339 time such as 2026-01-02T15:04:05Z", name)
+			}
+			*target = parsed
+		}
+	}
+	for name, target := range map[string]*int{"limit": &filter.Limit, "offset": &filter.Offset} {
+		if value := query.Get(name); value != "" {
+			parsed, err := strconv.Atoi(value)
+			if err != nil || parsed < 0 {
+				return filter, fmt.Errorf("%s must be a non-negative number", name)
+			}
+			*target = parsed
+		}
+	}
+	return filter, nil
+}
+
+// HandleQuery handles GET /api/audit - audit entries, newest first, filtered by
+// ?user=&repository=&operation=&outcome=&since=&until=&limit=&offset=
+func (l *Log) HandleQuery(w http.ResponseWriter, r *http.Request) {
+	filter, err := filterFromRequest(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	entries, err := l.Query(filter)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(entries)
+}
+
+// HandleExport handles GET /api/audit/export - the audit entries matching the same
+// filters as /api/audit, oldest first, as a JSON lines download
+func (l *Log) HandleExport(w http.ResponseWriter, r *http.Request) {
+	filter, err := filterFromRequest(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="gait-audit-%s.jsonl"`, time.Now().Format("20060102-150405")))
+	if err := l.Export(w, filter); err != nil {
+		// Headers are gone once streaming starts, so the export just ends early
+		log.Printf("Audit export failed: %v", err)
+	}
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{"error": message})
+}
@@ -0,0 +1,397 @@
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Role is the level of access a principal has; each role includes the ones before it
+type Role string
+
+// Roles, from least to most access
+const (
+	RoleViewer    Role = "viewer"    // read repositories
+	RoleCommitter Role = "committer" // change repositories: commit, branch, stash, push
+	RoleAdmin     Role = "admin"     // manage the server: repositories, credentials, remotes, destructive resets
+)
+
+var roleLevels = map[Role]int{RoleViewer: 1, RoleCommitter: 2, RoleAdmin: 3}
+
+// Valid reports whether r is a known role
+func (r Role) Valid() bool {
+	_, ok := roleLevels[r]
+	return ok
+}
+
+// Includes reports whether r grants at least the access of required
+func (r Role) Includes(required Role) bool {
+	return roleLevels[r] >= roleLevels[required]
+}
+
+// Principal is the authenticated caller of a request
+type Principal struct {
+	Name   string `json:"name"`
+	Role   Role   `json:"role"`
+	Method string `json:"method"` // password, token, proxy or anonymous
+}
+
+// Authenticator identifies the caller of a request by one method. It returns nil
+// without an error when the request carries no credentials for the method, and an error
+// when it carries credentials that are not valid.
+type Authenticator interface {
+	Authenticate(r *http.Request) (*Principal, error)
+}
+
+// ErrInvalidCredentials is returned for credentials that don't match any user or token
+var ErrInvalidCredentials = errors.New("invalid credentials")
+
+// Config is the saved authentication setup, read from .gait/auth.json
+type Config struct {
+	Users         []User       `json:"users,omitempty"`
+	Tokens        []Token      `json:"tokens,omitempty"`
+	Proxy         *ProxyConfig `json:"proxy,omitempty"`
+	AnonymousRole Role         `json:"anonymousRole,omitempty"` // role of requests without credentials; empty rejects them
+}
+
+// User is a local user who signs in with HTTP Basic authentication
+type User struct {
+	Username     string `json:"username"`
+	PasswordHash string `json:"passwordHash"` // bcrypt hash, as printed by gait -hash-password
+	Role         Role   `json:"role"`
+}
+
+// Token is a static API token sent as "Authorization: Bearer <token>", or as the
+// access_token query parameter where headers can't be set
+type Token struct {
+	Name        string `json:"name"`
+	Token       string `json:"token,omitempty"`
+	TokenSHA256 string `json:"tokenSha256,omitempty"` // hex SHA-256 of the token, to avoid storing it
+	Role        Role   `json:"role"`
+}
+
+// ProxyConfig trusts the user named by a header set by an authenticating reverse proxy
+type ProxyConfig struct {
+	UserHeader     string          `json:"userHeader,omitempty"` // default X-Forwarded-User
+	RoleHeader     string          `json:"roleHeader,omitempty"` // optional header carrying the role
+	TrustedProxies []string        `json:"trustedProxies"`       // addresses or CIDRs the headers are accepted from
+	Roles          map[string]Role `json:"roles,omitempty"`      // roles of individual users
+	DefaultRole    Role            `json:"defaultRole,omitempty"`
+}
+
+// LoadConfig reads the config at path; it returns nil without an error when the file
+// doesn't exist, which leaves authentication off
+func LoadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var config Config
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("invalid %s: %v", path, err)
+	}
+	return &config, nil
+}
+
+// HashPassword returns the bcrypt hash of a password for a User entry
+func HashPassword(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	return string(hash), err
+}
+
+// Service authenticates requests with a chain of authenticators
+type Service struct {
+	authenticators []Authenticator
+	anonymousRole  Role
+	basicRealm     bool // challenge browsers for a password
+}
+
+// New creates a Service for config with the authenticators it enables
+func New(config *Config) (*Service, error) {
+	s := &Service{anonymousRole: config.AnonymousRole}
+	if s.anonymousRole != "" && !s.anonymousRole.Valid() {
+		return nil, fmt.Errorf("unknown anonymous role %q", s.anonymousRole)
+	}
+
+	if len(config.Users) > 0 {
+		users, err := newLocalUsers(config.Users)
+		if err != nil {
+			return nil, err
+		}
+		s.Use(users)
+		s.basicRealm = true
+	}
+	if len(config.Tokens) > 0 {
+		tokens, err := newStaticTokens(config.Tokens)
+		if err != nil {
+			return nil, err
+		}
+		s.Use(tokens)
+	}
+	if config.Proxy != nil {
+		proxy, err := newProxyHeaders(*config.Proxy)
+		if err != nil {
+			return nil, err
+		}
+		s.Use(proxy)
+	}
+	if len(s.authenticators) == 0 && s.anonymousRole == "" {
+		return nil, fmt.Errorf("no users, tokens or proxy configured")
+	}
+	return s, nil
+}
+
+// Use adds an authenticator to the chain; the first one to recognise a request's
+// credentials decides who the caller is
+func (s *Service) Use(authenticator Authenticator) {
+	s.authenticators = append(s.authenticators, authenticator)
+}
+
+// Authenticate identifies the caller of each request and stores it in the request
+// context. Requests with invalid credentials, or without credentials when anonymous
+// access is off, fail with 401.
+func (s *Service) Authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var principal *Principal
+		for _, authenticator := range s.authenticators {
+			found, err := authenticator.Authenticate(r)
+			if err != nil {
+				s.unauthorized(w, err.Error())
+				return
+			}
+			if found != nil {
+				principal = found
+				break
+			}
+		}
+		if principal == nil {
+			if s.anonymousRole == "" {
+				s.unauthorized(w, "authentication required")
+				return
+			}
+			principal = &Principal{Name: "anonymous", Role: s.anonymousRole, Method: "anonymous"}
+		}
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), principalContextKey{}, principal)))
+	})
+}
+
+// unauthorized writes a 401, asking browsers for a password when local users exist
+func (s *Service) unauthorized(w http.ResponseWriter, message string) {
+	if s.basicRealm {
+		w.Header().Set("WWW-Authenticate", `Basic realm="gait", charset="UTF-8"`)
+	}
+	writeError(w, http.StatusUnauthorized, message, "unauthorized")
+}
+
+type principalContextKey struct{}
+
+// FromContext returns the principal stored by Authenticate, or nil when authentication
+// is off
+func FromContext(ctx context.Context) *Principal {
+	principal, _ := ctx.Value(principalContextKey{}).(*Principal)
+	return principal
+}
+
+// writeError writes a JSON error with a machine-readable code
+func writeError(w http.ResponseWriter, status int, message, code string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{"error": message, "code": code})
+}
+
+// verifiedTTL is how long a checked password is remembered, so that bcrypt doesn't run
+// on every request a browser sends
+const verifiedTTL = 5 * time.Minute
+
+// localUsers authenticates HTTP Basic credentials against bcrypt password hashes
+type localUsers struct {
+	users map[string]User
+
+	mu       sync.Mutex
+	verified map[string]time.Time // SHA-256 of username and password -> expiry
+}
+
+func newLocalUsers(users []User) (*localUsers, error) {
+	l := &localUsers{users: make(map[string]User), verified: make(map[string]time.Time)}
+	for _, user := range users {
+		if user.Username == "" || user.PasswordHash == "" {
+			return nil, fmt.Errorf("users need a username and passwordHash")
+		}
+		if !user.Role.Valid() {
+			return nil, fmt.Errorf("user %s has unknown role %q", user.Username, user.Role)
+		}
+		if _, err := bcrypt.Cost([]byte(user.PasswordHash)); err != nil {
+			return nil, fmt.Errorf("user %s: passwordHash is not a bcrypt hash", user.Username)
+		}
+		l.users[user.Username] = user
+	}
+	return l, nil
+}
+
+func (l *localUsers) Authenticate(r *http.Request) (*Principal, error) {
+	username, password, ok := r.BasicAuth()
+	if !ok {
+		return nil, nil
+	}
+	user, exists := l.users[username]
+	if !exists {
+		return nil, ErrInvalidCredentials
+	}
+
+	sum := sha256.Sum256([]byte(username + "\x00" + password + "\x00" + user.PasswordHash))
+	key := hex.EncodeToString(sum[:])
+	l.mu.Lock()
+	expiry, cached := l.verified[key]
+	l.mu.Unlock()
+	if !cached || time.Now().After(expiry) {
+		if bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)) != nil {
+			return nil, ErrInvalidCredentials
+		}
+		l.mu.Lock()
+		for k, e := range l.verified {
+			if time.Now().After(e) {
+				delete(l.verified, k)
+			}
+		}
+		l.verified[key] = time.Now().Add(verifiedTTL)
+		l.mu.Unlock()
+	}
+	return &Principal{Name: user.Username, Role: user.Role, Method: "password"}, nil
+}
+
+// staticTokens authenticates bearer tokens
+type staticTokens struct {
+	tokens []Token // with TokenSHA256 filled in
+}
+
+func newStaticTokens(tokens []Token) (*staticTokens, error) {
+	t := &staticTokens{}
+	for _, token := range tokens {
+		if !token.Role.Valid() {
+			return nil, fmt.Errorf("token %s has unknown role %q", token.Name, token.Role)
+		}
+		if token.Token != "" {
+			sum := sha256.Sum256([]byte(token.Token))
+			token.TokenSHA256 = hex.EncodeToString(sum[:])
+			token.Token = ""
+		}
+		if len(token.TokenSHA256) != sha256.Size*2 {
+			return nil, fmt.Errorf("token %s needs a token or tokenSha256", token.Name)
+		}
+		token.TokenSHA256 = strings.ToLower(token.TokenSHA256)
+		t.tokens = append(t.tokens, token)
+	}
+	return t, nil
+}
+
+func (t *staticTokens) Authenticate(r *http.Request) (*Principal, error) {
+	presented := r.URL.Query().Get("access_token")
+	if header := r.Header.Get("Authorization"); strings.HasPrefix(header, "Bearer ") {
+		presented = strings.TrimSpace(strings.TrimPrefix(header, "Bearer "))
+	}
+	if presented == "" {
+		return nil, nil
+	}
+
+	sum := sha256.Sum256([]byte(presented))
+	digest := []byte(hex.EncodeToString(sum[:]))
+	for _, token := range t.tokens {
+		if subtle.ConstantTimeCompare(digest, []byte(token.TokenSHA256)) == 1 {
+			return &Principal{Name: token.Name, Role: token.Role, Method: "token"}, nil
+		}
+	}
+	return nil, ErrInvalidCredentials
+}
+
+// proxyHeaders trusts the user header set by a reverse proxy that authenticated the user
+type proxyHeaders struct {
+	config  ProxyConfig
+	trusted []*net.IPNet
+}
+
+func newProxyHeaders(config ProxyConfig) (*proxyHeaders, error) {
+	if config.UserHeader == "" {
+		config.UserHeader = "X-Forwarded-User"
+	}
+	if config.DefaultRole != "" && !config.DefaultRole.Valid() {
+		return nil, fmt.Errorf("unknown proxy default role %q", config.DefaultRole)
+	}
+	for user, role := range config.Roles {
+		if !role.Valid() {
+			return nil, fmt.Errorf("proxy user %s has unknown role %q", user, role)
+		}
+	}
+	if len(config.TrustedProxies) == 0 {
+		return nil, fmt.Errorf("proxy mode needs trustedProxies")
+	}
+
+	p := &proxyHeaders{config: config}
+	for _, entry := range config.TrustedProxies {
+		if !strings.Contains(entry, "/") {
+			if strings.Contains(entry, ":") {
+				entry += "/128"
+			} else {
+				entry += "/32"
+			}
+		}
+		_, network, err := net.ParseCIDR(entry)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy %q: %v", entry, err)
+		}
+		p.trusted = append(p.trusted, network)
+	}
+	return p, nil
+}
+
+func (p *proxyHeaders) Authenticate(r *http.Request) (*Principal, error) {
+	user := r.Header.Get(p.config.UserHeader)
+	if user == "" || !p.fromTrustedProxy(r) {
+		// Headers from anyone but the proxy are ignored, never trusted
+		return nil, nil
+	}
+
+	role := p.config.Roles[user]
+	if role == "" && p.config.RoleHeader != "" {
+		role = Role(strings.ToLower(r.Header.Get(p.config.RoleHeader)))
+	}
+	if role == "" {
+		role = p.config.DefaultRole
+	}
+	if !role.Valid() {
+		return nil, fmt.Errorf("no role for proxy user %s", user)
+	}
+	return &Principal{Name: user, Role: role, Method: "proxy"}, nil
+}
+
+// fromTrustedProxy reports whether the request's peer is a trusted proxy
+func (p *proxyHeaders) fromTrustedProxy(r *http.Request) bool {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return false
+	}
+	for _, network := range p.trusted {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
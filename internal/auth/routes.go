@@ -0,0 +1,174 @@
+package auth
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+// Rule sets the minimum role of the routes with a path template, for some or all methods
+type Rule struct {
+	Path    string   // mux path template; one ending in / also covers the templates below it
+	Methods []string // empty covers every method
+	Role    Role
+}
+
+// DefaultRules are the exceptions to the method-based defaults: reads need viewer and
+// everything else committer. The first matching rule wins.
+var DefaultRules = []Rule{
+	// Server and repository management
+	{Path: "/api/repositories/search", Methods: []string{"POST"}, Role: RoleViewer},
+	{Path: "/api/repositories/", Methods: []string{"POST", "PUT", "DELETE"}, Role: RoleAdmin},
+	{Path: "/api/repository/switch", Role: RoleAdmin},
+	{Path: "/api/repository/clear", Role: RoleAdmin},
+	{Path: "/api/jobs/clone", Role: RoleAdmin},
+	{Path: "/api/auth/routes", Role: RoleAdmin},
+	{Path: "/api/audit", Role: RoleAdmin},
+	{Path: "/api/audit/export", Role: RoleAdmin},
+
+	// Credentials and remote configuration, which decide where code is pushed
+	{Path: "/api/credentials", Role: RoleAdmin},
+	{Path: "/api/credentials/{remote}", Role: RoleAdmin},
+	{Path: "/api/remotes", Methods: []string{"POST"}, Role: RoleAdmin},
+	{Path: "/api/remote/{remote}", Methods: []string{"DELETE"}, Role: RoleAdmin},
+	{Path: "/api/remote/{remote}/rename", Role: RoleAdmin},
+	{Path: "/api/remote/{remote}/url", Role: RoleAdmin},
+	{Path: "/api/remote/{remote}/refspecs", Methods: []string{"POST", "PUT", "DELETE"}, Role: RoleAdmin},
+
+	// Repository policy
+	{Path: "/api/protection/config", Methods: []string{"POST", "PUT", "DELETE"}, Role: RoleAdmin},
+	{Path: "/api/signature/config", Methods: []string{"POST", "PUT", "DELETE"}, Role: RoleAdmin},
+	{Path: "/api/commit/lint/config", Methods: []string{"POST", "PUT", "DELETE"}, Role: RoleAdmin},
+
+	// Running shell commands on the server
+	{Path: "/api/jobs/bisect-run", Role: RoleAdmin},
+
+	// Operations that throw work away
+	{Path: "/api/branch/reset", Role: RoleAdmin},
+	{Path: "/api/clean", Role: RoleAdmin},
+	{Path: "/api/branches/cleanup", Methods: []string{"POST"}, Role: RoleAdmin},
+
+	// Reads sent as POST
+	{Path: "/api/search", Role: RoleViewer},
+	{Path: "/api/commit/lint", Role: RoleViewer},
+	{Path: "/api/ai/", Role: RoleViewer},
+	{Path: "/api/ades/", Role: RoleViewer},
+}
+
+// isRead reports whether a method only reads
+func isRead(method string) bool {
+	return method == "GET" || method == "HEAD" || method == "OPTIONS"
+}
+
+// RequiredRole returns the minimum role for a request with method to a route with the
+// path template
+func RequiredRole(rules []Rule, method, template string) Role {
+	for _, rule := range rules {
+		if rule.Path != template && !(strings.HasSuffix(rule.Path, "/") && strings.HasPrefix(template, rule.Path)) {
+			continue
+		}
+		if len(rule.Methods) > 0 && !containsMethod(rule.Methods, method) {
+			continue
+		}
+		return rule.Role
+	}
+	if isRead(method) {
+		return RoleViewer
+	}
+	return RoleCommitter
+}
+
+func containsMethod(methods []string, method string) bool {
+	for _, m := range methods {
+		if strings.EqualFold(m, method) {
+			return true
+		}
+	}
+	return false
+}
+
+// Authorize is mux middleware that rejects requests whose principal lacks the role the
+// matched route requires, with 403
+func Authorize(rules []Rule) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			principal := FromContext(r.Context())
+			if principal == nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+			template := r.URL.Path
+			if route := mux.CurrentRoute(r); route != nil {
+				if t, err := route.GetPathTemplate(); err == nil {
+					template = t
+				}
+			}
+			required := RequiredRole(rules, r.Method, template)
+			if !principal.Role.Includes(required) {
+				writeError(w, http.StatusForbidden, "requires the "+string(required)+" role", "forbidden")
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// RouteRole is the minimum role of one method of a route
+type RouteRole struct {
+	Path   string `json:"path"`
+	Method string `json:"method"`
+	Role   Role   `json:"role"`
+}
+
+// Routes lists the minimum role of every route of router. Routes that accept any method
+// are listed for GET and POST.
+func Routes(router *mux.Router, rules []Rule) []RouteRole {
+	roles := make([]RouteRole, 0)
+	seen := make(map[string]bool)
+	router.Walk(func(route *mux.Route, _ *mux.Router, _ []*mux.Route) error {
+		template, err := route.GetPathTemplate()
+		if err != nil {
+			return nil
+		}
+		methods, err := route.GetMethods()
+		if err != nil || len(methods) == 0 {
+			methods = []string{"GET", "POST"}
+		}
+		for _, method := range methods {
+			if key := method + " " + template; !seen[key] {
+				seen[key] = true
+				roles = append(roles, RouteRole{Path: template, Method: method, Role: RequiredRole(rules, method, template)})
+			}
+		}
+		return nil
+	})
+	sort.Slice(roles, func(i, j int) bool {
+		if roles[i].Path != roles[j].Path {
+			return roles[i].Path < roles[j].Path
+		}
+		return roles[i].Method < roles[j].Method
+	})
+	return roles
+}
+
+// HandleMe handles GET /api/auth/me - the caller's name and role
+func HandleMe(w http.ResponseWriter, r *http.Request) {
+	principal := FromContext(r.Context())
+	if principal == nil {
+		// Authentication is off, so everyone has full access
+		principal = &Principal{Name: "anonymous", Role: RoleAdmin, Method: "none"}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(principal)
+}
+
+// HandleRoutes handles GET /api/auth/routes - the minimum role of every route
+func HandleRoutes(router *mux.Router, rules []Rule) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(Routes(router, rules))
+	}
+}
@@ -0,0 +1,134 @@
+package git
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// ArchiveFormats lists the archive formats supported by WriteArchive
+var ArchiveFormats = []string{"tar.gz", "zip"}
+
+// ResolveExportRef resolves a branch, tag or commit for export and returns its commit hash
+func (s *Service) ResolveExportRef(ref string) (string, error) {
+	if ref == "" {
+		return "", fmt.Errorf("ref cannot be empty")
+	}
+	if err := ValidateRevision(ref); err != nil {
+		return "", err
+	}
+	hash, err := s.runGitCommand("rev-parse", "--verify", "--quiet", ref+"^{commit}")
+	if err != nil || hash == "" {
+		return "", fmt.Errorf("ref not found: %s", ref)
+	}
+	return hash, nil
+}
+
+// WriteBundle streams a git bundle containing the history of ref to w.
+// Commits that are not pointed to by a ref are exported through a temporary ref,
+// since git bundle only records named refs.
+func (s *Service) WriteBundle(w io.Writer, ref string) error {
+	hash, err := s.ResolveExportRef(ref)
+	if err != nil {
+		return err
+	}
+
+	fullName, _ := s.runGitCommand("rev-parse", "--symbolic-full-name", ref)
+	if fullName == "" {
+		fullName = "refs/gait/export/" + hash
+		if _, err := s.runGitCommand("update-ref", fullName, hash); err != nil {
+			return fmt.Errorf("failed to create export ref: %w", err)
+		}
+		defer s.runGitCommand("update-ref", "-d", fullName)
+	}
+
+	return s.runGitCommandToWriter(w, "bundle", "create", "-", fullName)
+}
+
+// WriteArchive streams a tar.gz or zip archive of ref to w, optionally limited to paths
+func (s *Service) WriteArchive(w io.Writer, ref string, format string, prefix string, paths []string) error {
+	if _, err := s.ResolveExportRef(ref); err != nil {
+		return err
+	}
+
+	supported := false
+	for _, f := range ArchiveFormats {
+		if f == format {
+			supported = true
+			break
+		}
+	}
+	if !supported {
+		return fmt.Errorf("unsupported archive format: %s", format)
+	}
+
+	args := []string{"archive", "--format=" + format}
+	if prefix != "" {
+		// A prefix that climbs out would unpack outside the archive's directory
+		cleaned, err := CleanPath(prefix)
+		if err != nil {
+			return err
+		}
+		args = append(args, "--prefix="+cleaned+"/")
+	}
+	args = append(args, ref)
+	if len(paths) > 0 {
+		cleaned, err := CleanPaths(paths)
+		if err != nil {
+			return err
+		}
+		args = append(args, "--")
+		args = append(args, cleaned...)
+	}
+
+	return s.runGitCommandToWriter(w, args...)
+}
+
+// VerifyBundle checks that a bundle file is valid and its prerequisites exist in the repository
+func (s *Service) VerifyBundle(bundlePath string) (string, error) {
+	output, err := s.runGitCommand("bundle", "verify", bundlePath)
+	if err != nil {
+		return "", fmt.Errorf("bundle verification failed: %w", err)
+	}
+	return output, nil
+}
+
+// FetchFromBundle verifies a bundle and fetches its heads and tags into the repository.
+// Branch heads land under refs/remotes/<remoteName>/ so local branches are never overwritten.
+func (s *Service) FetchFromBundle(bundlePath string, remoteName string) ([]string, error) {
+	if remoteName == "" {
+		remoteName = "bundle"
+	}
+	if err := ValidateRemoteName(remoteName); err != nil {
+		return nil, err
+	}
+
+	if _, err := s.VerifyBundle(bundlePath); err != nil {
+		return nil, err
+	}
+
+	output, err := s.runGitCommand("bundle", "list-heads", bundlePath)
+	if err != nil {
+		return nil, err
+	}
+
+	heads := make([]string, 0)
+	for _, line := range strings.Split(output, "\n") {
+		parts := strings.Fields(line)
+		if len(parts) == 2 {
+			heads = append(heads, parts[1])
+		}
+	}
+
+	_, err = s.runGitCommand("fetch", bundlePath,
+		"+refs/heads/*:refs/remotes/"+remoteName+"/*",
+		"+refs/gait/export/*:refs/remotes/"+remoteName+"/export/*",
+		"refs/tags/*:refs/tags/*")
+	if err != nil {
+		return nil, err
+	}
+
+	s.invalidateBranchesCache()
+	s.invalidateTagsCache()
+	return heads, nil
+}
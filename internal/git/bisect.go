@@ -0,0 +1,255 @@
+package git
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/knoxai/gait/pkg/types"
+)
+
+var (
+	bisectVarRegex      = regexp.MustCompile(`^bisect_(\w+)=(\S+)$`)
+	bisectFirstBadRegex = regexp.MustCompile(`(?m)^# first bad commit: \[([0-9a-f]+)\]`)
+	bisectFoundRegex    = regexp.MustCompile(`(?m)^([0-9a-f]{40}) is the first bad commit`)
+)
+
+// Bisect terms accepted by BisectMark
+const (
+	BisectGood = "good"
+	BisectBad  = "bad"
+	BisectSkip = "skip"
+)
+
+// GetBisectStatus returns the state of the current bisect session
+func (s *Service) GetBisectStatus() (*types.BisectStatus, error) {
+	status := &types.BisectStatus{Good: []string{}, Skipped: []string{}}
+	if !s.gitPathExists("BISECT_START") {
+		return status, nil
+	}
+	status.InProgress = true
+
+	output, err := s.runGitCommand("for-each-ref", "--format=%(refname)|%(objectname)", "refs/bisect")
+	if err != nil {
+		return nil, err
+	}
+	for _, line := range strings.Split(output, "\n") {
+		parts := strings.Split(line, "|")
+		if len(parts) != 2 {
+			continue
+		}
+		switch {
+		case parts[0] == "refs/bisect/bad":
+			status.Bad = parts[1]
+		case strings.HasPrefix(parts[0], "refs/bisect/good-"):
+			status.Good = append(status.Good, parts[1])
+		case strings.HasPrefix(parts[0], "refs/bisect/skip-"):
+			status.Skipped = append(status.Skipped, parts[1])
+		}
+	}
+
+	if head, err := s.runGitCommand("log", "-1", "--format=%H|%s", "HEAD"); err == nil {
+		parts := strings.SplitN(head, "|", 2)
+		status.Current = parts[0]
+		if len(parts) == 2 {
+			status.CurrentSubject = parts[1]
+		}
+	}
+
+	if log, err := s.runGitCommand("bisect", "log"); err == nil {
+		if matches := bisectFirstBadRegex.FindStringSubmatch(log); len(matches) == 2 {
+			status.FirstBad = matches[1]
+		}
+	}
+
+	if status.Bad != "" && len(status.Good) > 0 && status.FirstBad == "" {
+		args := []string{"rev-list", "--bisect-vars", status.Bad, "--not"}
+		if output, err := s.runGitCommand(append(args, status.Good...)...); err == nil {
+			for _, line := range strings.Split(output, "\n") {
+				matches := bisectVarRegex.FindStringSubmatch(strings.TrimSpace(line))
+				if len(matches) != 3 {
+					continue
+				}
+				value, _ := strconv.Atoi(strings.Trim(matches[2], "'"))
+				switch matches[1] {
+				case "all":
+					status.Remaining = value
+				case "steps":
+					status.Steps = value
+				}
+			}
+		}
+	}
+
+	return status, nil
+}
+
+// BisectStart starts a bisect session between a bad commit and one or more good commits
+// and checks out the first commit to test
+func (s *Service) BisectStart(bad string, good []string) (*types.BisectStatus, error) {
+	if s.gitPathExists("BISECT_START") {
+		return nil, fmt.Errorf("a bisect is already in progress; reset it first")
+	}
+	if bad == "" {
+		bad = "HEAD"
+	}
+	if len(good) == 0 {
+		return nil, fmt.Errorf("at least one good commit is required")
+	}
+	for _, ref := range append([]string{bad}, good...) {
+		if err := ValidateRevision(ref); err != nil {
+			return nil, err
+		}
+		if _, err := s.runGitCommand("rev-parse", "--verify", "--quiet", ref+"^{commit}"); err != nil {
+			return nil, fmt.Errorf("commit not found: %s", ref)
+		}
+	}
+
+	args := append([]string{"bisect", "start", bad}, good...)
+	output, err := s.runGitCommand(append(args, "--")...)
+	s.invalidateBranchesCache()
+	if err != nil {
+		s.runGitCommand("bisect", "reset")
+		return nil, err
+	}
+	return s.bisectResult(output)
+}
+
+// BisectMark marks a commit, or the commit currently checked out when commit is empty,
+// as good, bad or skipped and checks out the next commit to test
+func (s *Service) BisectMark(term string, commit string) (*types.BisectStatus, error) {
+	if term != BisectGood && term != BisectBad && term != BisectSkip {
+		return nil, fmt.Errorf("invalid bisect term %q: expected good, bad or skip", term)
+	}
+	if !s.gitPathExists("BISECT_START") {
+		return nil, fmt.Errorf("no bisect in progress")
+	}
+
+	args := []string{"bisect", term}
+	if commit != "" {
+		if err := ValidateRevision(commit); err != nil {
+			return nil, err
+		}
+		args = append(args, commit)
+	}
+	output, err := s.runGitCommand(args...)
+	if err != nil {
+		return nil, err
+	}
+	return s.bisectResult(output)
+}
+
+// BisectReset ends the bisect session and returns to the branch it started from
+func (s *Service) BisectReset() error {
+	if !s.gitPathExists("BISECT_START") {
+		return fmt.Errorf("no bisect in progress")
+	}
+	_, err := s.runGitCommand("bisect", "reset")
+	s.invalidateBranchesCache()
+	return err
+}
+
+// GetBisectLog returns the bisect log of the current session
+func (s *Service) GetBisectLog() (string, error) {
+	if !s.gitPathExists("BISECT_START") {
+		return "", fmt.Errorf("no bisect in progress")
+	}
+	return s.runGitCommand("bisect", "log")
+}
+
+// BisectRun runs command in the working tree at each bisect step until the first bad
+// commit is found. Exit code 0 marks a commit good, 125 skips it and any other code
+// up to 127 marks it bad. Each line of output is reported through progress as it
+// is produced.
+func (s *Service) BisectRun(ctx context.Context, command string, progress ProgressFunc) (*types.BisectStatus, error) {
+	if strings.TrimSpace(command) == "" {
+		return nil, fmt.Errorf("test command cannot be empty")
+	}
+	s = s.WithContext(ctx)
+	status, err := s.GetBisectStatus()
+	if err != nil {
+		return nil, err
+	}
+	if !status.InProgress || status.Bad == "" || len(status.Good) == 0 {
+		return nil, fmt.Errorf("start a bisect with good and bad commits first")
+	}
+
+	args := []string{"bisect", "run", "sh", "-c", command}
+	cmd, runCtx, cancel := s.command(0, []string{"GIT_TERMINAL_PROMPT=0"}, args...)
+	defer cancel()
+
+	reader, writer := io.Pipe()
+	cmd.Stdout = writer
+	cmd.Stderr = writer
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+	waitErr := make(chan error, 1)
+	go func() {
+		err := cmd.Wait()
+		writer.Close()
+		waitErr <- err
+	}()
+
+	var output strings.Builder
+	lineNumber := int64(0)
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		output.WriteString(line)
+		output.WriteString("\n")
+		lineNumber++
+		if progress != nil {
+			progress(types.JobProgress{Phase: "bisect run", Current: lineNumber, Message: line})
+		}
+	}
+	io.Copy(io.Discard, reader)
+
+	err = <-waitErr
+	s.invalidateBranchesCache()
+	if runCtx.Err() != nil {
+		return nil, commandError(runCtx, args, err, lastLines(output.String(), 20))
+	}
+
+	result, statusErr := s.bisectResult(output.String())
+	if statusErr != nil {
+		return nil, statusErr
+	}
+	if err != nil && result.FirstBad == "" {
+		return nil, fmt.Errorf("bisect run failed: %v, output: %s", err, strings.TrimSpace(lastLines(output.String(), 20)))
+	}
+	return result, nil
+}
+
+// bisectResult returns the bisect status after a bisect command produced output
+func (s *Service) bisectResult(output string) (*types.BisectStatus, error) {
+	status, err := s.GetBisectStatus()
+	if err != nil {
+		return nil, err
+	}
+	status.Output = strings.TrimSpace(output)
+	if status.FirstBad == "" {
+		if matches := bisectFoundRegex.FindStringSubmatch(output); len(matches) == 2 {
+			status.FirstBad = matches[1]
+		}
+	}
+	if status.FirstBad != "" {
+		status.Remaining = 0
+		status.Steps = 0
+	}
+	return status, nil
+}
+
+// lastLines returns the last n lines of output
+func lastLines(output string, n int) string {
+	lines := strings.Split(strings.TrimRight(output, "\n"), "\n")
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return strings.Join(lines, "\n")
+}
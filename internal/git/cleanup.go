@@ -0,0 +1,280 @@
+package git
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/knoxai/gait/pkg/types"
+)
+
+// Cleanup reasons reported by AnalyzeBranches
+const (
+	CleanupMerged       = "merged"
+	CleanupSquashMerged = "squash-merged"
+	CleanupStale        = "stale"
+	CleanupUpstreamGone = "upstream-gone"
+	CleanupOrphaned     = "orphaned"
+)
+
+// DefaultStaleDays is the last-commit age after which a branch counts as stale
+const DefaultStaleDays = 90
+
+// AnalyzeBranches classifies local and remote-tracking branches as cleanup candidates
+// relative to base: merged, squash-merged (every change already in base by patch-id),
+// stale by last-commit age, local branches whose upstream is gone, and remote-tracking
+// branches that no longer exist on their remote. Only branches with a reason are returned.
+func (s *Service) AnalyzeBranches(base string, staleDays int, includeRemotes bool) (*types.BranchCleanupReport, error) {
+	current, _ := s.runGitCommand("rev-parse", "--abbrev-ref", "HEAD")
+	if base == "" {
+		base = current
+	}
+	if base == "" || base == "HEAD" {
+		return nil, fmt.Errorf("base branch is required when HEAD is detached")
+	}
+	if err := ValidateRevision(base); err != nil {
+		return nil, err
+	}
+	if _, err := s.runGitCommand("rev-parse", "--verify", "--quiet", base+"^{commit}"); err != nil {
+		return nil, fmt.Errorf("base branch not found: %s", base)
+	}
+	if staleDays <= 0 {
+		staleDays = DefaultStaleDays
+	}
+
+	report := &types.BranchCleanupReport{
+		Base:      base,
+		StaleDays: staleDays,
+		Branches:  make([]types.BranchCleanupCandidate, 0),
+	}
+	staleBefore := time.Now().AddDate(0, 0, -staleDays)
+
+	// Never offer the base, the checked out branch or the base's upstream for deletion
+	protected := map[string]bool{base: true, current: true}
+	if upstream, err := s.runGitCommand("rev-parse", "--abbrev-ref", base+"@{upstream}"); err == nil {
+		protected[upstream] = true
+	}
+
+	refs := "refs/heads"
+	if includeRemotes {
+		refs = "refs/heads refs/remotes"
+	}
+	merged := s.listMergedRefs(base, strings.Fields(refs))
+
+	orphaned := make(map[string]bool)
+	if includeRemotes {
+		orphaned = s.listOrphanedRemoteRefs()
+	}
+
+	args := append([]string{"for-each-ref",
+		"--format=%(refname)|%(refname:short)|%(objectname:short)|%(committerdate:unix)|%(upstream:short)|%(upstream:track,nobracket)|%(symref)"},
+		strings.Fields(refs)...)
+	output, err := s.runGitCommand(args...)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, line := range strings.Split(output, "\n") {
+		parts := strings.Split(line, "|")
+		if len(parts) < 7 || parts[6] != "" {
+			continue // skip symbolic refs such as origin/HEAD
+		}
+		fullName, shortName := parts[0], parts[1]
+		if protected[shortName] {
+			continue
+		}
+		branchName := shortName
+		if strings.HasPrefix(fullName, "refs/remotes/") {
+			_, branchName = splitRemoteBranch(shortName)
+		}
+		if _, isProtected := s.protectedPattern(branchName); isProtected {
+			continue
+		}
+
+		candidate := types.BranchCleanupCandidate{
+			Name:     shortName,
+			Hash:     parts[2],
+			IsRemote: strings.HasPrefix(fullName, "refs/remotes/"),
+			Upstream: parts[4],
+			Reasons:  make([]string, 0),
+		}
+		if candidate.IsRemote {
+			candidate.Remote, candidate.Name = splitRemoteBranch(shortName)
+		}
+		if unix, err := strconv.ParseInt(parts[3], 10, 64); err == nil {
+			candidate.LastCommitDate = time.Unix(unix, 0)
+		}
+
+		switch {
+		case merged[fullName]:
+			candidate.Reasons = append(candidate.Reasons, CleanupMerged)
+		case s.isSquashMerged(base, fullName):
+			candidate.Reasons = append(candidate.Reasons, CleanupSquashMerged)
+		}
+		if candidate.LastCommitDate.Before(staleBefore) {
+			candidate.Reasons = append(candidate.Reasons, CleanupStale)
+		}
+		if _, _, gone := parseUpstreamTrack(parts[5]); gone {
+			candidate.Reasons = append(candidate.Reasons, CleanupUpstreamGone)
+		}
+		if orphaned[shortName] {
+			candidate.Reasons = append(candidate.Reasons, CleanupOrphaned)
+		}
+
+		if len(candidate.Reasons) > 0 {
+			report.Branches = append(report.Branches, candidate)
+		}
+	}
+
+	sort.SliceStable(report.Branches, func(i, j int) bool {
+		return report.Branches[i].LastCommitDate.Before(report.Branches[j].LastCommitDate)
+	})
+	return report, nil
+}
+
+// listMergedRefs returns the full names of refs already reachable from base
+func (s *Service) listMergedRefs(base string, patterns []string) map[string]bool {
+	merged := make(map[string]bool)
+	args := append([]string{"for-each-ref", "--merged", base, "--format=%(refname)"}, patterns...)
+	output, err := s.runGitCommand(args...)
+	if err != nil {
+		return merged
+	}
+	for _, line := range strings.Split(output, "\n") {
+		if line != "" {
+			merged[line] = true
+		}
+	}
+	return merged
+}
+
+// listOrphanedRemoteRefs asks every remote which of its remote-tracking branches no
+// longer exist upstream. Remotes that cannot be reached are skipped.
+func (s *Service) listOrphanedRemoteRefs() map[string]bool {
+	orphaned := make(map[string]bool)
+	remotes, err := s.runGitCommand("remote")
+	if err != nil {
+		return orphaned
+	}
+	for _, remote := range strings.Fields(remotes) {
+		output, err := s.runGitCommandWithTimeoutEnv(30*time.Second, s.remoteAuthEnv(remote), "remote", "prune", "--dry-run", remote)
+		if err != nil {
+			continue
+		}
+		for _, line := range strings.Split(output, "\n") {
+			line = strings.TrimSpace(line)
+			if strings.HasPrefix(line, "* [would prune] ") {
+				orphaned[strings.TrimPrefix(line, "* [would prune] ")] = true
+			}
+		}
+	}
+	return orphaned
+}
+
+// isSquashMerged reports whether the combined changes of ref since it forked from
+// base already landed in base, e.g. through a squash merge. The branch is collapsed
+// into a single throwaway commit and compared by patch-id with git cherry.
+func (s *Service) isSquashMerged(base string, ref string) bool {
+	mergeBase, err := s.runGitCommand("merge-base", base, ref)
+	if err != nil || mergeBase == "" {
+		return false
+	}
+	tree, err := s.runGitCommand("rev-parse", ref+"^{tree}")
+	if err != nil {
+		return false
+	}
+	if baseTree, _ := s.runGitCommand("rev-parse", mergeBase+"^{tree}"); baseTree == tree {
+		return false // no changes at all; nothing to compare
+	}
+	squashed, err := s.runGitCommand("commit-tree", tree, "-p", mergeBase, "-m", "squash")
+	if err != nil {
+		return false
+	}
+	output, err := s.runGitCommand("cherry", base, squashed)
+	return err == nil && strings.HasPrefix(output, "-")
+}
+
+// splitRemoteBranch splits "origin/feature/x" into its remote and branch name
+func splitRemoteBranch(shortName string) (string, string) {
+	if idx := strings.Index(shortName, "/"); idx > 0 {
+		return shortName[:idx], shortName[idx+1:]
+	}
+	return "", shortName
+}
+
+// DeleteBranches deletes local branches, or remote branches when Remote is set.
+// With dryRun nothing is changed and each result describes the command that would run.
+// Remote branches that are already gone upstream have their remote-tracking ref removed.
+// Protected branches are never deleted.
+func (s *Service) DeleteBranches(branches []types.BranchRef, force bool, dryRun bool) []types.BranchDeleteResult {
+	current, _ := s.runGitCommand("rev-parse", "--abbrev-ref", "HEAD")
+	results := make([]types.BranchDeleteResult, 0, len(branches))
+
+	for _, branch := range branches {
+		result := types.BranchDeleteResult{Name: branch.Name, Remote: branch.Remote, DryRun: dryRun}
+
+		// A dry run only previews the block; real attempts are logged
+		var protectedErr error
+		if pattern, ok := s.protectedPattern(branch.Name); ok && branch.Name != "" {
+			protectedErr = &ProtectedBranchError{Operation: OperationDelete, Branch: branch.Name, Pattern: pattern}
+			if !dryRun {
+				protectedErr = s.checkProtected(OperationDelete, branch.Name, branch.Remote)
+			}
+		}
+
+		inputErr := ValidateRefName(branch.Name)
+		if inputErr == nil && branch.Remote != "" {
+			inputErr = ValidateRemoteName(branch.Remote)
+		}
+
+		var args []string
+		switch {
+		case branch.Name == "":
+			result.Error = "branch name cannot be empty"
+		case inputErr != nil:
+			result.Error = inputErr.Error()
+		case branch.Remote == "" && branch.Name == current:
+			result.Error = "cannot delete the checked out branch"
+		case protectedErr != nil:
+			result.Error = protectedErr.Error()
+		case branch.Remote == "" && force:
+			args = []string{"branch", "-D", "--", branch.Name}
+		case branch.Remote == "":
+			args = []string{"branch", "-d", "--", branch.Name}
+		default:
+			args = []string{"push", "--delete", "--", branch.Remote, branch.Name}
+		}
+
+		if args != nil {
+			result.Command = "git " + strings.Join(args, " ")
+			if !dryRun {
+				var output string
+				var err error
+				if branch.Remote != "" {
+					output, err = s.runRemoteCommand(branch.Remote, args...)
+				} else {
+					output, err = s.runGitCommand(args...)
+				}
+				if err != nil && branch.Remote != "" && strings.Contains(err.Error(), "remote ref does not exist") {
+					// Already gone on the remote; drop the stale remote-tracking ref instead
+					output, err = s.runGitCommand("branch", "-r", "-d", "--", branch.Remote+"/"+branch.Name)
+				}
+				if err != nil {
+					result.Error = err.Error()
+				} else {
+					result.Deleted = true
+					result.Output = output
+				}
+			}
+		}
+
+		results = append(results, result)
+	}
+
+	if !dryRun {
+		s.invalidateBranchesCache()
+	}
+	return results
+}
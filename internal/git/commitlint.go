@@ -0,0 +1,254 @@
+package git
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/knoxai/gait/pkg/types"
+)
+
+// commitLintConfigFile is the per-repository lint policy, relative to the repository root
+const commitLintConfigFile = ".gait/commit-lint.json"
+
+var trailerLineRegex = regexp.MustCompile(`^([A-Za-z0-9][A-Za-z0-9-]*): (.+)$`)
+
+// DefaultConventionalTypes are the commit types allowed when none are configured
+var DefaultConventionalTypes = []string{"feat", "fix", "docs", "style", "refactor", "perf", "test", "build", "ci", "chore", "revert"}
+
+// CommitLintConfig holds the commit message policy for a repository
+type CommitLintConfig struct {
+	Enabled          bool     `json:"enabled"`
+	Conventional     bool     `json:"conventional"`
+	Types            []string `json:"types,omitempty"`
+	RequireScope     bool     `json:"requireScope"`
+	MaxSubjectLength int      `json:"maxSubjectLength"`
+	BodyWrap         int      `json:"bodyWrap"`
+	RequiredTrailers []string `json:"requiredTrailers,omitempty"`
+	IssuePattern     string   `json:"issuePattern,omitempty"`
+	AllowOverride    bool     `json:"allowOverride"`
+}
+
+// DefaultCommitLintConfig returns the policy used when a repository has no lint config
+func DefaultCommitLintConfig() *CommitLintConfig {
+	return &CommitLintConfig{
+		Enabled:          false,
+		Types:            DefaultConventionalTypes,
+		MaxSubjectLength: 72,
+		BodyWrap:         72,
+	}
+}
+
+// CommitLintError is returned when a commit message violates the lint policy
+type CommitLintError struct {
+	Violations  []types.CommitLintViolation
+	Overridable bool
+}
+
+// Error implements the error interface
+func (e *CommitLintError) Error() string {
+	messages := make([]string, len(e.Violations))
+	for i, v := range e.Violations {
+		messages[i] = v.Message
+	}
+	return "commit message violates policy: " + strings.Join(messages, "; ")
+}
+
+// CommitOptions controls how CreateCommitWithOptions creates a commit
+type CommitOptions struct {
+	Amend    bool
+	Signoff  bool
+	SkipLint bool // only honoured when the lint config allows overrides
+}
+
+// LoadCommitLintConfig reads the repository's commit lint config, falling back to defaults
+func (s *Service) LoadCommitLintConfig() (*CommitLintConfig, error) {
+	config := DefaultCommitLintConfig()
+
+	data, err := os.ReadFile(filepath.Join(s.repoPath, commitLintConfigFile))
+	if os.IsNotExist(err) {
+		return config, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(data, config); err != nil {
+		return nil, fmt.Errorf("invalid commit lint config: %v", err)
+	}
+	if config.IssuePattern != "" {
+		if _, err := regexp.Compile(config.IssuePattern); err != nil {
+			return nil, fmt.Errorf("invalid issue pattern: %v", err)
+		}
+	}
+	return config, nil
+}
+
+// SaveCommitLintConfig writes the repository's commit lint config
+func (s *Service) SaveCommitLintConfig(config *CommitLintConfig) error {
+	if config.IssuePattern != "" {
+		if _, err := regexp.Compile(config.IssuePattern); err != nil {
+			return fmt.Errorf("invalid issue pattern: %v", err)
+		}
+	}
+
+	path := filepath.Join(s.repoPath, commitLintConfigFile)
+	if err := s.prepareGaitDir(); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(config, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// LintCommitMessage checks a commit message against the repository's lint config.
+// signoff indicates that git will add a Signed-off-by trailer itself.
+func (s *Service) LintCommitMessage(message string, signoff bool) ([]types.CommitLintViolation, error) {
+	config, err := s.LoadCommitLintConfig()
+	if err != nil {
+		return nil, err
+	}
+	if !config.Enabled {
+		return []types.CommitLintViolation{}, nil
+	}
+	return LintCommitMessage(config, message, signoff), nil
+}
+
+// LintCommitMessage checks a commit message against a lint config
+func LintCommitMessage(config *CommitLintConfig, message string, signoff bool) []types.CommitLintViolation {
+	violations := []types.CommitLintViolation{}
+	lines := strings.Split(strings.TrimRight(message, "\n"), "\n")
+	subject := strings.TrimSpace(lines[0])
+
+	if subject == "" {
+		return append(violations, types.CommitLintViolation{
+			Rule:    "subject-empty",
+			Message: "Subject line cannot be empty",
+			Line:    1,
+		})
+	}
+
+	if config.MaxSubjectLength > 0 && len([]rune(subject)) > config.MaxSubjectLength {
+		violations = append(violations, types.CommitLintViolation{
+			Rule:    "subject-max-length",
+			Message: fmt.Sprintf("Subject is %d characters, maximum is %d", len([]rune(subject)), config.MaxSubjectLength),
+			Line:    1,
+		})
+	}
+
+	if config.Conventional {
+		cc := ParseConventionalCommit(subject, "")
+		allowed := config.Types
+		if len(allowed) == 0 {
+			allowed = DefaultConventionalTypes
+		}
+
+		switch {
+		case !cc.Valid:
+			violations = append(violations, types.CommitLintViolation{
+				Rule:    "conventional-format",
+				Message: "Subject must follow the conventional-commit format: type(scope): description",
+				Line:    1,
+			})
+		default:
+			typeAllowed := false
+			for _, t := range allowed {
+				if t == cc.Type {
+					typeAllowed = true
+					break
+				}
+			}
+			if !typeAllowed {
+				violations = append(violations, types.CommitLintViolation{
+					Rule:    "conventional-type",
+					Message: fmt.Sprintf("Type %q is not allowed, use one of: %s", cc.Type, strings.Join(allowed, ", ")),
+					Line:    1,
+				})
+			}
+			if config.RequireScope && cc.Scope == "" {
+				violations = append(violations, types.CommitLintViolation{
+					Rule:    "conventional-scope",
+					Message: "A scope is required: type(scope): description",
+					Line:    1,
+				})
+			}
+		}
+	}
+
+	if len(lines) > 1 && strings.TrimSpace(lines[1]) != "" {
+		violations = append(violations, types.CommitLintViolation{
+			Rule:    "body-leading-blank",
+			Message: "Subject and body must be separated by a blank line",
+			Line:    2,
+		})
+	}
+
+	if config.BodyWrap > 0 {
+		for i := 1; i < len(lines); i++ {
+			// Long URLs cannot be wrapped
+			if strings.Contains(lines[i], "://") {
+				continue
+			}
+			if length := len([]rune(lines[i])); length > config.BodyWrap {
+				violations = append(violations, types.CommitLintViolation{
+					Rule:    "body-max-line-length",
+					Message: fmt.Sprintf("Body line is %d characters, wrap at %d", length, config.BodyWrap),
+					Line:    i + 1,
+				})
+			}
+		}
+	}
+
+	if len(config.RequiredTrailers) > 0 {
+		trailers := parseTrailers(lines[1:])
+		for _, required := range config.RequiredTrailers {
+			if signoff && strings.EqualFold(required, "Signed-off-by") {
+				continue
+			}
+			if _, ok := trailers[strings.ToLower(required)]; !ok {
+				violations = append(violations, types.CommitLintViolation{
+					Rule:    "trailer-required",
+					Message: fmt.Sprintf("Missing required trailer: %s", required),
+				})
+			}
+		}
+	}
+
+	if config.IssuePattern != "" {
+		if re, err := regexp.Compile(config.IssuePattern); err == nil && !re.MatchString(message) {
+			violations = append(violations, types.CommitLintViolation{
+				Rule:    "issue-reference",
+				Message: fmt.Sprintf("Message must reference an issue matching %s", config.IssuePattern),
+			})
+		}
+	}
+
+	return violations
+}
+
+// parseTrailers returns the trailer tokens (lowercased) found in the last paragraph of a message body
+func parseTrailers(bodyLines []string) map[string]string {
+	trailers := make(map[string]string)
+
+	end := len(bodyLines)
+	for end > 0 && strings.TrimSpace(bodyLines[end-1]) == "" {
+		end--
+	}
+	start := end
+	for start > 0 && strings.TrimSpace(bodyLines[start-1]) != "" {
+		start--
+	}
+
+	for _, line := range bodyLines[start:end] {
+		if matches := trailerLineRegex.FindStringSubmatch(strings.TrimSpace(line)); len(matches) == 3 {
+			trailers[strings.ToLower(matches[1])] = matches[2]
+		}
+	}
+	return trailers
+}
@@ -0,0 +1,81 @@
+package git
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	conventionalSubjectRegex = regexp.MustCompile(`^([a-zA-Z]+)(?:\(([^()]+)\))?(!)?: (.+)$`)
+	breakingFooterRegex      = regexp.MustCompile(`(?m)^BREAKING[ -]CHANGE: (.+)$`)
+)
+
+// ConventionalCommit holds the parts of a conventional-commit message
+type ConventionalCommit struct {
+	Type         string
+	Scope        string
+	Description  string
+	Breaking     bool
+	BreakingNote string
+	Valid        bool
+}
+
+// ParseConventionalCommit parses a commit subject and body following the
+// Conventional Commits specification. Non-conforming subjects are returned with
+// Valid set to false and the whole subject as the description.
+func ParseConventionalCommit(subject string, body string) ConventionalCommit {
+	cc := ConventionalCommit{Description: strings.TrimSpace(subject)}
+
+	if matches := conventionalSubjectRegex.FindStringSubmatch(cc.Description); len(matches) == 5 {
+		cc.Type = strings.ToLower(matches[1])
+		cc.Scope = matches[2]
+		cc.Breaking = matches[3] == "!"
+		cc.Description = matches[4]
+		cc.Valid = true
+	}
+
+	if matches := breakingFooterRegex.FindStringSubmatch(body); len(matches) == 2 {
+		cc.Breaking = true
+		cc.BreakingNote = strings.TrimSpace(matches[1])
+	}
+
+	return cc
+}
+
+// rangeCommit is a commit with its full body, as needed for changelog generation
+type rangeCommit struct {
+	Hash      string
+	ShortHash string
+	Subject   string
+	Body      string
+	Author    string
+}
+
+// getCommitsInRange lists the commits reachable by revisionRange, newest first
+func (s *Service) getCommitsInRange(revisionRange string) ([]rangeCommit, error) {
+	// Unit and record separators keep multi-line bodies intact
+	output, err := s.runGitCommand("log", "--no-merges", "--format=%H%x1f%h%x1f%s%x1f%an%x1f%b%x1e", revisionRange)
+	if err != nil {
+		return nil, err
+	}
+
+	commits := make([]rangeCommit, 0)
+	for _, record := range strings.Split(output, "\x1e") {
+		record = strings.TrimSpace(record)
+		if record == "" {
+			continue
+		}
+		parts := strings.SplitN(record, "\x1f", 5)
+		if len(parts) < 5 {
+			continue
+		}
+		commits = append(commits, rangeCommit{
+			Hash:      parts[0],
+			ShortHash: parts[1],
+			Subject:   parts[2],
+			Author:    parts[3],
+			Body:      parts[4],
+		})
+	}
+	return commits, nil
+}
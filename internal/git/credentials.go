@@ -0,0 +1,414 @@
+package git
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/knoxai/gait/pkg/types"
+)
+
+// credentialsFile holds the repository's encrypted remote credentials, relative to the repository root
+const credentialsFile = ".gait/credentials.enc"
+
+// CredentialKeyEnv names the environment variable holding the passphrase credentials are
+// encrypted with. Without it a random key is generated in the user's config directory.
+const CredentialKeyEnv = "GAIT_CREDENTIAL_KEY"
+
+// credentialsMu serializes read-modify-write cycles on credential files
+var credentialsMu sync.Mutex
+
+// AuthError is returned when a remote operation fails because credentials are missing or rejected
+type AuthError struct {
+	Remote string
+	Output string
+}
+
+func (e *AuthError) Error() string {
+	if e.Remote != "" {
+		return fmt.Sprintf("authentication failed for remote %s: %s", e.Remote, e.Output)
+	}
+	return fmt.Sprintf("authentication failed: %s", e.Output)
+}
+
+// ErrorCode identifies authentication failures to API clients
+func (e *AuthError) ErrorCode() string {
+	return "auth_failed"
+}
+
+// authFailurePatterns are lowercased fragments of git, HTTP and SSH output that mean
+// the remote needed credentials it did not get
+var authFailurePatterns = []string{
+	"authentication failed",
+	"could not read username",
+	"could not read password",
+	"terminal prompts disabled",
+	"permission denied (publickey",
+	"invalid username or password",
+	"http basic: access denied",
+	"the requested url returned error: 401",
+	"the requested url returned error: 403",
+}
+
+// classifyRemoteError turns a git failure caused by missing or rejected credentials into *AuthError
+func classifyRemoteError(remote string, err error) error {
+	if err == nil {
+		return nil
+	}
+	message := err.Error()
+	lower := strings.ToLower(message)
+	for _, pattern := range authFailurePatterns {
+		if strings.Contains(lower, pattern) {
+			if idx := strings.Index(message, "output: "); idx >= 0 {
+				message = message[idx+len("output: "):]
+			}
+			return &AuthError{Remote: remote, Output: strings.TrimSpace(message)}
+		}
+	}
+	return err
+}
+
+// LoadCredentials decrypts the repository's stored remote credentials, keyed by remote name
+func (s *Service) LoadCredentials() (map[string]types.RemoteCredential, error) {
+	credentials := make(map[string]types.RemoteCredential)
+
+	data, err := os.ReadFile(filepath.Join(s.repoPath, credentialsFile))
+	if os.IsNotExist(err) {
+		return credentials, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	plaintext, err := decryptCredentials(data)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(plaintext, &credentials); err != nil {
+		return nil, fmt.Errorf("invalid credentials file: %w", err)
+	}
+	return credentials, nil
+}
+
+// saveCredentials encrypts and writes the repository's remote credentials
+func (s *Service) saveCredentials(credentials map[string]types.RemoteCredential) error {
+	path := filepath.Join(s.repoPath, credentialsFile)
+	if len(credentials) == 0 {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+
+	plaintext, err := json.Marshal(credentials)
+	if err != nil {
+		return err
+	}
+	data, err := encryptCredentials(plaintext)
+	if err != nil {
+		return err
+	}
+	if err := s.prepareGaitDir(); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0600)
+}
+
+// SaveCredential stores or replaces the credential for a remote. An empty token keeps
+// the token already stored for that remote.
+func (s *Service) SaveCredential(credential types.RemoteCredential) error {
+	if credential.Remote == "" {
+		return fmt.Errorf("remote name cannot be empty")
+	}
+	if credential.SSHKeyPath != "" {
+		credential.SSHKeyPath = expandHome(credential.SSHKeyPath)
+		if _, err := os.Stat(credential.SSHKeyPath); err != nil {
+			return fmt.Errorf("SSH key not found: %s", credential.SSHKeyPath)
+		}
+	}
+
+	credentialsMu.Lock()
+	defer credentialsMu.Unlock()
+
+	credentials, err := s.LoadCredentials()
+	if err != nil {
+		return err
+	}
+	if credential.Token == "" {
+		credential.Token = credentials[credential.Remote].Token
+	}
+	credentials[credential.Remote] = credential
+	return s.saveCredentials(credentials)
+}
+
+// DeleteCredential removes the stored credential for a remote
+func (s *Service) DeleteCredential(remote string) error {
+	credentialsMu.Lock()
+	defer credentialsMu.Unlock()
+
+	credentials, err := s.LoadCredentials()
+	if err != nil {
+		return err
+	}
+	if _, ok := credentials[remote]; !ok {
+		return fmt.Errorf("no credential stored for remote %s", remote)
+	}
+	delete(credentials, remote)
+	return s.saveCredentials(credentials)
+}
+
+// renameCredential moves the stored credential of a remote, if any, to its new name
+func (s *Service) renameCredential(oldName string, newName string) error {
+	credentialsMu.Lock()
+	defer credentialsMu.Unlock()
+
+	credentials, err := s.LoadCredentials()
+	if err != nil {
+		return err
+	}
+	credential, ok := credentials[oldName]
+	if !ok {
+		return nil
+	}
+	credential.Remote = newName
+	credentials[newName] = credential
+	delete(credentials, oldName)
+	return s.saveCredentials(credentials)
+}
+
+// ListCredentials returns the stored credentials without their secrets
+func (s *Service) ListCredentials() ([]types.CredentialInfo, error) {
+	credentials, err := s.LoadCredentials()
+	if err != nil {
+		return nil, err
+	}
+
+	infos := make([]types.CredentialInfo, 0, len(credentials))
+	for _, credential := range credentials {
+		infos = append(infos, types.CredentialInfo{
+			Remote:     credential.Remote,
+			Username:   credential.Username,
+			HasToken:   credential.Token != "",
+			SSHKeyPath: credential.SSHKeyPath,
+		})
+	}
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].Remote < infos[j].Remote
+	})
+	return infos, nil
+}
+
+// defaultRemote returns the remote git uses when none is named: the current branch's, else origin
+func (s *Service) defaultRemote() string {
+	if branch, err := s.runGitCommand("symbolic-ref", "--short", "HEAD"); err == nil {
+		if remote, err := s.runGitCommand("config", "branch."+branch+".remote"); err == nil && remote != "" {
+			return remote
+		}
+	}
+	return "origin"
+}
+
+// remoteAuthEnv returns the environment for running git against remote (the default
+// remote when empty) with its stored credentials and without interactive prompts
+func (s *Service) remoteAuthEnv(remote string) []string {
+	if remote == "" {
+		remote = s.defaultRemote()
+	}
+
+	var credential *types.RemoteCredential
+	if credentials, err := s.LoadCredentials(); err == nil {
+		if c, ok := credentials[remote]; ok {
+			credential = &c
+		}
+	}
+
+	env := AuthEnv(credential)
+	if credential == nil || credential.SSHKeyPath == "" {
+		// Keep any configured SSH command, but never let ssh prompt for a passphrase
+		if sshCommand, _ := s.runGitCommand("config", "core.sshCommand"); sshCommand == "" && os.Getenv("GIT_SSH_COMMAND") == "" {
+			env = append(env, "GIT_SSH_COMMAND=ssh -o BatchMode=yes")
+		}
+	}
+	return env
+}
+
+// AuthEnv builds the environment that runs git non-interactively with credential, which
+// may be nil. HTTPS tokens are answered through GIT_ASKPASS, bypassing configured credential
+// helpers, and SSH keys are passed through GIT_SSH_COMMAND. Terminal prompts are always
+// disabled so missing credentials fail fast instead of hanging.
+func AuthEnv(credential *types.RemoteCredential) []string {
+	env := []string{"GIT_TERMINAL_PROMPT=0"}
+	if credential == nil {
+		return env
+	}
+
+	if credential.Token != "" {
+		if askpass, err := askpassScript(); err == nil {
+			username := credential.Username
+			if username == "" {
+				username = "git"
+			}
+			env = append(env,
+				"GIT_ASKPASS="+askpass,
+				"GAIT_ASKPASS_USERNAME="+username,
+				"GAIT_ASKPASS_PASSWORD="+credential.Token,
+				"GIT_CONFIG_COUNT=1",
+				"GIT_CONFIG_KEY_0=credential.helper",
+				"GIT_CONFIG_VALUE_0=",
+			)
+		}
+	}
+	if credential.SSHKeyPath != "" {
+		env = append(env, "GIT_SSH_COMMAND=ssh -i "+shellQuote(expandHome(credential.SSHKeyPath))+" -o IdentitiesOnly=yes -o BatchMode=yes")
+	}
+	return env
+}
+
+// runRemoteCommand runs a git command that talks to remote with its credentials injected,
+// returning *AuthError when authentication fails
+func (s *Service) runRemoteCommand(remote string, args ...string) (string, error) {
+	cmd, ctx, cancel := s.command(0, s.remoteAuthEnv(remote), args...)
+	defer cancel()
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return "", classifyRemoteError(remote, commandError(ctx, args, err, string(output)))
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+var (
+	askpassOnce sync.Once
+	askpassPath string
+	askpassErr  error
+)
+
+// askpassScript writes, once per process, the GIT_ASKPASS helper that answers git's username
+// and password prompts from the environment, so secrets are never written to disk in clear
+func askpassScript() (string, error) {
+	askpassOnce.Do(func() {
+		dir, err := os.MkdirTemp("", "gait-askpass-")
+		if err != nil {
+			askpassErr = err
+			return
+		}
+		askpassPath = filepath.Join(dir, "askpass.sh")
+		script := "#!/bin/sh\n" +
+			"case \"$1\" in\n" +
+			"  Username*) printf '%s\\n' \"$GAIT_ASKPASS_USERNAME\" ;;\n" +
+			"  *) printf '%s\\n' \"$GAIT_ASKPASS_PASSWORD\" ;;\n" +
+			"esac\n"
+		askpassErr = os.WriteFile(askpassPath, []byte(script), 0700)
+	})
+	return askpassPath, askpassErr
+}
+
+// credentialKey returns the AES-256 key credentials are encrypted with
+func credentialKey() ([]byte, error) {
+	if passphrase := os.Getenv(CredentialKeyEnv); passphrase != "" {
+		key := sha256.Sum256([]byte(passphrase))
+		return key[:], nil
+	}
+
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return nil, fmt.Errorf("cannot locate credential key: %v", err)
+	}
+	path := filepath.Join(configDir, "gait", "credential.key")
+
+	if key, err := os.ReadFile(path); err == nil {
+		if len(key) != 32 {
+			return nil, fmt.Errorf("invalid credential key: %s", path)
+		}
+		return key, nil
+	} else if !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, err
+	}
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if os.IsExist(err) {
+		// Another process created the key first
+		return credentialKey()
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	if _, err := file.Write(key); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// encryptCredentials seals plaintext with AES-GCM, prefixing the random nonce
+func encryptCredentials(plaintext []byte) ([]byte, error) {
+	gcm, err := credentialCipher()
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+	return gcm.Seal(nonce, nonce, plaintext, nil), nil
+}
+
+// decryptCredentials opens data produced by encryptCredentials
+func decryptCredentials(data []byte) ([]byte, error) {
+	gcm, err := credentialCipher()
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < gcm.NonceSize() {
+		return nil, errors.New("credentials file is corrupt")
+	}
+	plaintext, err := gcm.Open(nil, data[:gcm.NonceSize()], data[gcm.NonceSize():], nil)
+	if err != nil {
+		return nil, fmt.Errorf("cannot decrypt credentials (was %s changed?)", CredentialKeyEnv)
+	}
+	return plaintext, nil
+}
+
+// credentialCipher returns the AES-GCM cipher for the credential key
+func credentialCipher() (cipher.AEAD, error) {
+	key, err := credentialKey()
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// expandHome replaces a leading ~/ with the user's home directory
+func expandHome(path string) string {
+	if strings.HasPrefix(path, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, path[2:])
+		}
+	}
+	return path
+}
+
+// shellQuote quotes s for use as a single POSIX shell word
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
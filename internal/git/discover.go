@@ -0,0 +1,267 @@
+package git
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/knoxai/gait/pkg/types"
+)
+
+// Repository layouts recognised by ResolveRepository
+const (
+	LayoutWorkTree = "worktree" // a working tree with a .git directory
+	LayoutGitFile  = "gitfile"  // a working tree whose .git file points elsewhere: linked worktrees and submodules
+	LayoutBare     = "bare"     // a bare repository
+	LayoutGitDir   = "gitdir"   // a git directory kept apart from its working tree, named by core.worktree
+)
+
+// IgnoreFile is the file in a discovery root that lists directories to skip
+const IgnoreFile = ".gaitignore"
+
+// DiscoverOptions controls repository discovery
+type DiscoverOptions struct {
+	MaxDepth int      // directory levels below the root to search
+	Exclude  []string // patterns of directories to skip, in addition to the root's .gaitignore
+}
+
+// NewServiceWithGitDir creates a Git service for a working tree whose git directory is
+// kept elsewhere; every command is run with GIT_DIR and GIT_WORK_TREE set
+func NewServiceWithGitDir(workTree, gitDir string) *Service {
+	service := NewService(workTree)
+	service.gitDir = gitDir
+	return service
+}
+
+// environ returns the environment git commands run with: nil to inherit the process
+// environment, or the process environment plus env and the repository's GIT_DIR
+func (s *Service) environ(env ...string) []string {
+	if s.gitDir != "" {
+		env = append(env, "GIT_DIR="+s.gitDir, "GIT_WORK_TREE="+s.repoPath)
+	}
+	if len(env) == 0 {
+		return nil
+	}
+	return append(os.Environ(), env...)
+}
+
+// Exists reports whether the service's repository is still a Git repository
+func (s *Service) Exists() bool {
+	dir := s.repoPath
+	if s.gitDir != "" {
+		dir = s.gitDir
+	}
+	_, err := ResolveRepository(dir)
+	return err == nil
+}
+
+// ResolveRepository works out the layout of the repository at dir, which may be a
+// working tree with a .git directory or file, a bare repository, or a git directory
+// whose core.worktree names its working tree. The returned path is the one the
+// repository is managed by: the working tree, or the git directory of a bare repository.
+func ResolveRepository(dir string) (*types.DiscoveredRepository, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, fmt.Errorf("invalid path: %v", err)
+	}
+
+	repo := &types.DiscoveredRepository{Path: dir}
+	if info, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+		repo.Layout = LayoutWorkTree
+		if !info.IsDir() {
+			repo.Layout = LayoutGitFile
+		}
+	} else if isGitDir(dir) {
+		bare, _ := gitDirConfig(dir, "core.bare")
+		workTree, _ := gitDirConfig(dir, "core.worktree")
+		switch {
+		case workTree != "":
+			if !filepath.IsAbs(workTree) {
+				workTree = filepath.Join(dir, workTree)
+			}
+			repo.Path = filepath.Clean(workTree)
+			repo.GitDir = dir
+			repo.Layout = LayoutGitDir
+		case bare != "true" && filepath.Base(dir) == ".git":
+			// The .git directory of an ordinary working tree
+			return ResolveRepository(filepath.Dir(dir))
+		default:
+			repo.Layout = LayoutBare
+		}
+	} else {
+		return nil, fmt.Errorf("not a Git repository: %s", dir)
+	}
+
+	// Let git confirm the layout, which catches dangling .git files and broken git dirs
+	if _, err := NewServiceWithGitDir(repo.Path, repo.GitDir).runGitCommand("rev-parse", "--git-dir"); err != nil {
+		return nil, fmt.Errorf("not a Git repository: %s", dir)
+	}
+
+	repo.Name = filepath.Base(repo.Path)
+	if repo.Layout == LayoutBare {
+		repo.Name = strings.TrimSuffix(repo.Name, ".git")
+	}
+	return repo, nil
+}
+
+// isGitDir reports whether dir looks like a git directory
+func isGitDir(dir string) bool {
+	head, err := os.Stat(filepath.Join(dir, "HEAD"))
+	if err != nil || head.IsDir() {
+		return false
+	}
+	for _, name := range []string{"objects", "refs"} {
+		if info, err := os.Stat(filepath.Join(dir, name)); err != nil || !info.IsDir() {
+			return false
+		}
+	}
+	return true
+}
+
+// gitDirConfig reads a config value of the git directory dir
+func gitDirConfig(dir, key string) (string, error) {
+	args := []string{"--git-dir=" + dir, "config", "--get", key}
+	ctx, cancel := withTimeout(context.Background(), args, 0)
+	defer cancel()
+	output, err := exec.CommandContext(ctx, "git", args...).Output()
+	return strings.TrimSpace(string(output)), err
+}
+
+// DiscoverRepositories finds Git repositories of every layout under rootPath, skipping
+// directories excluded by the root's .gaitignore or opts.Exclude. Working trees are
+// searched for nested repositories such as submodules; git directories are not.
+func DiscoverRepositories(rootPath string, opts DiscoverOptions) ([]types.DiscoveredRepository, error) {
+	rootPath, err := filepath.Abs(rootPath)
+	if err != nil {
+		return nil, err
+	}
+	patterns, err := LoadIgnoreFile(filepath.Join(rootPath, IgnoreFile))
+	if err != nil {
+		return nil, err
+	}
+	ignore := newIgnoreMatcher(append(patterns, opts.Exclude...))
+
+	repos := make([]types.DiscoveredRepository, 0)
+	seen := make(map[string]bool)
+	err = filepath.WalkDir(rootPath, func(dir string, d fs.DirEntry, err error) error {
+		if err != nil || !d.IsDir() {
+			return nil // Continue walking
+		}
+		if d.Name() == ".git" {
+			return filepath.SkipDir
+		}
+
+		relPath, _ := filepath.Rel(rootPath, dir)
+		relPath = filepath.ToSlash(relPath)
+		depth := 0
+		if relPath != "." {
+			depth = strings.Count(relPath, "/") + 1
+			if ignore.match(relPath) {
+				return filepath.SkipDir
+			}
+		}
+		if depth > opts.MaxDepth {
+			return filepath.SkipDir
+		}
+
+		if _, err := os.Lstat(filepath.Join(dir, ".git")); err != nil && !isGitDir(dir) {
+			return nil
+		}
+		repo, err := ResolveRepository(dir)
+		if err != nil {
+			return nil
+		}
+		repo.Depth = depth
+		if !seen[repo.Path] {
+			seen[repo.Path] = true
+			repos = append(repos, *repo)
+		}
+		if repo.Layout == LayoutBare || repo.Layout == LayoutGitDir {
+			return filepath.SkipDir
+		}
+		return nil
+	})
+	return repos, err
+}
+
+// LoadIgnoreFile reads the patterns of a .gaitignore file; a missing file has none
+func LoadIgnoreFile(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	patterns := make([]string, 0)
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		patterns = append(patterns, scanner.Text())
+	}
+	return patterns, scanner.Err()
+}
+
+// ignoreMatcher matches directories against .gaitignore patterns, which follow
+// .gitignore for directories: blank lines and # comments are skipped, a trailing / is
+// allowed, a pattern containing a / (other than a leading **/) matches the path from
+// the root and any other pattern matches a directory name at any depth. A pattern
+// starting with ! re-includes directories an earlier pattern excluded.
+type ignoreMatcher struct {
+	patterns []ignorePattern
+}
+
+type ignorePattern struct {
+	glob     string
+	anchored bool
+	negate   bool
+}
+
+func newIgnoreMatcher(lines []string) *ignoreMatcher {
+	matcher := &ignoreMatcher{}
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		pattern := ignorePattern{}
+		if strings.HasPrefix(line, "!") {
+			pattern.negate = true
+			line = line[1:]
+		}
+		line = strings.TrimPrefix(strings.TrimSuffix(line, "/"), "**/")
+		if strings.Contains(line, "/") {
+			pattern.anchored = true
+			line = strings.TrimPrefix(line, "/")
+		}
+		if line == "" {
+			continue
+		}
+		pattern.glob = line
+		matcher.patterns = append(matcher.patterns, pattern)
+	}
+	return matcher
+}
+
+// match reports whether the directory at relPath, relative to the root and slash
+// separated, is excluded
+func (m *ignoreMatcher) match(relPath string) bool {
+	ignored := false
+	for _, pattern := range m.patterns {
+		subject := path.Base(relPath)
+		if pattern.anchored {
+			subject = relPath
+		}
+		if ok, _ := path.Match(pattern.glob, subject); ok {
+			ignored = !pattern.negate
+		}
+	}
+	return ignored
+}
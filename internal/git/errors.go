@@ -0,0 +1,133 @@
+package git
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// Kinds of git command failure, for use with errors.Is
+var (
+	ErrTimeout        = errors.New("git command timed out")
+	ErrCancelled      = errors.New("git command cancelled")
+	ErrNotARepository = errors.New("not a git repository")
+	ErrRefNotFound    = errors.New("ref not found")
+	ErrConflict       = errors.New("conflict")
+)
+
+// CommandError is returned when a git command fails, is cancelled or times out
+type CommandError struct {
+	Args     []string
+	Output   string // what git wrote to stderr, or all its output for combined commands
+	ExitCode int    // -1 when git did not exit on its own
+	Kind     error  // one of the Err kinds above, or nil when the failure is not classified
+	Err      error  // the underlying error from exec or the context
+}
+
+func (e *CommandError) Error() string {
+	switch e.Kind {
+	case ErrTimeout, ErrCancelled:
+		return fmt.Sprintf("%v: git %s", e.Kind, subcommand(e.Args))
+	}
+	return fmt.Sprintf("git command failed: %v, output: %s", e.Err, e.Output)
+}
+
+// Unwrap makes errors.Is match both the kind and the underlying error, such as
+// context.DeadlineExceeded for a timeout
+func (e *CommandError) Unwrap() []error {
+	unwrapped := make([]error, 0, 2)
+	for _, err := range []error{e.Kind, e.Err} {
+		if err != nil {
+			unwrapped = append(unwrapped, err)
+		}
+	}
+	return unwrapped
+}
+
+// ErrorCode identifies the kind of failure to API clients
+func (e *CommandError) ErrorCode() string {
+	switch e.Kind {
+	case ErrTimeout:
+		return "timeout"
+	case ErrCancelled:
+		return "cancelled"
+	case ErrNotARepository:
+		return "not_a_repository"
+	case ErrRefNotFound:
+		return "ref_not_found"
+	case ErrConflict:
+		return "conflict"
+	}
+	return "git_failed"
+}
+
+// Fragments of git's messages, lowercased, that identify a kind of failure
+var (
+	notARepositoryPatterns = []string{"not a git repository"}
+	refNotFoundPatterns    = []string{
+		"unknown revision", "bad revision", "invalid reference", "not a valid object name",
+		"not a valid ref", "needed a single revision", "couldn't find remote ref", "bad object",
+		"does not point to a commit", "not a tree object", "no such ref",
+	}
+	conflictPatterns = []string{
+		"conflict", "could not apply", "would be overwritten", "needs merge",
+		"resolve your current index first", "automatic merge failed", "unmerged files",
+		"does not apply", "patch failed",
+	}
+)
+
+// classifyOutput returns the kind of failure git's output describes, or nil
+func classifyOutput(output string) error {
+	lower := strings.ToLower(output)
+	for _, kind := range []struct {
+		err      error
+		patterns []string
+	}{
+		{ErrNotARepository, notARepositoryPatterns},
+		{ErrRefNotFound, refNotFoundPatterns},
+		{ErrConflict, conflictPatterns},
+	} {
+		for _, pattern := range kind.patterns {
+			if strings.Contains(lower, pattern) {
+				return kind.err
+			}
+		}
+	}
+	return nil
+}
+
+// commandError describes the failure of a git command run under ctx. A command killed
+// because ctx ended is a timeout or a cancellation whatever git printed.
+func commandError(ctx context.Context, args []string, err error, output string) error {
+	cmdErr := &CommandError{Args: args, Output: strings.TrimSpace(output), ExitCode: -1, Err: err}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		cmdErr.ExitCode = exitErr.ExitCode()
+	}
+	switch ctxErr := ctx.Err(); {
+	case errors.Is(ctxErr, context.DeadlineExceeded):
+		cmdErr.Kind, cmdErr.Err = ErrTimeout, ctxErr
+	case errors.Is(ctxErr, context.Canceled):
+		cmdErr.Kind, cmdErr.Err = ErrCancelled, ctxErr
+	default:
+		cmdErr.Kind = classifyOutput(cmdErr.Output)
+	}
+	return cmdErr
+}
+
+// subcommand returns the git subcommand among args, skipping global options such as
+// -c name=value
+func subcommand(args []string) string {
+	for i := 0; i < len(args); i++ {
+		switch {
+		case args[i] == "-c" || args[i] == "-C":
+			i++
+		case strings.HasPrefix(args[i], "-"):
+		default:
+			return args[i]
+		}
+	}
+	return ""
+}
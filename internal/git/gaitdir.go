@@ -0,0 +1,176 @@
+package git
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// gaitDir holds a repository's GAIT policy, credentials and logs, relative to the
+// repository root
+const gaitDir = ".gait"
+
+// gaitCleanPattern keeps the whole .gait directory out of git clean
+const gaitCleanPattern = "/" + gaitDir + "/"
+
+// gaitProtectedEntries are the entries of the .gait directory that hold credentials,
+// branch protection and signer trust. They are kept out of git, out of file requests
+// and out of revisions that would replace them. Other .gait files, such as the commit
+// lint config, may be committed and shared with a team.
+var gaitProtectedEntries = []string{
+	"credentials.enc",
+	"protection.json",
+	"protection.log",
+	"signatures.json",
+	"allowed_signers",
+	"gnupg",
+}
+
+// isProtectedGaitEntry reports whether name, an entry of the .gait directory, is one of
+// gaitProtectedEntries, ignoring case as case-insensitive file systems do
+func isProtectedGaitEntry(name string) bool {
+	for _, entry := range gaitProtectedEntries {
+		if strings.EqualFold(name, entry) {
+			return true
+		}
+	}
+	return false
+}
+
+// gaitExcludePatterns returns the info/exclude lines for the protected .gait entries
+func gaitExcludePatterns() []string {
+	patterns := make([]string, 0, len(gaitProtectedEntries))
+	for _, entry := range gaitProtectedEntries {
+		pattern := "/" + gaitDir + "/" + entry
+		if entry == "gnupg" {
+			pattern += "/"
+		}
+		patterns = append(patterns, pattern)
+	}
+	return patterns
+}
+
+// prepareGaitDir creates the .gait directory and lists its protected entries in the
+// repository's info/exclude, so that they never show up as untracked changes and are
+// not staged with everything else
+func (s *Service) prepareGaitDir() error {
+	if err := os.MkdirAll(filepath.Join(s.repoPath, gaitDir), 0755); err != nil {
+		return err
+	}
+
+	excludePath, err := s.gitPath("info/exclude")
+	if err != nil {
+		return err
+	}
+	content, err := os.ReadFile(excludePath)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	listed := make(map[string]bool)
+	for _, line := range strings.Split(string(content), "\n") {
+		listed[strings.TrimSpace(line)] = true
+	}
+	missing := make([]string, 0)
+	for _, pattern := range gaitExcludePatterns() {
+		if !listed[pattern] {
+			missing = append(missing, pattern)
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(excludePath), 0755); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(excludePath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	lines := "# GAIT credentials, branch protection and signer trust\n" + strings.Join(missing, "\n") + "\n"
+	if len(content) > 0 && !strings.HasSuffix(string(content), "\n") {
+		lines = "\n" + lines
+	}
+	_, err = file.WriteString(lines)
+	return err
+}
+
+// checkProtectedGaitFiles refuses a revision that tracks protected .gait files other
+// than the ones HEAD tracks, if any. Git replaces ignored files without asking, so
+// checking such a revision out, merging it or resetting to it would swap this
+// repository's credentials, branch protection and signer trust for the revision's own.
+func (s *Service) checkProtectedGaitFiles(rev string) error {
+	target, err := s.protectedGaitFiles(rev)
+	if err != nil {
+		return nil // an unknown revision, which the caller's command reports
+	}
+	current, _ := s.protectedGaitFiles("HEAD")
+	if strings.Join(target, "\n") == strings.Join(current, "\n") {
+		return nil
+	}
+	return fmt.Errorf("%s tracks %s files that would replace this repository's credentials, branch protection or signer trust (%s); remove them from that revision first",
+		rev, gaitDir, strings.Join(gaitProtectedEntries, ", "))
+}
+
+// checkFetchedGaitFiles runs checkProtectedGaitFiles on each commit the last fetch
+// marked for merging, so that a pull can refuse them before it merges them
+func (s *Service) checkFetchedGaitFiles() error {
+	path, err := s.gitPath("FETCH_HEAD")
+	if err != nil {
+		return err
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	for _, line := range strings.Split(string(content), "\n") {
+		hash, rest, found := strings.Cut(line, "\t")
+		if !found || strings.HasPrefix(rest, "not-for-merge") {
+			continue
+		}
+		if err := s.checkProtectedGaitFiles(hash); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// protectedGaitFiles lists the protected .gait files rev tracks as ls-tree lines, in
+// order. A .gait entry that is not a directory is listed too, since checking it out
+// would replace the directory.
+func (s *Service) protectedGaitFiles(rev string) ([]string, error) {
+	root, err := s.runGitCommand("ls-tree", "--full-tree", rev)
+	if err != nil {
+		return nil, err
+	}
+
+	files := make([]string, 0)
+	for _, line := range strings.Split(root, "\n") {
+		info, name, found := strings.Cut(line, "\t")
+		if !found || !strings.EqualFold(name, gaitDir) {
+			continue
+		}
+		if !strings.Contains(info, " tree ") {
+			files = append(files, line)
+			continue
+		}
+		entries, err := s.runGitCommand("ls-tree", "-r", "--full-tree", rev+":"+name)
+		if err != nil {
+			return nil, err
+		}
+		for _, entry := range strings.Split(entries, "\n") {
+			_, file, found := strings.Cut(entry, "\t")
+			if !found {
+				continue
+			}
+			if first, _, _ := strings.Cut(file, "/"); isProtectedGaitEntry(first) {
+				files = append(files, entry)
+			}
+		}
+	}
+	sort.Strings(files)
+	return files, nil
+}
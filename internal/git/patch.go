@@ -0,0 +1,210 @@
+package git
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/knoxai/gait/pkg/types"
+)
+
+var (
+	patchFailedRegex = regexp.MustCompile(`^error: patch failed: (.+):(\d+)$`)
+	hunkHeaderRegex  = regexp.MustCompile(`^@@ -(\d+)(?:,\d+)? \+\d+(?:,\d+)? @@`)
+	diffFileRegex    = regexp.MustCompile(`^diff --git a/(.+) b/(.+)$`)
+)
+
+// FormatPatch returns git format-patch mbox output for a single commit or a revision range
+func (s *Service) FormatPatch(revision string) ([]byte, error) {
+	if revision == "" {
+		return nil, fmt.Errorf("revision cannot be empty")
+	}
+	if err := ValidateRevision(revision); err != nil {
+		return nil, err
+	}
+
+	args := []string{"format-patch", "--stdout"}
+	if strings.Contains(revision, "..") {
+		args = append(args, revision, "--")
+	} else {
+		args = append(args, "-1", revision, "--")
+	}
+
+	output, _, err := s.runGitCommandWithInput(nil, args...)
+	if err != nil {
+		return nil, err
+	}
+	return output, nil
+}
+
+// GetWorkingTreeDiff returns plain diff output for the working tree against HEAD,
+// or only the staged changes when staged is true
+func (s *Service) GetWorkingTreeDiff(staged bool) ([]byte, error) {
+	args := []string{"diff", "--binary"}
+	if staged {
+		args = append(args, "--cached")
+	} else {
+		args = append(args, "HEAD")
+	}
+
+	output, _, err := s.runGitCommandWithInput(nil, args...)
+	if err != nil {
+		return nil, err
+	}
+	return output, nil
+}
+
+// IsMailboxPatch reports whether a patch is in format-patch mbox form rather than a plain diff
+func IsMailboxPatch(patch []byte) bool {
+	return bytes.HasPrefix(patch, []byte("From "))
+}
+
+// ApplyPatch applies a plain patch with git apply or an mbox with git am.
+// The patch is always checked first; per-hunk failures are reported and nothing
+// is applied when the check fails or checkOnly is set.
+func (s *Service) ApplyPatch(patch []byte, useAm bool, checkOnly bool, threeWay bool) (*types.PatchApplyResult, error) {
+	if len(bytes.TrimSpace(patch)) == 0 {
+		return nil, fmt.Errorf("patch cannot be empty")
+	}
+
+	result := &types.PatchApplyResult{
+		Mode:     "apply",
+		Checked:  checkOnly,
+		Files:    []string{},
+		Failures: []types.PatchHunkFailure{},
+	}
+	if useAm {
+		result.Mode = "am"
+	}
+
+	// List the files touched by the patch, which must all be paths a request may write
+	numstat, _, err := s.runGitCommandWithInput(patch, "apply", "--numstat", "-z", "-")
+	if err != nil {
+		return nil, fmt.Errorf("invalid patch: %w", err)
+	}
+	files, err := patchFiles(numstat)
+	if err != nil {
+		return nil, err
+	}
+	result.Files = files
+
+	// --reject makes git report every failing hunk instead of stopping at the first one
+	_, checkOutput, _ := s.runGitCommandWithInput(patch, "apply", "--check", "--reject", "-v", "-")
+	result.Failures = parsePatchFailures(checkOutput, patch)
+	result.Output = strings.TrimSpace(checkOutput)
+
+	if checkOnly || (len(result.Failures) > 0 && !threeWay) {
+		return result, nil
+	}
+
+	var args []string
+	if useAm {
+		args = []string{"am"}
+		if threeWay {
+			args = append(args, "--3way")
+		}
+	} else {
+		args = []string{"apply"}
+		if threeWay {
+			args = append(args, "--3way")
+		}
+		args = append(args, "-")
+	}
+
+	stdout, stderr, err := s.runGitCommandWithInput(patch, args...)
+	result.Output = strings.TrimSpace(string(stdout) + stderr)
+	if err != nil {
+		if useAm {
+			// Leave the repository as it was rather than mid-am
+			s.runGitCommand("am", "--abort")
+		}
+		return result, nil
+	}
+
+	result.Applied = true
+	if useAm {
+		s.invalidateBranchesCache()
+	}
+	return result, nil
+}
+
+// patchFiles returns the files in git apply --numstat -z output, checked with
+// CleanPath. A rename lists the new path, after checking the old one too.
+func patchFiles(numstat []byte) ([]string, error) {
+	files := []string{}
+	fields := strings.Split(string(numstat), "\x00")
+	for i := 0; i < len(fields); i++ {
+		parts := strings.SplitN(fields[i], "\t", 3)
+		if len(parts) != 3 {
+			continue
+		}
+		paths := []string{parts[2]}
+		if parts[2] == "" && i+2 < len(fields) {
+			// Renames and copies give the old and new paths as the next two fields
+			paths = fields[i+1 : i+3]
+			i += 2
+		}
+		for _, path := range paths {
+			if _, err := CleanPath(path); err != nil {
+				return nil, err
+			}
+		}
+		files = append(files, paths[len(paths)-1])
+	}
+	return files, nil
+}
+
+// parsePatchFailures extracts per-hunk failures from git apply -v output and maps
+// each failing line back to its hunk number in the patch
+func parsePatchFailures(output string, patch []byte) []types.PatchHunkFailure {
+	failures := []types.PatchHunkFailure{}
+
+	// Index hunk start lines per file so failures can be numbered
+	hunkStarts := make(map[string][]int)
+	currentFile := ""
+	for _, line := range strings.Split(string(patch), "\n") {
+		if matches := diffFileRegex.FindStringSubmatch(line); len(matches) == 3 {
+			currentFile = matches[2]
+		} else if matches := hunkHeaderRegex.FindStringSubmatch(line); len(matches) == 2 && currentFile != "" {
+			start, _ := strconv.Atoi(matches[1])
+			hunkStarts[currentFile] = append(hunkStarts[currentFile], start)
+		}
+	}
+
+	var context []string
+	inContext := false
+	for _, line := range strings.Split(output, "\n") {
+		if line == "error: while searching for:" {
+			inContext = true
+			context = nil
+			continue
+		}
+
+		if matches := patchFailedRegex.FindStringSubmatch(line); len(matches) == 3 {
+			lineNum, _ := strconv.Atoi(matches[2])
+			failure := types.PatchHunkFailure{
+				Path:    matches[1],
+				Line:    lineNum,
+				Context: strings.TrimSpace(strings.Join(context, "\n")),
+			}
+			for i, start := range hunkStarts[failure.Path] {
+				if start == lineNum {
+					failure.Hunk = i + 1
+					break
+				}
+			}
+			failures = append(failures, failure)
+			inContext = false
+			context = nil
+			continue
+		}
+
+		if inContext {
+			context = append(context, line)
+		}
+	}
+
+	return failures
+}
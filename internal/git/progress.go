@@ -0,0 +1,269 @@
+package git
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/knoxai/gait/pkg/types"
+)
+
+// ProgressFunc receives progress parsed from git's --progress output
+type ProgressFunc func(types.JobProgress)
+
+var (
+	progressPercentRegex = regexp.MustCompile(`^(?:remote: )?([A-Za-z][A-Za-z ]*):\s+(\d+)% \((\d+)/(\d+)\)`)
+	progressCountRegex   = regexp.MustCompile(`^(?:remote: )?([A-Za-z][A-Za-z ]*): (\d+)(?:, done\.)?$`)
+	fetchRefUpdateRegex  = regexp.MustCompile(`^ ([ +\-t*!=]) (\[[^\]]+\]|\S+)\s+(\S+)\s+->\s+(\S+)(?:\s+\((.+)\))?$`)
+	pushRefUpdateRegex   = regexp.MustCompile(`^([ +\-t*!=])\t([^:]*):(\S+)\t(.*?)(?: \((.+)\))?$`)
+)
+
+// refUpdateStatus maps git's single-character ref update flags to statuses
+var refUpdateStatus = map[string]string{
+	" ": "fast-forward",
+	"+": "forced",
+	"-": "deleted",
+	"*": "new",
+	"!": "rejected",
+	"=": "up-to-date",
+	"t": "tag-update",
+}
+
+// parseProgressLine parses a single line of git progress output
+func parseProgressLine(line string) (types.JobProgress, bool) {
+	line = strings.TrimSpace(line)
+	if matches := progressPercentRegex.FindStringSubmatch(line); len(matches) == 5 {
+		percent, _ := strconv.Atoi(matches[2])
+		current, _ := strconv.ParseInt(matches[3], 10, 64)
+		total, _ := strconv.ParseInt(matches[4], 10, 64)
+		return types.JobProgress{Phase: matches[1], Percent: percent, Current: current, Total: total, Message: line}, true
+	}
+	if matches := progressCountRegex.FindStringSubmatch(line); len(matches) == 3 {
+		current, _ := strconv.ParseInt(matches[2], 10, 64)
+		return types.JobProgress{Phase: matches[1], Current: current, Message: line}, true
+	}
+	return types.JobProgress{}, false
+}
+
+// scanProgressLines splits git stderr on both \r and \n, since progress meters
+// redraw the current line with carriage returns
+func scanProgressLines(data []byte, atEOF bool) (int, []byte, error) {
+	if atEOF && len(data) == 0 {
+		return 0, nil, nil
+	}
+	if i := bytes.IndexAny(data, "\r\n"); i >= 0 {
+		return i + 1, data[:i], nil
+	}
+	if atEOF {
+		return len(data), data, nil
+	}
+	return 0, nil, nil
+}
+
+// runGitWithProgress runs git in dir with --progress style output on stderr. Progress
+// lines are reported through progress; all other stderr lines are returned. The command
+// is killed when ctx is cancelled or its timeout passes, and fails with a CommandError
+// of kind ErrCancelled or ErrTimeout. env is the full environment of the command; nil
+// inherits the process environment.
+func runGitWithProgress(ctx context.Context, dir string, env []string, progress ProgressFunc, args ...string) (string, string, error) {
+	ctx, cancel := withTimeout(ctx, args, 0)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	cmd.Env = env
+	cmd.WaitDelay = commandWaitDelay
+
+	var stdout bytes.Buffer
+	cmd.Stdout = &stdout
+	stderrPipe, err := cmd.StderrPipe()
+	if err != nil {
+		return "", "", err
+	}
+	if err := cmd.Start(); err != nil {
+		return "", "", err
+	}
+
+	var stderr strings.Builder
+	scanner := bufio.NewScanner(stderrPipe)
+	scanner.Split(scanProgressLines)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		if p, ok := parseProgressLine(line); ok {
+			if progress != nil {
+				progress(p)
+			}
+			continue
+		}
+		stderr.WriteString(line)
+		stderr.WriteString("\n")
+	}
+
+	err = cmd.Wait()
+	if err != nil || ctx.Err() != nil {
+		return stdout.String(), stderr.String(), commandError(ctx, args, err, stderr.String())
+	}
+	return stdout.String(), stderr.String(), nil
+}
+
+// parseRefUpdates parses ref update lines from fetch stderr or push --porcelain stdout
+func parseRefUpdates(output string, porcelain bool) ([]types.RefUpdate, []types.RefUpdate) {
+	updated := make([]types.RefUpdate, 0)
+	rejected := make([]types.RefUpdate, 0)
+
+	for _, line := range strings.Split(output, "\n") {
+		var update types.RefUpdate
+		if porcelain {
+			matches := pushRefUpdateRegex.FindStringSubmatch(line)
+			if len(matches) != 6 {
+				continue
+			}
+			update = types.RefUpdate{Status: refUpdateStatus[matches[1]], From: matches[2], To: matches[3], Summary: matches[4], Reason: matches[5]}
+		} else {
+			matches := fetchRefUpdateRegex.FindStringSubmatch(line)
+			if len(matches) != 6 {
+				continue
+			}
+			update = types.RefUpdate{Status: refUpdateStatus[matches[1]], From: matches[3], To: matches[4], Summary: matches[2], Reason: matches[5]}
+		}
+
+		if update.Status == "rejected" {
+			rejected = append(rejected, update)
+		} else {
+			updated = append(updated, update)
+		}
+	}
+	return updated, rejected
+}
+
+// FetchWithProgress fetches from remote (all remotes when empty), reporting progress
+// and returning the refs that were updated or rejected
+func (s *Service) FetchWithProgress(ctx context.Context, remote string, prune bool, progress ProgressFunc) (*types.RemoteOperationResult, error) {
+	s = s.WithContext(ctx)
+	args := []string{"fetch", "--progress"}
+	if prune {
+		args = append(args, "--prune")
+	}
+	if remote != "" {
+		if err := ValidateRemoteName(remote); err != nil {
+			return nil, err
+		}
+		args = append(args, "--", remote)
+	} else {
+		args = append(args, "--all")
+	}
+
+	_, stderr, err := runGitWithProgress(ctx, s.repoPath, s.environ(s.remoteAuthEnv(remote)...), progress, args...)
+	err = classifyRemoteError(remote, err)
+	result := &types.RemoteOperationResult{Operation: "fetch", Remote: remote, Output: strings.TrimSpace(stderr)}
+	result.Updated, result.Rejected = parseRefUpdates(stderr, false)
+	s.invalidateBranchesCache()
+	s.invalidateTagsCache()
+	return result, err
+}
+
+// PullWithProgress pulls from remote, reporting fetch progress. Like PullFromRemote, it
+// fetches first and refuses fetched commits that would replace protected .gait files.
+func (s *Service) PullWithProgress(ctx context.Context, remote string, branch string, progress ProgressFunc) (*types.RemoteOperationResult, error) {
+	s = s.WithContext(ctx)
+	fetchArgs, err := remoteBranchArgs([]string{"fetch", "--progress", "--update-head-ok"}, remote, branch)
+	if err != nil {
+		return nil, err
+	}
+
+	stdout, stderr, err := runGitWithProgress(ctx, s.repoPath, s.environ(s.remoteAuthEnv(remote)...), progress, fetchArgs...)
+	err = classifyRemoteError(remote, err)
+	result := &types.RemoteOperationResult{Operation: "pull", Remote: remote, Output: strings.TrimSpace(stdout + stderr)}
+	result.Updated, result.Rejected = parseRefUpdates(stderr, false)
+	defer s.invalidateBranchesCache()
+	if err != nil {
+		return result, err
+	}
+	if err := s.checkFetchedGaitFiles(); err != nil {
+		return result, err
+	}
+
+	args, _ := remoteBranchArgs([]string{"pull"}, remote, branch)
+	output, err := s.runRemoteCommand(remote, args...)
+	result.Output = strings.TrimSpace(result.Output + "\n" + output)
+	return result, err
+}
+
+// PushWithProgress pushes to remote, reporting progress and returning the refs that
+// were updated or rejected. A push with rejected refs also returns an error. Force
+// pushes follow the same rules as PushToRemote.
+func (s *Service) PushWithProgress(ctx context.Context, remote string, branch string, force bool, progress ProgressFunc) (*types.RemoteOperationResult, error) {
+	s = s.WithContext(ctx)
+	if err := s.guardPush(remote, branch, force); err != nil {
+		return nil, err
+	}
+	args := []string{"push", "--progress", "--porcelain"}
+	if force {
+		args = append(args, s.forceFlag())
+	}
+	args, err := remoteBranchArgs(args, remote, branch)
+	if err != nil {
+		return nil, err
+	}
+
+	stdout, stderr, err := runGitWithProgress(ctx, s.repoPath, s.environ(s.remoteAuthEnv(remote)...), progress, args...)
+	err = classifyRemoteError(remote, err)
+	result := &types.RemoteOperationResult{Operation: "push", Remote: remote, Output: strings.TrimSpace(stdout + stderr)}
+	result.Updated, result.Rejected = parseRefUpdates(stdout, true)
+	s.invalidateBranchesCache()
+	if err == nil && len(result.Rejected) > 0 {
+		err = fmt.Errorf("push rejected for %d ref(s)", len(result.Rejected))
+	}
+	return result, err
+}
+
+// CloneWithProgress clones url into path using credential, which may be nil, reporting
+// progress. opts can limit the clone to one branch, a shallow depth, a partial clone
+// filter and sparse-checkout cone directories. A partially cloned directory is removed
+// when the clone fails or is cancelled; on success the credential is stored for the new
+// repository's origin.
+func CloneWithProgress(ctx context.Context, url string, path string, credential *types.RemoteCredential, opts types.CloneOptions, progress ProgressFunc) (*types.RemoteOperationResult, error) {
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		return nil, fmt.Errorf("directory already exists: %s", path)
+	}
+	optionArgs, err := cloneArgs(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	args := append([]string{"clone", "--progress"}, optionArgs...)
+	args = append(args, "--", url, path)
+	_, stderr, err := runGitWithProgress(ctx, "", append(os.Environ(), AuthEnv(credential)...), progress, args...)
+	if err != nil {
+		os.RemoveAll(path)
+		return nil, classifyRemoteError("origin", err)
+	}
+	if len(opts.Sparse) > 0 {
+		if _, err := NewService(path).WithContext(ctx).SetSparseCheckout(opts.Sparse, true); err != nil {
+			os.RemoveAll(path)
+			return nil, err
+		}
+	}
+	if credential != nil && (credential.Token != "" || credential.SSHKeyPath != "") {
+		stored := *credential
+		stored.Remote = "origin"
+		if err := NewService(path).SaveCredential(stored); err != nil {
+			return nil, fmt.Errorf("cloned, but failed to store credentials: %v", err)
+		}
+	}
+	return &types.RemoteOperationResult{
+		Operation: "clone",
+		Remote:    "origin",
+		Updated:   []types.RefUpdate{},
+		Output:    strings.TrimSpace(stderr),
+		Path:      path,
+	}, nil
+}
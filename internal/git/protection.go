@@ -0,0 +1,223 @@
+package git
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/knoxai/gait/pkg/types"
+)
+
+// protectionConfigFile holds the repository's branch protection settings, relative to the repository root
+const protectionConfigFile = ".gait/protection.json"
+
+// protectionLogFile records blocked operations as JSON lines, relative to the repository root
+const protectionLogFile = ".gait/protection.log"
+
+// Operations guarded on protected branches
+const (
+	OperationForcePush = "force-push"
+	OperationDelete    = "delete"
+	OperationHardReset = "hard-reset"
+)
+
+// ProtectionConfig lists the branches guarded against destructive operations
+type ProtectionConfig struct {
+	ProtectedBranches []string `json:"protectedBranches"` // patterns such as "main" or "release/*"
+	ForceWithLease    bool     `json:"forceWithLease"`    // force pushes use --force-with-lease instead of --force
+}
+
+// DefaultProtectionConfig returns the protection settings used when a repository has no config
+func DefaultProtectionConfig() *ProtectionConfig {
+	return &ProtectionConfig{
+		ProtectedBranches: []string{},
+		ForceWithLease:    true,
+	}
+}
+
+// ProtectedBranchError is returned when an operation is blocked on a protected branch
+type ProtectedBranchError struct {
+	Operation string
+	Branch    string
+	Pattern   string
+}
+
+func (e *ProtectedBranchError) Error() string {
+	return fmt.Sprintf("%s of protected branch %s is not allowed (matches %q)", e.Operation, e.Branch, e.Pattern)
+}
+
+// ErrorCode identifies protected branch violations to API clients
+func (e *ProtectedBranchError) ErrorCode() string {
+	return "protected_branch"
+}
+
+// LoadProtectionConfig reads the repository's protection config, falling back to defaults
+func (s *Service) LoadProtectionConfig() (*ProtectionConfig, error) {
+	config := DefaultProtectionConfig()
+
+	data, err := os.ReadFile(filepath.Join(s.repoPath, protectionConfigFile))
+	if os.IsNotExist(err) {
+		return config, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(data, config); err != nil {
+		return nil, fmt.Errorf("invalid protection config: %v", err)
+	}
+	return config, nil
+}
+
+// SaveProtectionConfig writes the repository's protection config
+func (s *Service) SaveProtectionConfig(config *ProtectionConfig) error {
+	for _, pattern := range config.ProtectedBranches {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid branch pattern %q: %v", pattern, err)
+		}
+	}
+
+	configPath := filepath.Join(s.repoPath, protectionConfigFile)
+	if err := s.prepareGaitDir(); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(config, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(configPath, data, 0644)
+}
+
+// protectedPattern returns the configured pattern protecting branch, if any
+func (s *Service) protectedPattern(branch string) (string, bool) {
+	config, err := s.LoadProtectionConfig()
+	if err != nil {
+		// An unreadable config protects everything rather than nothing
+		return protectionConfigFile, true
+	}
+	for _, pattern := range config.ProtectedBranches {
+		if matched, _ := path.Match(pattern, branch); matched || pattern == branch {
+			return pattern, true
+		}
+	}
+	return "", false
+}
+
+// checkProtected returns *ProtectedBranchError, and logs the attempt, when operation
+// targets a protected branch
+func (s *Service) checkProtected(operation string, branch string, remote string) error {
+	if branch == "" {
+		return nil
+	}
+	pattern, protected := s.protectedPattern(branch)
+	if !protected {
+		return nil
+	}
+
+	s.logBlockedOperation(types.BlockedOperation{
+		Time:      time.Now(),
+		Operation: operation,
+		Branch:    branch,
+		Remote:    remote,
+		Pattern:   pattern,
+	})
+	return &ProtectedBranchError{Operation: operation, Branch: branch, Pattern: pattern}
+}
+
+// logBlockedOperation writes a blocked operation to the server log and the repository's protection log
+func (s *Service) logBlockedOperation(blocked types.BlockedOperation) {
+	target := blocked.Branch
+	if blocked.Remote != "" {
+		target = blocked.Remote + "/" + blocked.Branch
+	}
+	log.Printf("Blocked %s of protected branch %s in %s (pattern %q)", blocked.Operation, target, s.repoPath, blocked.Pattern)
+
+	logPath := filepath.Join(s.repoPath, protectionLogFile)
+	if err := s.prepareGaitDir(); err != nil {
+		return
+	}
+	file, err := os.OpenFile(logPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		log.Printf("Failed to record blocked operation: %v", err)
+		return
+	}
+	defer file.Close()
+
+	data, _ := json.Marshal(blocked)
+	file.Write(append(data, '\n'))
+}
+
+// GetBlockedOperations returns the most recent blocked operations, newest first
+func (s *Service) GetBlockedOperations(limit int) ([]types.BlockedOperation, error) {
+	blocked := make([]types.BlockedOperation, 0)
+
+	file, err := os.Open(filepath.Join(s.repoPath, protectionLogFile))
+	if os.IsNotExist(err) {
+		return blocked, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var entry types.BlockedOperation
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err == nil {
+			blocked = append(blocked, entry)
+		}
+	}
+
+	for i, j := 0, len(blocked)-1; i < j; i, j = i+1, j-1 {
+		blocked[i], blocked[j] = blocked[j], blocked[i]
+	}
+	if limit > 0 && len(blocked) > limit {
+		blocked = blocked[:limit]
+	}
+	return blocked, scanner.Err()
+}
+
+// forceFlag returns the flag used for force pushes: --force-with-lease, which only
+// overwrites the remote branch if it still points at the last fetched remote-tracking
+// ref, unless the repository opted out
+func (s *Service) forceFlag() string {
+	if config, err := s.LoadProtectionConfig(); err == nil && !config.ForceWithLease {
+		return "--force"
+	}
+	return "--force-with-lease"
+}
+
+// guardPush blocks pushes of refspec that would force-update or delete a protected branch
+func (s *Service) guardPush(remote string, refspec string, force bool) error {
+	if strings.HasPrefix(refspec, ":") {
+		return s.checkProtected(OperationDelete, strings.TrimPrefix(strings.TrimPrefix(refspec, ":"), "refs/heads/"), remote)
+	}
+	if force || strings.HasPrefix(refspec, "+") {
+		return s.checkProtected(OperationForcePush, s.pushDestination(refspec), remote)
+	}
+	return nil
+}
+
+// pushDestination returns the remote branch a push of refspec updates. An empty refspec
+// pushes the current branch to its upstream branch, or to a branch of the same name.
+func (s *Service) pushDestination(refspec string) string {
+	refspec = strings.TrimPrefix(refspec, "+")
+	if idx := strings.LastIndex(refspec, ":"); idx >= 0 {
+		refspec = refspec[idx+1:]
+	}
+	if refspec == "" || refspec == "HEAD" {
+		current, _ := s.runGitCommand("symbolic-ref", "--short", "HEAD")
+		refspec = current
+		if merge, err := s.runGitCommand("config", "branch."+current+".merge"); err == nil && merge != "" {
+			refspec = merge
+		}
+	}
+	return strings.TrimPrefix(refspec, "refs/heads/")
+}
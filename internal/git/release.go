@@ -0,0 +1,164 @@
+package git
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/knoxai/gait/pkg/types"
+)
+
+// releaseNoteSections defines the order and titles of release note groups
+var releaseNoteSections = []struct {
+	Type  string
+	Title string
+}{
+	{"feat", "Features"},
+	{"fix", "Bug Fixes"},
+	{"perf", "Performance Improvements"},
+	{"refactor", "Code Refactoring"},
+	{"revert", "Reverts"},
+	{"docs", "Documentation"},
+	{"style", "Styles"},
+	{"test", "Tests"},
+	{"build", "Build System"},
+	{"ci", "Continuous Integration"},
+	{"chore", "Chores"},
+	{"other", "Other Changes"},
+}
+
+// GetPreviousTag returns the closest tag reachable from the parent of ref, or "" if there is none
+func (s *Service) GetPreviousTag(ref string) string {
+	previous, err := s.runGitCommand("describe", "--tags", "--abbrev=0", ref+"^")
+	if err != nil {
+		return ""
+	}
+	return previous
+}
+
+// GenerateReleaseNotes lists the commits between a tag and its predecessor,
+// grouped by conventional-commit type, with a markdown rendering
+func (s *Service) GenerateReleaseNotes(tagName string) (*types.ReleaseNotes, error) {
+	if tagName == "" {
+		return nil, fmt.Errorf("tag name cannot be empty")
+	}
+	if err := ValidateRevision(tagName); err != nil {
+		return nil, err
+	}
+
+	if _, err := s.runGitCommand("rev-parse", "--verify", "--quiet", tagName+"^{commit}"); err != nil {
+		return nil, fmt.Errorf("tag not found: %s", tagName)
+	}
+
+	notes := &types.ReleaseNotes{
+		Tag:         tagName,
+		PreviousTag: s.GetPreviousTag(tagName),
+		Date:        time.Now(),
+	}
+
+	if dateOutput, err := s.runGitCommand("log", "-1", "--format=%cI", tagName, "--"); err == nil {
+		if date, err := time.Parse(time.RFC3339, dateOutput); err == nil {
+			notes.Date = date
+		}
+	}
+
+	revisionRange := tagName
+	if notes.PreviousTag != "" {
+		revisionRange = notes.PreviousTag + ".." + tagName
+	}
+
+	commits, err := s.getCommitsInRange(revisionRange)
+	if err != nil {
+		return nil, err
+	}
+
+	notes.Groups, notes.Breaking = groupReleaseNoteEntries(commits)
+	notes.Markdown = renderReleaseNotesMarkdown(notes.Tag, notes.PreviousTag, notes.Date, notes.Groups, notes.Breaking)
+	return notes, nil
+}
+
+// groupReleaseNoteEntries groups commits by conventional-commit type in section order
+func groupReleaseNoteEntries(commits []rangeCommit) ([]types.ReleaseNoteGroup, []types.ReleaseNoteEntry) {
+	byType := make(map[string][]types.ReleaseNoteEntry)
+	breaking := make([]types.ReleaseNoteEntry, 0)
+
+	known := make(map[string]bool)
+	for _, section := range releaseNoteSections {
+		known[section.Type] = true
+	}
+
+	for _, commit := range commits {
+		cc := ParseConventionalCommit(commit.Subject, commit.Body)
+		entryType := cc.Type
+		if !cc.Valid || !known[entryType] {
+			entryType = "other"
+		}
+
+		entry := types.ReleaseNoteEntry{
+			Hash:      commit.Hash,
+			ShortHash: commit.ShortHash,
+			Type:      entryType,
+			Scope:     cc.Scope,
+			Subject:   cc.Description,
+			Author:    commit.Author,
+			Breaking:  cc.Breaking,
+		}
+
+		byType[entryType] = append(byType[entryType], entry)
+		if cc.Breaking {
+			breaking = append(breaking, entry)
+		}
+	}
+
+	groups := make([]types.ReleaseNoteGroup, 0)
+	for _, section := range releaseNoteSections {
+		if entries := byType[section.Type]; len(entries) > 0 {
+			groups = append(groups, types.ReleaseNoteGroup{
+				Type:    section.Type,
+				Title:   section.Title,
+				Entries: entries,
+			})
+		}
+	}
+
+	return groups, breaking
+}
+
+// renderReleaseNotesMarkdown renders grouped release notes as markdown
+func renderReleaseNotesMarkdown(tag string, previousTag string, date time.Time, groups []types.ReleaseNoteGroup, breaking []types.ReleaseNoteEntry) string {
+	var sb strings.Builder
+
+	fmt.Fprintf(&sb, "## %s (%s)\n", tag, date.Format("2006-01-02"))
+	if previousTag != "" {
+		fmt.Fprintf(&sb, "\nChanges since %s.\n", previousTag)
+	}
+
+	if len(breaking) > 0 {
+		sb.WriteString("\n### ⚠ BREAKING CHANGES\n\n")
+		for _, entry := range breaking {
+			writeReleaseNoteEntry(&sb, entry)
+		}
+	}
+
+	for _, group := range groups {
+		fmt.Fprintf(&sb, "\n### %s\n\n", group.Title)
+		for _, entry := range group.Entries {
+			writeReleaseNoteEntry(&sb, entry)
+		}
+	}
+
+	if len(groups) == 0 {
+		sb.WriteString("\nNo changes.\n")
+	}
+
+	return sb.String()
+}
+
+// writeReleaseNoteEntry writes a single markdown list item for a release note entry
+func writeReleaseNoteEntry(sb *strings.Builder, entry types.ReleaseNoteEntry) {
+	if entry.Scope != "" {
+		fmt.Fprintf(sb, "- **%s:** %s (%s)\n", entry.Scope, entry.Subject, entry.ShortHash)
+	} else {
+		fmt.Fprintf(sb, "- %s (%s)\n", entry.Subject, entry.ShortHash)
+	}
+}
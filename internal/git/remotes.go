@@ -0,0 +1,225 @@
+package git
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/knoxai/gait/pkg/types"
+)
+
+// requireRemote returns an error unless a remote with the given name is configured
+func (s *Service) requireRemote(name string) error {
+	if name == "" {
+		return fmt.Errorf("remote name cannot be empty")
+	}
+	output, err := s.runGitCommand("remote")
+	if err != nil {
+		return err
+	}
+	for _, remote := range strings.Fields(output) {
+		if remote == name {
+			return nil
+		}
+	}
+	return fmt.Errorf("remote not found: %s", name)
+}
+
+// parseRemoteShowBranch parses one entry of the "Remote branches:" section of git remote show
+func parseRemoteShowBranch(remote string, line string) types.RemoteBranch {
+	fields := strings.Fields(line)
+	branch := types.RemoteBranch{Name: fields[0], Status: "unknown"}
+	if len(fields) > 1 {
+		branch.Status = fields[1]
+	}
+	if branch.Status == "stale" {
+		branch.Name = strings.TrimPrefix(branch.Name, "refs/remotes/"+remote+"/")
+	}
+	return branch
+}
+
+// markLocallyTracked records which local branches have each remote branch as upstream
+func (s *Service) markLocallyTracked(remote string, branches []types.RemoteBranch) {
+	output, err := s.runGitCommand("for-each-ref", "refs/heads", "--format=%(refname:short)|%(upstream:remotename)|%(upstream:remoteref)")
+	if err != nil {
+		return
+	}
+
+	tracking := make(map[string][]string)
+	for _, line := range strings.Split(output, "\n") {
+		parts := strings.Split(line, "|")
+		if len(parts) == 3 && parts[1] == remote {
+			name := strings.TrimPrefix(parts[2], "refs/heads/")
+			tracking[name] = append(tracking[name], parts[0])
+		}
+	}
+
+	for i := range branches {
+		branches[i].LocalBranches = tracking[branches[i].Name]
+		branches[i].TrackedLocally = len(branches[i].LocalBranches) > 0
+	}
+}
+
+// AddRemote adds a remote, optionally fetching it straight away
+func (s *Service) AddRemote(name string, url string, fetch bool) error {
+	if name == "" || url == "" {
+		return fmt.Errorf("remote name and URL are required")
+	}
+	if err := ValidateRemoteName(name); err != nil {
+		return err
+	}
+	if err := ValidateRemoteURL(url); err != nil {
+		return err
+	}
+
+	_, err := s.runGitCommand("remote", "add", "--", name, url)
+	s.invalidateRemotesCache()
+	if err != nil || !fetch {
+		return err
+	}
+
+	_, err = s.runRemoteCommand(name, "fetch", "--", name)
+	s.invalidateBranchesCache()
+	return err
+}
+
+// RenameRemote renames a remote, its remote-tracking branches and its stored credentials
+func (s *Service) RenameRemote(oldName string, newName string) error {
+	if err := s.requireRemote(oldName); err != nil {
+		return err
+	}
+	if newName == "" {
+		return fmt.Errorf("new remote name cannot be empty")
+	}
+	if err := ValidateRemoteName(newName); err != nil {
+		return err
+	}
+
+	if _, err := s.runGitCommand("remote", "rename", "--", oldName, newName); err != nil {
+		return err
+	}
+	s.invalidateRemotesCache()
+	s.invalidateBranchesCache()
+
+	return s.renameCredential(oldName, newName)
+}
+
+// RemoveRemote removes a remote, its remote-tracking branches and its stored credentials
+func (s *Service) RemoveRemote(name string) error {
+	if err := s.requireRemote(name); err != nil {
+		return err
+	}
+
+	if _, err := s.runGitCommand("remote", "remove", "--", name); err != nil {
+		return err
+	}
+	s.invalidateRemotesCache()
+	s.invalidateBranchesCache()
+
+	if credentials, err := s.LoadCredentials(); err == nil {
+		if _, ok := credentials[name]; ok {
+			return s.DeleteCredential(name)
+		}
+	}
+	return nil
+}
+
+// SetRemoteURL sets a remote's fetch URL, or its push URL when push is true. An empty
+// push URL removes it so pushes go to the fetch URL again.
+func (s *Service) SetRemoteURL(name string, url string, push bool) error {
+	if err := s.requireRemote(name); err != nil {
+		return err
+	}
+
+	if url != "" {
+		if err := ValidateRemoteURL(url); err != nil {
+			return err
+		}
+	}
+
+	var err error
+	switch {
+	case push && url == "":
+		_, err = s.runGitCommand("config", "--unset-all", "remote."+name+".pushurl")
+	case push:
+		_, err = s.runGitCommand("remote", "set-url", "--push", "--", name, url)
+	case url == "":
+		return fmt.Errorf("fetch URL cannot be empty")
+	default:
+		_, err = s.runGitCommand("remote", "set-url", "--", name, url)
+	}
+	s.invalidateRemotesCache()
+	return err
+}
+
+// GetRemoteRefspecs returns a remote's fetch refspecs
+func (s *Service) GetRemoteRefspecs(name string) ([]string, error) {
+	output, err := s.runGitCommand("config", "--get-all", "remote."+name+".fetch")
+	if err != nil {
+		// git config exits with 1 when the key is not set
+		return []string{}, nil
+	}
+	refspecs := make([]string, 0)
+	for _, line := range strings.Split(output, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			refspecs = append(refspecs, line)
+		}
+	}
+	return refspecs, nil
+}
+
+// SetRemoteRefspecs replaces a remote's fetch refspecs
+func (s *Service) SetRemoteRefspecs(name string, refspecs []string) error {
+	if err := s.requireRemote(name); err != nil {
+		return err
+	}
+	if len(refspecs) == 0 {
+		return fmt.Errorf("at least one fetch refspec is required")
+	}
+	for _, refspec := range refspecs {
+		if err := ValidateRefspec(refspec); err != nil {
+			return err
+		}
+	}
+
+	key := "remote." + name + ".fetch"
+	s.runGitCommand("config", "--unset-all", key)
+	for _, refspec := range refspecs {
+		if _, err := s.runGitCommand("config", "--add", key, refspec); err != nil {
+			return err
+		}
+	}
+	s.invalidateRemotesCache()
+	return nil
+}
+
+// PruneRemote deletes remote-tracking branches whose branch no longer exists on the remote,
+// returning the pruned refs. With dryRun nothing is deleted.
+func (s *Service) PruneRemote(name string, dryRun bool) ([]string, error) {
+	if err := s.requireRemote(name); err != nil {
+		return nil, err
+	}
+
+	args := []string{"remote", "prune"}
+	if dryRun {
+		args = append(args, "--dry-run")
+	}
+	output, err := s.runRemoteCommand(name, append(args, "--", name)...)
+	if err != nil {
+		return nil, err
+	}
+
+	pruned := make([]string, 0)
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		for _, prefix := range []string{"* [pruned] ", "* [would prune] "} {
+			if strings.HasPrefix(line, prefix) {
+				pruned = append(pruned, strings.TrimPrefix(line, prefix))
+			}
+		}
+	}
+
+	if !dryRun {
+		s.invalidateBranchesCache()
+	}
+	return pruned, nil
+}
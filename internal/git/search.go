@@ -0,0 +1,222 @@
+package git
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/knoxai/gait/pkg/types"
+)
+
+// Search types accepted by Search
+const (
+	SearchMessage = "message"
+	SearchAuthor  = "author"
+	SearchHash    = "hash"
+	SearchPath    = "path"
+	SearchCode    = "code" // pickaxe: commits that add or remove the text
+)
+
+// searchTimeout bounds each git log run by a search; pickaxe searches read every diff
+const searchTimeout = 30 * time.Second
+
+// searchWeights ranks the kinds of match against each other
+var searchWeights = map[string]float64{
+	SearchHash:    5,
+	SearchMessage: 3,
+	SearchPath:    2.5,
+	SearchAuthor:  2,
+	SearchCode:    2,
+}
+
+var hexPrefixRegex = regexp.MustCompile(`^[0-9a-fA-F]{4,40}$`)
+
+// searchTypes returns the search types a request asks for
+func searchTypes(req types.SearchRequest) ([]string, error) {
+	requested := req.Types
+	if len(requested) == 0 && req.Type != "" {
+		requested = []string{req.Type}
+	}
+	if len(requested) == 0 {
+		// Pickaxe searches read every diff, so they only run when asked for
+		return []string{SearchMessage, SearchAuthor, SearchHash, SearchPath}, nil
+	}
+
+	kinds := make([]string, 0, len(requested))
+	for _, kind := range requested {
+		if kind == "file" {
+			kind = SearchPath
+		}
+		if _, ok := searchWeights[kind]; !ok {
+			return nil, fmt.Errorf("unknown search type %q: expected message, author, hash, path or code", kind)
+		}
+		kinds = append(kinds, kind)
+	}
+	return kinds, nil
+}
+
+// ValidateSearchRequest checks the search types a request asks for
+func ValidateSearchRequest(req types.SearchRequest) error {
+	_, err := searchTypes(req)
+	return err
+}
+
+// Search finds commits on all refs whose message, author, hash, changed paths or diff
+// text (code) match the query, ranked best first
+func (s *Service) Search(req types.SearchRequest) ([]types.SearchResult, error) {
+	query := strings.TrimSpace(req.Query)
+	if query == "" {
+		return nil, fmt.Errorf("search query cannot be empty")
+	}
+	kinds, err := searchTypes(req)
+	if err != nil {
+		return nil, err
+	}
+	limit := req.MaxResults
+	if limit <= 0 {
+		limit = 100
+	}
+
+	results := make([]types.SearchResult, 0)
+	now := time.Now()
+	for _, kind := range kinds {
+		found, err := s.searchKind(kind, query, limit)
+		if err != nil {
+			return nil, fmt.Errorf("%s search failed: %w", kind, err)
+		}
+		for i := range found {
+			found[i].Score = searchScore(found[i], query, now)
+		}
+		results = append(results, found...)
+	}
+
+	SortSearchResults(results)
+	if len(results) > limit {
+		results = results[:limit]
+	}
+	return results, nil
+}
+
+// SortSearchResults orders results best match first, newest first among equal scores
+func SortSearchResults(results []types.SearchResult) {
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return results[i].Date.After(results[j].Date)
+	})
+}
+
+// searchKind runs one kind of search
+func (s *Service) searchKind(kind, query string, limit int) ([]types.SearchResult, error) {
+	args := []string{"log", "--all", "-n", fmt.Sprintf("%d", limit), "--format=\x1e%H\x1f%an\x1f%aI\x1f%s"}
+	switch kind {
+	case SearchHash:
+		if !hexPrefixRegex.MatchString(query) {
+			return nil, nil
+		}
+		hash, err := s.runGitCommand("rev-parse", "--verify", "--quiet", query+"^{commit}")
+		if err != nil {
+			return nil, nil
+		}
+		args = []string{"log", "-1", "--format=\x1e%H\x1f%an\x1f%aI\x1f%s", hash}
+	case SearchMessage:
+		args = append(args, "-i", "--fixed-strings", "--grep="+query)
+	case SearchAuthor:
+		args = append(args, "-i", "--fixed-strings", "--author="+query)
+	case SearchPath:
+		args = append(args, "--name-only", "--", ":(icase,glob)**/*"+escapeGlob(query)+"*")
+	case SearchCode:
+		args = append(args, "--name-only", "-S", query)
+	}
+
+	output, err := s.runGitCommandWithTimeout(searchTimeout, args...)
+	if err != nil {
+		return nil, err
+	}
+	return parseSearchLog(kind, query, output), nil
+}
+
+// parseSearchLog parses git log output written with the search format
+func parseSearchLog(kind, query, output string) []types.SearchResult {
+	results := make([]types.SearchResult, 0)
+	for _, record := range strings.Split(output, "\x1e") {
+		lines := strings.Split(strings.TrimSpace(record), "\n")
+		fields := strings.Split(lines[0], "\x1f")
+		if len(fields) != 4 {
+			continue
+		}
+		date, _ := time.Parse(time.RFC3339, fields[2])
+		result := types.SearchResult{
+			CommitHash: fields[0],
+			Type:       kind,
+			Subject:    fields[3],
+			Author:     fields[1],
+			Date:       date,
+			Context:    fmt.Sprintf("%s: %s", fields[1], fields[3]),
+		}
+		for _, path := range lines[1:] {
+			if path = strings.TrimSpace(path); path != "" {
+				result.Paths = append(result.Paths, path)
+			}
+		}
+
+		switch kind {
+		case SearchHash:
+			result.Match = fields[0]
+		case SearchMessage:
+			result.Match = fields[3]
+		case SearchAuthor:
+			result.Match = fields[1]
+		case SearchPath:
+			result.Match = strings.Join(result.Paths, ", ")
+		case SearchCode:
+			result.Match = query
+			if len(result.Paths) > 0 {
+				result.Context = fmt.Sprintf("%s in %s", result.Context, strings.Join(result.Paths, ", "))
+			}
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+// searchScore ranks a result by the kind of match, how closely it matches and how
+// recent the commit is
+func searchScore(result types.SearchResult, query string, now time.Time) float64 {
+	score := searchWeights[result.Type]
+
+	match := result.Match
+	if result.Type == SearchMessage {
+		// A match in the subject counts for more than one further down the message
+		if strings.Contains(strings.ToLower(result.Subject), strings.ToLower(query)) {
+			score += 0.5
+		}
+	}
+	switch {
+	case strings.EqualFold(match, query):
+		score += 1
+	case strings.HasPrefix(strings.ToLower(match), strings.ToLower(query)):
+		score += 0.5
+	}
+	if strings.Contains(match, query) {
+		score += 0.25 // same case
+	}
+
+	if !result.Date.IsZero() {
+		ageDays := now.Sub(result.Date).Hours() / 24
+		if ageDays < 0 {
+			ageDays = 0
+		}
+		score += 1 / (1 + ageDays/30)
+	}
+	return score
+}
+
+// escapeGlob escapes glob metacharacters so text is matched literally in a pathspec
+func escapeGlob(text string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, `*`, `\*`, `?`, `\?`, `[`, `\[`)
+	return replacer.Replace(text)
+}
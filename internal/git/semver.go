@@ -0,0 +1,248 @@
+package git
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/knoxai/gait/pkg/types"
+)
+
+var semverTagRegex = regexp.MustCompile(`^(v?)(\d+)\.(\d+)\.(\d+)(?:-([0-9A-Za-z.-]+))?(?:\+[0-9A-Za-z.-]+)?$`)
+
+// semVersion is a parsed semantic version tag
+type semVersion struct {
+	Prefix     string
+	Major      int
+	Minor      int
+	Patch      int
+	PreRelease string
+}
+
+// parseSemver parses a tag such as v1.2.3 or 1.2.3-rc.1
+func parseSemver(tag string) (semVersion, bool) {
+	matches := semverTagRegex.FindStringSubmatch(tag)
+	if len(matches) != 6 {
+		return semVersion{}, false
+	}
+	major, _ := strconv.Atoi(matches[2])
+	minor, _ := strconv.Atoi(matches[3])
+	patch, _ := strconv.Atoi(matches[4])
+	return semVersion{
+		Prefix:     matches[1],
+		Major:      major,
+		Minor:      minor,
+		Patch:      patch,
+		PreRelease: matches[5],
+	}, true
+}
+
+// String formats the version without its tag prefix
+func (v semVersion) String() string {
+	version := fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+	if v.PreRelease != "" {
+		version += "-" + v.PreRelease
+	}
+	return version
+}
+
+// less reports whether v sorts before other; pre-releases sort before their release
+func (v semVersion) less(other semVersion) bool {
+	if v.Major != other.Major {
+		return v.Major < other.Major
+	}
+	if v.Minor != other.Minor {
+		return v.Minor < other.Minor
+	}
+	if v.Patch != other.Patch {
+		return v.Patch < other.Patch
+	}
+	if v.PreRelease == "" || other.PreRelease == "" {
+		return v.PreRelease != "" && other.PreRelease == ""
+	}
+	return comparePreRelease(v.PreRelease, other.PreRelease) < 0
+}
+
+// comparePreRelease compares pre-release versions as semver does: identifier by
+// identifier, numeric ones by value and below alphanumeric ones, which compare as
+// text; when one runs out of identifiers first, it sorts first
+func comparePreRelease(a, b string) int {
+	aIDs, bIDs := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(aIDs) && i < len(bIDs); i++ {
+		aNum, bNum := isNumericIdentifier(aIDs[i]), isNumericIdentifier(bIDs[i])
+		switch {
+		case aNum && bNum:
+			// Numeric identifiers have no leading zeros, so the longer one is larger
+			if len(aIDs[i]) != len(bIDs[i]) {
+				return len(aIDs[i]) - len(bIDs[i])
+			}
+			if c := strings.Compare(aIDs[i], bIDs[i]); c != 0 {
+				return c
+			}
+		case aNum:
+			return -1
+		case bNum:
+			return 1
+		default:
+			if c := strings.Compare(aIDs[i], bIDs[i]); c != 0 {
+				return c
+			}
+		}
+	}
+	return len(aIDs) - len(bIDs)
+}
+
+func isNumericIdentifier(id string) bool {
+	if id == "" {
+		return false
+	}
+	for _, r := range id {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// bump returns the next version for a major, minor or patch bump. A pre-release is
+// completed by its release rather than skipped over when the release is already a
+// bump of that size, as 2.0.0 is for 2.0.0-rc.1 and any bump.
+func (v semVersion) bump(kind string) semVersion {
+	next := semVersion{Prefix: v.Prefix, Major: v.Major, Minor: v.Minor, Patch: v.Patch}
+	pre := v.PreRelease != ""
+	switch kind {
+	case "major":
+		if !pre || v.Minor != 0 || v.Patch != 0 {
+			next.Major++
+		}
+		next.Minor = 0
+		next.Patch = 0
+	case "minor":
+		if !pre || v.Patch != 0 {
+			next.Minor++
+		}
+		next.Patch = 0
+	case "patch":
+		if !pre {
+			next.Patch++
+		}
+	}
+	return next
+}
+
+// GetLatestSemverTag returns the highest semver tag reachable from HEAD, or "" if there is none
+func (s *Service) GetLatestSemverTag() (string, error) {
+	output, err := s.runGitCommand("tag", "--merged", "HEAD")
+	if err != nil {
+		return "", err
+	}
+
+	latestTag := ""
+	var latest semVersion
+	for _, tag := range strings.Split(output, "\n") {
+		tag = strings.TrimSpace(tag)
+		version, ok := parseSemver(tag)
+		if !ok {
+			continue
+		}
+		if latestTag == "" || latest.less(version) {
+			latest = version
+			latestTag = tag
+		}
+	}
+	return latestTag, nil
+}
+
+// SuggestVersionBump classifies the commits since the latest semver tag on the
+// current branch and suggests the next major, minor or patch version
+func (s *Service) SuggestVersionBump() (*types.VersionBump, error) {
+	return s.planVersionBump("")
+}
+
+// CreateVersionTag creates an annotated tag for the suggested version, or for an
+// explicit major/minor/patch bump, using the generated changelog as the tag message
+func (s *Service) CreateVersionTag(bump string) (*types.VersionBump, error) {
+	plan, err := s.planVersionBump(bump)
+	if err != nil {
+		return nil, err
+	}
+
+	if plan.Bump == "none" {
+		return nil, fmt.Errorf("no commits since %s", plan.CurrentTag)
+	}
+
+	if err := s.CreateTag(plan.NextTag, "", plan.Changelog, true); err != nil {
+		return nil, err
+	}
+	plan.Created = true
+	return plan, nil
+}
+
+// planVersionBump computes the version bump, using override instead of the
+// suggested bump when it is set
+func (s *Service) planVersionBump(override string) (*types.VersionBump, error) {
+	currentTag, err := s.GetLatestSemverTag()
+	if err != nil {
+		return nil, err
+	}
+
+	current := semVersion{Prefix: "v"}
+	revisionRange := "HEAD"
+	if currentTag != "" {
+		current, _ = parseSemver(currentTag)
+		revisionRange = currentTag + "..HEAD"
+	}
+
+	commits, err := s.getCommitsInRange(revisionRange)
+	if err != nil {
+		return nil, err
+	}
+
+	groups, breaking := groupReleaseNoteEntries(commits)
+	result := &types.VersionBump{
+		CurrentTag:     currentTag,
+		CurrentVersion: current.String(),
+		Bump:           "none",
+		Candidates:     make(map[string]string),
+		Commits:        make([]types.ReleaseNoteEntry, 0, len(commits)),
+	}
+
+	for _, kind := range []string{"major", "minor", "patch"} {
+		next := current.bump(kind)
+		result.Candidates[kind] = next.Prefix + next.String()
+	}
+
+	// Breaking changes bump major, features bump minor, anything else bumps patch
+	for _, group := range groups {
+		result.Commits = append(result.Commits, group.Entries...)
+		if group.Type == "feat" {
+			result.Bump = "minor"
+		} else if result.Bump == "none" {
+			result.Bump = "patch"
+		}
+	}
+	if len(breaking) > 0 {
+		result.Bump = "major"
+	}
+
+	if override != "" && len(commits) > 0 {
+		if _, ok := result.Candidates[override]; !ok {
+			return nil, fmt.Errorf("invalid bump: %s", override)
+		}
+		result.Bump = override
+	}
+
+	if result.Bump == "none" {
+		result.NextVersion = result.CurrentVersion
+		result.NextTag = currentTag
+		return result, nil
+	}
+
+	next := current.bump(result.Bump)
+	result.NextVersion = next.String()
+	result.NextTag = next.Prefix + next.String()
+	result.Changelog = renderReleaseNotesMarkdown(result.NextTag, currentTag, time.Now(), groups, breaking)
+	return result, nil
+}
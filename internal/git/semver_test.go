@@ -0,0 +1,74 @@
+package git
+
+import (
+	"sort"
+	"testing"
+)
+
+func TestSemverOrder(t *testing.T) {
+	// Ascending order from the semver specification, plus numeric identifiers of
+	// different lengths
+	ordered := []string{
+		"1.0.0-alpha",
+		"1.0.0-alpha.1",
+		"1.0.0-alpha.beta",
+		"1.0.0-beta",
+		"1.0.0-beta.2",
+		"1.0.0-beta.11",
+		"1.0.0-rc.1",
+		"1.0.0-rc.9",
+		"1.0.0-rc.10",
+		"1.0.0",
+		"1.0.1",
+		"1.1.0",
+		"2.0.0",
+	}
+	for i := range ordered {
+		for j := range ordered {
+			a, _ := parseSemver(ordered[i])
+			b, _ := parseSemver(ordered[j])
+			if got, want := a.less(b), i < j; got != want {
+				t.Errorf("%s < %s = %v; want %v", ordered[i], ordered[j], got, want)
+			}
+		}
+	}
+
+	shuffled := []string{"v1.0.0-rc.10", "v1.0.0-rc.9", "v1.0.0-rc.2"}
+	sort.Slice(shuffled, func(i, j int) bool {
+		a, _ := parseSemver(shuffled[i])
+		b, _ := parseSemver(shuffled[j])
+		return b.less(a)
+	})
+	if shuffled[0] != "v1.0.0-rc.10" {
+		t.Errorf("latest of rc.2, rc.9 and rc.10 is %s; want v1.0.0-rc.10", shuffled[0])
+	}
+}
+
+func TestSemverBump(t *testing.T) {
+	tests := []struct {
+		version string
+		kind    string
+		want    string
+	}{
+		{"1.2.3", "patch", "1.2.4"},
+		{"1.2.3", "minor", "1.3.0"},
+		{"1.2.3", "major", "2.0.0"},
+		{"1.2.3-rc.1", "patch", "1.2.3"},
+		{"1.2.3-rc.1", "minor", "1.3.0"},
+		{"1.2.3-rc.1", "major", "2.0.0"},
+		{"1.2.0-rc.1", "minor", "1.2.0"},
+		{"1.2.0-rc.1", "major", "2.0.0"},
+		{"2.0.0-rc.1", "patch", "2.0.0"},
+		{"2.0.0-rc.1", "minor", "2.0.0"},
+		{"2.0.0-rc.1", "major", "2.0.0"},
+	}
+	for _, tt := range tests {
+		v, ok := parseSemver(tt.version)
+		if !ok {
+			t.Fatalf("parseSemver(%q) failed", tt.version)
+		}
+		if got := v.bump(tt.kind).String(); got != tt.want {
+			t.Errorf("%s %s bump = %s; want %s", tt.version, tt.kind, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,294 @@
+package git
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/knoxai/gait/pkg/types"
+)
+
+// Operations run through git's sequencer
+const (
+	SequenceCherryPick = "cherry-pick"
+	SequenceRevert     = "revert"
+)
+
+// SequenceOptions selects the commits of a cherry-pick or revert and how they are applied
+type SequenceOptions struct {
+	Commits      []string `json:"commits"`      // hand-picked commits, applied in the given order
+	Range        string   `json:"range"`        // a revision range such as "main..feature"
+	Branch       string   `json:"branch"`       // branch to check out first; empty uses the current branch
+	RecordOrigin bool     `json:"recordOrigin"` // add a "(cherry picked from commit ...)" trailer (-x)
+	Mainline     int      `json:"mainline"`     // parent number to diff merge commits against (-m)
+	NoCommit     bool     `json:"noCommit"`     // apply the changes to the index without committing
+}
+
+// gitPath returns the path of a file inside the repository's git directory
+func (s *Service) gitPath(name string) (string, error) {
+	path, err := s.runGitCommand("rev-parse", "--git-path", name)
+	if err != nil {
+		return "", err
+	}
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(s.repoPath, path)
+	}
+	return path, nil
+}
+
+// gitPathExists reports whether a file exists inside the repository's git directory
+func (s *Service) gitPathExists(name string) bool {
+	path, err := s.gitPath(name)
+	if err != nil {
+		return false
+	}
+	_, err = os.Stat(path)
+	return err == nil
+}
+
+// resolveSequenceCommits expands the requested range and commits to full hashes in the
+// order they will be applied
+func (s *Service) resolveSequenceCommits(operation string, opts SequenceOptions) ([]string, error) {
+	commits := make([]string, 0)
+
+	if opts.Range != "" {
+		from, to, found := strings.Cut(opts.Range, "..")
+		if !found {
+			return nil, fmt.Errorf("invalid range %q: expected <from>..<to>", opts.Range)
+		}
+		// Either end may be left out, as in "main..", and a symmetric range has a third dot
+		for _, end := range []string{from, strings.TrimPrefix(to, ".")} {
+			if end == "" {
+				continue
+			}
+			if err := ValidateRevision(end); err != nil {
+				return nil, err
+			}
+		}
+		args := []string{"rev-list"}
+		if operation == SequenceCherryPick {
+			// Cherry-picks apply oldest first, reverts newest first
+			args = append(args, "--reverse")
+		}
+		output, err := s.runGitCommand(append(args, opts.Range, "--")...)
+		if err != nil {
+			return nil, fmt.Errorf("invalid range %q: %w", opts.Range, err)
+		}
+		if output != "" {
+			commits = append(commits, strings.Split(output, "\n")...)
+		}
+	}
+
+	for _, commit := range opts.Commits {
+		commit = strings.TrimSpace(commit)
+		if commit == "" {
+			continue
+		}
+		if err := ValidateRevision(commit); err != nil {
+			return nil, err
+		}
+		hash, err := s.runGitCommand("rev-parse", "--verify", "--quiet", commit+"^{commit}")
+		if err != nil {
+			return nil, fmt.Errorf("commit not found: %s", commit)
+		}
+		commits = append(commits, hash)
+	}
+
+	if len(commits) == 0 {
+		return nil, fmt.Errorf("no commits selected")
+	}
+	return commits, nil
+}
+
+// checkMainline ensures merge commits have a valid mainline parent selected
+func (s *Service) checkMainline(commits []string, mainline int) error {
+	for _, commit := range commits {
+		output, err := s.runGitCommand("rev-list", "--parents", "-n", "1", commit)
+		if err != nil {
+			return err
+		}
+		parents := len(strings.Fields(output)) - 1
+		if parents < 2 {
+			continue
+		}
+		if mainline == 0 {
+			return fmt.Errorf("commit %s is a merge; select the mainline parent (1-%d)", shortHash(commit), parents)
+		}
+		if mainline > parents {
+			return fmt.Errorf("commit %s has only %d parents; mainline %d is out of range", shortHash(commit), parents, mainline)
+		}
+	}
+	return nil
+}
+
+// CherryPick applies one or more commits onto the current or a chosen branch. When a
+// commit conflicts the sequence stops and the returned result lists the conflicts; it
+// is finished with ContinueSequence or undone with AbortSequence.
+func (s *Service) CherryPick(opts SequenceOptions) (*types.SequenceResult, error) {
+	return s.runSequence(SequenceCherryPick, opts)
+}
+
+// Revert reverts one or more commits on the current or a chosen branch; see CherryPick
+func (s *Service) Revert(opts SequenceOptions) (*types.SequenceResult, error) {
+	return s.runSequence(SequenceRevert, opts)
+}
+
+// runSequence runs a multi-commit cherry-pick or revert
+func (s *Service) runSequence(operation string, opts SequenceOptions) (*types.SequenceResult, error) {
+	if opts.Branch != "" {
+		if err := ValidateRefName(opts.Branch); err != nil {
+			return nil, err
+		}
+	}
+	if status := s.GetSequenceStatus(); status.InProgress {
+		return nil, fmt.Errorf("a %s is already in progress; continue or abort it first", status.Operation)
+	}
+
+	commits, err := s.resolveSequenceCommits(operation, opts)
+	if err != nil {
+		return nil, err
+	}
+	if opts.Mainline < 0 {
+		return nil, fmt.Errorf("mainline must be a parent number starting at 1")
+	}
+	if err := s.checkMainline(commits, opts.Mainline); err != nil {
+		return nil, err
+	}
+
+	if opts.Branch != "" {
+		current, _ := s.runGitCommand("symbolic-ref", "--short", "HEAD")
+		if current != opts.Branch {
+			if err := s.checkProtectedGaitFiles(opts.Branch); err != nil {
+				return nil, err
+			}
+			if _, err := s.runGitCommand("checkout", opts.Branch, "--"); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	args := []string{operation}
+	if opts.RecordOrigin && operation == SequenceCherryPick {
+		args = append(args, "-x")
+	}
+	if opts.Mainline > 0 {
+		args = append(args, "-m", fmt.Sprintf("%d", opts.Mainline))
+	}
+	if opts.NoCommit {
+		args = append(args, "--no-commit")
+	}
+	if operation == SequenceRevert && !opts.NoCommit {
+		args = append(args, "--no-edit")
+	}
+	args = append(args, commits...)
+
+	_, stderr, runErr := s.runGitCommandWithEnv([]string{"GIT_EDITOR=true"}, nil, args...)
+	s.invalidateBranchesCache()
+
+	result := s.GetSequenceStatus()
+	result.Operation = operation
+	result.Commits = commits
+	result.Output = strings.TrimSpace(stderr)
+	if runErr != nil && !result.InProgress {
+		return nil, runErr
+	}
+	return result, nil
+}
+
+// GetSequenceStatus returns the state of an interrupted cherry-pick or revert
+func (s *Service) GetSequenceStatus() *types.SequenceResult {
+	result := &types.SequenceResult{
+		Commits:       []string{},
+		ConflictFiles: []string{},
+		Remaining:     []string{},
+	}
+	result.Branch, _ = s.runGitCommand("symbolic-ref", "--short", "HEAD")
+
+	for _, head := range []struct{ file, operation string }{
+		{"CHERRY_PICK_HEAD", SequenceCherryPick},
+		{"REVERT_HEAD", SequenceRevert},
+	} {
+		if s.gitPathExists(head.file) {
+			result.Operation = head.operation
+			result.InProgress = true
+			result.CurrentCommit, _ = s.runGitCommand("rev-parse", head.file)
+			break
+		}
+	}
+
+	// The sequencer's todo list starts with the commit that stopped the sequence
+	if todoPath, err := s.gitPath("sequencer/todo"); err == nil {
+		if content, err := os.ReadFile(todoPath); err == nil {
+			for _, line := range strings.Split(string(content), "\n") {
+				fields := strings.Fields(line)
+				if len(fields) < 2 || strings.HasPrefix(fields[0], "#") {
+					continue
+				}
+				if result.Operation == "" {
+					result.Operation = SequenceCherryPick
+					if fields[0] == "revert" {
+						result.Operation = SequenceRevert
+					}
+				}
+				result.InProgress = true
+				result.Remaining = append(result.Remaining, fields[1])
+			}
+		}
+	}
+	if len(result.Remaining) > 0 && result.CurrentCommit != "" && strings.HasPrefix(result.CurrentCommit, result.Remaining[0]) {
+		result.Remaining = result.Remaining[1:]
+	}
+
+	if result.InProgress {
+		if output, err := s.runGitCommand("diff", "--name-only", "--diff-filter=U"); err == nil && output != "" {
+			result.ConflictFiles = strings.Split(output, "\n")
+		}
+	}
+	return result
+}
+
+// ContinueSequence resumes an interrupted cherry-pick or revert once its conflicts are
+// resolved and staged, returning the state it stops at next
+func (s *Service) ContinueSequence() (*types.SequenceResult, error) {
+	status := s.GetSequenceStatus()
+	if !status.InProgress {
+		return nil, fmt.Errorf("no cherry-pick or revert in progress")
+	}
+	if len(status.ConflictFiles) > 0 {
+		return nil, fmt.Errorf("resolve and stage the conflicted files first: %s", strings.Join(status.ConflictFiles, ", "))
+	}
+
+	_, stderr, runErr := s.runGitCommandWithEnv([]string{"GIT_EDITOR=true"}, nil, status.Operation, "--continue")
+	s.invalidateBranchesCache()
+
+	result := s.GetSequenceStatus()
+	if result.Operation == "" {
+		result.Operation = status.Operation
+	}
+	result.Output = strings.TrimSpace(stderr)
+	if runErr != nil && !result.InProgress {
+		return nil, runErr
+	}
+	return result, nil
+}
+
+// AbortSequence cancels an interrupted cherry-pick or revert and restores the branch
+// to where it was before the sequence started
+func (s *Service) AbortSequence() error {
+	status := s.GetSequenceStatus()
+	if !status.InProgress {
+		return fmt.Errorf("no cherry-pick or revert in progress")
+	}
+	_, err := s.runGitCommand(status.Operation, "--abort")
+	s.invalidateBranchesCache()
+	return err
+}
+
+// shortHash abbreviates a full commit hash for messages
+func shortHash(hash string) string {
+	if len(hash) > 7 {
+		return hash[:7]
+	}
+	return hash
+}
@@ -1,7 +1,11 @@
 package git
 
 import (
+	"bytes"
+	"context"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -17,6 +21,8 @@ import (
 // Service handles Git operations
 type Service struct {
 	repoPath string
+	gitDir   string          // set when the git directory is kept outside the working tree
+	ctx      context.Context // set by WithContext; git commands are killed when it ends
 	cache    *serviceCache
 }
 
@@ -27,9 +33,12 @@ type serviceCache struct {
 	tags     []types.Tag
 	remotes  []types.Remote
 	
-	branchesExpiry time.Time
-	tagsExpiry     time.Time
-	remotesExpiry  time.Time
+	signatures map[string]*types.SignatureStatus // verified signatures by commit hash, nil when unsigned
+
+	branchesExpiry   time.Time
+	tagsExpiry       time.Time
+	remotesExpiry    time.Time
+	signaturesExpiry time.Time
 	
 	cacheDuration time.Duration
 }
@@ -44,59 +53,146 @@ func NewService(repoPath string) *Service {
 	}
 }
 
+// WithContext returns a copy of the service whose git commands run under ctx, so that
+// they are killed when ctx is cancelled, such as when the client of a request goes
+// away. The copy shares the service's caches.
+func (s *Service) WithContext(ctx context.Context) *Service {
+	bound := *s
+	bound.ctx = ctx
+	return &bound
+}
+
+// Context returns the context the service's git commands run under
+func (s *Service) Context() context.Context {
+	if s.ctx == nil {
+		return context.Background()
+	}
+	return s.ctx
+}
+
 // GetRepoPath returns the repository path
 func (s *Service) GetRepoPath() string {
 	return s.repoPath
 }
 
+// commandWaitDelay is how long a killed git command gets to close its output before
+// waiting for it gives up, in case a child process such as ssh still holds it open
+const commandWaitDelay = 2 * time.Second
+
+// command returns a git command that runs in the repository under the service's
+// context and the command's timeout (see Timeouts), with env added to its environment.
+// The returned context is the one the command runs under; cancel must be called once
+// the command has finished.
+func (s *Service) command(timeout time.Duration, env []string, args ...string) (*exec.Cmd, context.Context, context.CancelFunc) {
+	ctx, cancel := withTimeout(s.Context(), args, timeout)
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = s.repoPath
+	cmd.Env = s.environ(env...)
+	cmd.WaitDelay = commandWaitDelay
+	return cmd, ctx, cancel
+}
+
 // runGitCommand executes a git command in the repository
 func (s *Service) runGitCommand(args ...string) (string, error) {
-	cmd := exec.Command("git", args...)
-	cmd.Dir = s.repoPath
+	cmd, ctx, cancel := s.command(0, nil, args...)
+	defer cancel()
 	output, err := cmd.CombinedOutput()
 	if err != nil {
-		return "", fmt.Errorf("git command failed: %v, output: %s", err, string(output))
+		return "", commandError(ctx, args, err, string(output))
 	}
 	return strings.TrimSpace(string(output)), nil
 }
 
-// runGitCommandWithTimeout executes a git command with timeout for better performance
+// runGitCommandWithInput executes a git command feeding input on stdin and returns
+// the untrimmed stdout along with stderr, for commands whose output must stay byte-exact
+func (s *Service) runGitCommandWithInput(input []byte, args ...string) ([]byte, string, error) {
+	return s.runGitCommandWithEnv(nil, input, args...)
+}
+
+// runGitCommandWithEnv is runGitCommandWithInput with extra environment variables
+func (s *Service) runGitCommandWithEnv(env []string, input []byte, args ...string) ([]byte, string, error) {
+	cmd, ctx, cancel := s.command(0, env, args...)
+	defer cancel()
+	if input != nil {
+		cmd.Stdin = bytes.NewReader(input)
+	}
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return stdout.Bytes(), stderr.String(), commandError(ctx, args, err, stderr.String())
+	}
+	return stdout.Bytes(), stderr.String(), nil
+}
+
+// runGitCommandToWriter executes a git command streaming its stdout to w
+func (s *Service) runGitCommandToWriter(w io.Writer, args ...string) error {
+	cmd, ctx, cancel := s.command(0, nil, args...)
+	defer cancel()
+	var stderr bytes.Buffer
+	cmd.Stdout = w
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return commandError(ctx, args, err, stderr.String())
+	}
+	return nil
+}
+
+// runGitCommandWithTimeout executes a git command that must finish within timeout,
+// unless the configured timeouts give its subcommand a different one
 func (s *Service) runGitCommandWithTimeout(timeout time.Duration, args ...string) (string, error) {
-	cmd := exec.Command("git", args...)
-	cmd.Dir = s.repoPath
-	
-	// Set a reasonable timeout to prevent hanging
-	done := make(chan error, 1)
-	var output []byte
-	var err error
-	
-	go func() {
-		output, err = cmd.Output()
-		done <- err
-	}()
-	
-	select {
-	case err := <-done:
-		if err != nil {
-			return "", fmt.Errorf("git command failed: %v", err)
-		}
-		return strings.TrimSpace(string(output)), nil
-	case <-time.After(timeout):
-		if cmd.Process != nil {
-			cmd.Process.Kill()
-		}
-		return "", fmt.Errorf("git command timed out after %v", timeout)
+	return s.runGitCommandWithTimeoutEnv(timeout, nil, args...)
+}
+
+// runGitCommandWithTimeoutEnv is runGitCommandWithTimeout with extra environment variables
+func (s *Service) runGitCommandWithTimeoutEnv(timeout time.Duration, env []string, args ...string) (string, error) {
+	cmd, ctx, cancel := s.command(timeout, env, args...)
+	defer cancel()
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		return "", commandError(ctx, args, err, stderr.String())
 	}
+	return strings.TrimSpace(string(output)), nil
 }
 
+// commitLogFormat is the git log format parsed into types.Commit
+const commitLogFormat = "%H|%h|%s|%an|%ae|%ad|%cd|%P"
+
+// signedCommitLogFormat adds the signature fields to commitLogFormat. They come last so
+// they can be read from the end of the line. Git verifies every signed commit it prints
+// with this format, so it is only used where signatures are needed.
+const signedCommitLogFormat = commitLogFormat + "|%G?|%GK|%GS"
+
 // GetCommits retrieves commit history
 func (s *Service) GetCommits(limit int, branch string, showAll bool) ([]types.Commit, error) {
 	return s.GetCommitsWithOffset(limit, 0, branch, showAll)
 }
 
-// GetCommitsWithOffset retrieves commit history with pagination support and optimizations
+// GetCommitsWithOffset retrieves commit history with pagination support and optimizations.
+// Signatures are verified only for the listed commits and are then cached.
 func (s *Service) GetCommitsWithOffset(limit int, offset int, branch string, showAll bool) ([]types.Commit, error) {
-	args := []string{"log", "--pretty=format:%H|%h|%s|%an|%ae|%ad|%cd|%P", "--date=iso"}
+	commits, err := s.listCommits(limit, offset, branch, showAll, false)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.attachSignatures(commits); err != nil {
+		return nil, err
+	}
+	return commits, nil
+}
+
+// listCommits retrieves a page of commit history, with each commit's verified signature
+// when withSignatures is set
+func (s *Service) listCommits(limit int, offset int, branch string, showAll bool, withSignatures bool) ([]types.Commit, error) {
+	args, env := []string{}, []string(nil)
+	format := commitLogFormat
+	if withSignatures {
+		args, env = s.verificationOptions()
+		format = signedCommitLogFormat
+	}
+	args = append(args, "log", "--pretty=format:"+format, "--date=iso")
 	
 	// Add skip parameter for offset
 	if offset > 0 {
@@ -108,15 +204,21 @@ func (s *Service) GetCommitsWithOffset(limit int, offset int, branch string, sho
 	}
 	
 	if !showAll && branch != "" {
-		args = append(args, branch)
+		if err := ValidateRevision(branch); err != nil {
+			return nil, err
+		}
+		args = append(args, branch, "--")
 	} else if showAll {
 		args = append(args, "--all")
 	}
 
 	// Use timeout for better performance
-	output, err := s.runGitCommandWithTimeout(10*time.Second, args...)
+	output, err := s.runGitCommandWithTimeoutEnv(10*time.Second, env, args...)
 	if err != nil {
-		return []types.Commit{}, nil // Return empty array instead of nil
+		if !showAll && branch == "" && s.headIsUnborn() {
+			return []types.Commit{}, nil // a repository without commits has an empty history
+		}
+		return nil, err
 	}
 
 	lines := strings.Split(output, "\n")
@@ -159,6 +261,9 @@ func (s *Service) GetCommitsWithOffset(limit int, offset int, branch string, sho
 			Refs:       refs,
 			Stats:      types.CommitStats{}, // Initialize with zero values
 		}
+		if withSignatures {
+			commit.Signature = logSignatureFields(parts)
+		}
 
 		commits = append(commits, commit)
 	}
@@ -166,6 +271,13 @@ func (s *Service) GetCommitsWithOffset(limit int, offset int, branch string, sho
 	return commits, nil
 }
 
+// headIsUnborn reports whether HEAD names a branch that has no commits yet, as in a
+// newly created repository
+func (s *Service) headIsUnborn() bool {
+	_, err := s.runGitCommand("rev-parse", "--verify", "--quiet", "HEAD")
+	return err != nil && s.Context().Err() == nil
+}
+
 // GetBranches retrieves all branches with caching
 func (s *Service) GetBranches() ([]types.Branch, error) {
 	s.cache.mu.RLock()
@@ -177,40 +289,36 @@ func (s *Service) GetBranches() ([]types.Branch, error) {
 	}
 	s.cache.mu.RUnlock()
 
-	// Get local branches
-	output, err := s.runGitCommandWithTimeout(5*time.Second, "branch", "-v")
+	// Get local branches with their upstream tracking state
+	output, err := s.runGitCommandWithTimeout(5*time.Second, "for-each-ref", "refs/heads",
+		"--format=%(HEAD)|%(refname:short)|%(objectname:short)|%(upstream:short)|%(upstream:track,nobracket)|%(upstream:remotename)")
 	if err != nil {
-		return []types.Branch{}, nil // Return empty array instead of nil
+		return nil, err
 	}
 
 	branches := make([]types.Branch, 0)
+	lastFetch := s.getLastFetchTimes()
 
 	lines := strings.Split(output, "\n")
 	for _, line := range lines {
-		if line == "" {
+		parts := strings.Split(line, "|")
+		if len(parts) < 6 {
 			continue
 		}
 
-		line = strings.TrimSpace(line)
-		isCurrent := strings.HasPrefix(line, "*")
-		if isCurrent {
-			line = strings.TrimSpace(line[1:]) // Remove the *
+		branch := types.Branch{
+			Name:      parts[1],
+			Hash:      parts[2],
+			IsRemote:  false,
+			IsCurrent: parts[0] == "*",
+			Upstream:  parts[3],
 		}
-
-		parts := strings.Fields(line)
-		if len(parts) >= 2 {
-			name := parts[0]
-			hash := parts[1]
-
-			branch := types.Branch{
-				Name:      name,
-				Hash:      hash,
-				IsRemote:  false,
-				IsCurrent: isCurrent,
-			}
-
-			branches = append(branches, branch)
+		branch.Ahead, branch.Behind, branch.UpstreamGone = parseUpstreamTrack(parts[4])
+		if fetched, ok := lastFetch[parts[5]]; ok {
+			branch.LastFetch = &fetched
 		}
+
+		branches = append(branches, branch)
 	}
 
 	// Cache the result
@@ -243,7 +351,7 @@ func (s *Service) GetTags() ([]types.Tag, error) {
 
 	output, err := s.runGitCommandWithTimeout(5*time.Second, "tag", "-l", "--format=%(refname:short)|%(objectname:short)|%(objecttype)|%(subject)|%(taggername)|%(taggeremail)|%(taggerdate:iso)")
 	if err != nil {
-		return []types.Tag{}, nil // Return empty array instead of nil
+		return nil, err
 	}
 
 	if strings.TrimSpace(output) == "" {
@@ -307,7 +415,7 @@ func (s *Service) GetTags() ([]types.Tag, error) {
 func (s *Service) GetStashes() ([]types.Stash, error) {
 	output, err := s.runGitCommand("stash", "list", "--format=%gd|%gs|%gD|%gt")
 	if err != nil {
-		return []types.Stash{}, nil // Return empty array instead of nil
+		return nil, err
 	}
 
 	if strings.TrimSpace(output) == "" {
@@ -364,7 +472,7 @@ func (s *Service) GetRemotes() ([]types.Remote, error) {
 
 	output, err := s.runGitCommandWithTimeout(5*time.Second, "remote", "-v")
 	if err != nil {
-		return []types.Remote{}, nil // Return empty array instead of nil
+		return nil, err
 	}
 
 	if strings.TrimSpace(output) == "" {
@@ -434,7 +542,13 @@ func (s *Service) getCommitRefs(hash string) ([]string, error) {
 
 // CheckoutBranch switches to a different branch
 func (s *Service) CheckoutBranch(branch string) error {
-	_, err := s.runGitCommand("checkout", branch)
+	if err := ValidateRevision(branch); err != nil {
+		return err
+	}
+	if err := s.checkProtectedGaitFiles(branch); err != nil {
+		return err
+	}
+	_, err := s.runGitCommand("checkout", branch, "--")
 	if err == nil {
 		// Invalidate branches cache after successful checkout
 		s.invalidateBranchesCache()
@@ -444,10 +558,17 @@ func (s *Service) CheckoutBranch(branch string) error {
 
 // CreateBranch creates a new branch
 func (s *Service) CreateBranch(branchName string, startPoint string) error {
+	if err := ValidateRefName(branchName); err != nil {
+		return err
+	}
 	args := []string{"checkout", "-b", branchName}
 	if startPoint != "" {
+		if err := ValidateRevision(startPoint); err != nil {
+			return err
+		}
 		args = append(args, startPoint)
 	}
+	args = append(args, "--")
 	_, err := s.runGitCommand(args...)
 	if err == nil {
 		// Invalidate branches cache after successful branch creation
@@ -456,13 +577,19 @@ func (s *Service) CreateBranch(branchName string, startPoint string) error {
 	return err
 }
 
-// DeleteBranch deletes a branch
+// DeleteBranch deletes a branch unless it is protected
 func (s *Service) DeleteBranch(branchName string, force bool) error {
+	if err := ValidateRefName(branchName); err != nil {
+		return err
+	}
+	if err := s.checkProtected(OperationDelete, branchName, ""); err != nil {
+		return err
+	}
 	flag := "-d"
 	if force {
 		flag = "-D"
 	}
-	_, err := s.runGitCommand("branch", flag, branchName)
+	_, err := s.runGitCommand("branch", flag, "--", branchName)
 	if err == nil {
 		// Invalidate branches cache after successful branch deletion
 		s.invalidateBranchesCache()
@@ -472,11 +599,17 @@ func (s *Service) DeleteBranch(branchName string, force bool) error {
 
 // MergeBranch merges a branch into the current branch
 func (s *Service) MergeBranch(branchName string, noFastForward bool) error {
+	if err := ValidateRevision(branchName); err != nil {
+		return err
+	}
+	if err := s.checkProtectedGaitFiles(branchName); err != nil {
+		return err
+	}
 	args := []string{"merge"}
 	if noFastForward {
 		args = append(args, "--no-ff")
 	}
-	args = append(args, branchName)
+	args = append(args, "--", branchName)
 	_, err := s.runGitCommand(args...)
 	return err
 }
@@ -484,57 +617,30 @@ func (s *Service) MergeBranch(branchName string, noFastForward bool) error {
 // Fetch fetches from remote
 func (s *Service) Fetch(remote string, prune bool) error {
 	args := []string{"fetch"}
+	if prune {
+		args = append(args, "--prune")
+	}
 	if remote != "" {
-		args = append(args, remote)
+		if err := ValidateRemoteName(remote); err != nil {
+			return err
+		}
+		args = append(args, "--", remote)
 	} else {
 		args = append(args, "--all")
 	}
-	if prune {
-		args = append(args, "--prune")
-	}
-	_, err := s.runGitCommand(args...)
+	_, err := s.runRemoteCommand(remote, args...)
 	return err
 }
 
-// DiscoverRepositories finds Git repositories in a directory
-func (s *Service) DiscoverRepositories(rootPath string, maxDepth int) ([]types.Repository, error) {
-	var repos []types.Repository
-
-	err := filepath.WalkDir(rootPath, func(path string, d os.DirEntry, err error) error {
-		if err != nil {
-			return nil // Continue walking
-		}
-
-		// Check depth
-		relPath, _ := filepath.Rel(rootPath, path)
-		depth := strings.Count(relPath, string(filepath.Separator))
-		if depth > maxDepth {
-			return filepath.SkipDir
-		}
-
-		// Check if this is a .git directory
-		if d.IsDir() && d.Name() == ".git" {
-			repoPath := filepath.Dir(path)
-			repoName := filepath.Base(repoPath)
-
-			repos = append(repos, types.Repository{
-				Name: repoName,
-				Path: repoPath,
-			})
-
-			return filepath.SkipDir
-		}
-
-		return nil
-	})
-
-	return repos, err
-}
-
 // GetCommitDetails retrieves detailed information about a specific commit
 func (s *Service) GetCommitDetails(hash string) (*types.Commit, error) {
+	if err := ValidateRevision(hash); err != nil {
+		return nil, err
+	}
 	// Get basic commit info
-	output, err := s.runGitCommand("log", "--pretty=format:%H|%h|%s|%an|%ae|%ad|%cd|%P", "--date=iso", "-1", hash)
+	args, env := s.verificationOptions()
+	args = append(args, "log", "--pretty=format:"+signedCommitLogFormat, "--date=iso", "-1", hash, "--")
+	output, err := s.runGitCommandWithTimeoutEnv(10*time.Second, env, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -570,6 +676,7 @@ func (s *Service) GetCommitDetails(hash string) (*types.Commit, error) {
 		Parents:    parents,
 		Refs:       refs,
 		Stats:      types.CommitStats{},
+		Signature:  logSignatureFields(parts),
 	}
 
 	// Get file changes
@@ -584,7 +691,7 @@ func (s *Service) GetCommitDetails(hash string) (*types.Commit, error) {
 // getCommitFileChanges gets the file changes for a commit
 func (s *Service) getCommitFileChanges(hash string) ([]types.FileChange, error) {
 	// Get file status and stats
-	output, err := s.runGitCommand("show", "--numstat", "--format=", hash)
+	output, err := s.runGitCommand("show", "--numstat", "--format=", hash, "--")
 	if err != nil {
 		return []types.FileChange{}, nil
 	}
@@ -646,7 +753,7 @@ func (s *Service) getCommitFileChanges(hash string) ([]types.FileChange, error)
 
 	// If no changes found with numstat, fall back to name-status for renames/copies
 	if len(changes) == 0 {
-		statusOutput, err := s.runGitCommand("show", "--name-status", "--format=", hash)
+		statusOutput, err := s.runGitCommand("show", "--name-status", "--format=", hash, "--")
 		if err != nil {
 			return []types.FileChange{}, nil
 		}
@@ -683,13 +790,21 @@ func (s *Service) getCommitFileChanges(hash string) ([]types.FileChange, error)
 
 // GetFileDiff gets the diff for a specific file in a commit
 func (s *Service) GetFileDiff(hash, filePath string) (*types.FileDiff, error) {
+	filePath, err := CleanPath(filePath)
+	if err != nil {
+		return nil, err
+	}
+	if hash != "uncommitted" {
+		if err := ValidateRevision(hash); err != nil {
+			return nil, err
+		}
+	}
 	var output string
-	var err error
 	
 	if hash == "uncommitted" {
 		// For uncommitted changes, get the diff between HEAD and working directory
 		// This will show both staged and unstaged changes
-		output, err = s.runGitCommand("diff", "HEAD", "--", filePath)
+		output, err = s.runGitCommand("diff", "HEAD", "--", literalPathspec(filePath))
 		if err != nil {
 			return nil, err
 		}
@@ -697,7 +812,7 @@ func (s *Service) GetFileDiff(hash, filePath string) (*types.FileDiff, error) {
 		// If no diff found, the file might be untracked
 		if strings.TrimSpace(output) == "" {
 			// Check if file is untracked
-			statusOutput, statusErr := s.runGitCommand("status", "--porcelain", "--", filePath)
+			statusOutput, statusErr := s.runGitCommand("status", "--porcelain", "--", literalPathspec(filePath))
 			if statusErr == nil && strings.HasPrefix(strings.TrimSpace(statusOutput), "??") {
 				// File is untracked, show it as all additions
 				content, contentErr := s.GetFileContent("uncommitted", filePath)
@@ -713,10 +828,10 @@ func (s *Service) GetFileDiff(hash, filePath string) (*types.FileDiff, error) {
 		}
 	} else {
 		// For committed changes, get the diff between the commit and its parent
-		output, err = s.runGitCommand("diff", hash+"^", hash, "--", filePath)
+		output, err = s.runGitCommand("diff", hash+"^", hash, "--", literalPathspec(filePath))
 		if err != nil {
 			// If the commit has no parent (initial commit), compare with empty tree
-			output, err = s.runGitCommand("diff", "4b825dc642cb6eb9a060e54bf8d69288fbee4904", hash, "--", filePath)
+			output, err = s.runGitCommand("diff", "4b825dc642cb6eb9a060e54bf8d69288fbee4904", hash, "--", literalPathspec(filePath))
 			if err != nil {
 				return nil, err
 			}
@@ -731,7 +846,11 @@ func (s *Service) GetFileContent(hash, filePath string) ([]string, error) {
 	var args []string
 	if hash == "uncommitted" {
 		// For uncommitted changes, get the working directory version
-		content, err := os.ReadFile(filepath.Join(s.repoPath, filePath))
+		fullPath, err := s.resolvePath(filePath)
+		if err != nil {
+			return nil, err
+		}
+		content, err := os.ReadFile(fullPath)
 		if err != nil {
 			return nil, err
 		}
@@ -742,7 +861,14 @@ func (s *Service) GetFileContent(hash, filePath string) ([]string, error) {
 		}
 		return lines, nil
 	} else {
-		args = []string{"show", hash + ":" + filePath}
+		cleaned, err := CleanPath(filePath)
+		if err != nil {
+			return nil, err
+		}
+		if err := ValidateRevision(hash); err != nil {
+			return nil, err
+		}
+		args = []string{"show", hash + ":" + cleaned}
 	}
 
 	output, err := s.runGitCommand(args...)
@@ -760,7 +886,10 @@ func (s *Service) GetFileContent(hash, filePath string) ([]string, error) {
 
 // SaveFileContent saves content to a file in the working directory
 func (s *Service) SaveFileContent(filePath string, content []string) error {
-	fullPath := filepath.Join(s.repoPath, filePath)
+	fullPath, err := s.resolvePath(filePath)
+	if err != nil {
+		return err
+	}
 	
 	// Ensure the directory exists
 	dir := filepath.Dir(fullPath)
@@ -885,7 +1014,7 @@ func (s *Service) GetCommitsByTag(tagName string, limit int) ([]types.Commit, er
 
 // GetCommitsByTagWithOffset retrieves commits for a specific tag with pagination support
 func (s *Service) GetCommitsByTagWithOffset(tagName string, limit int, offset int) ([]types.Commit, error) {
-	args := []string{"log", "--pretty=format:%H|%h|%s|%an|%ae|%ad|%cd|%P", "--date=iso"}
+	args := []string{"log", "--pretty=format:" + commitLogFormat, "--date=iso"}
 	
 	// Add skip parameter for offset
 	if offset > 0 {
@@ -897,12 +1026,15 @@ func (s *Service) GetCommitsByTagWithOffset(tagName string, limit int, offset in
 	}
 	
 	// Add the tag reference
-	args = append(args, tagName)
+	if err := ValidateRefName(tagName); err != nil {
+		return nil, err
+	}
+	args = append(args, tagName, "--")
 
 	// Use timeout for better performance
 	output, err := s.runGitCommandWithTimeout(10*time.Second, args...)
 	if err != nil {
-		return []types.Commit{}, nil // Return empty array instead of nil
+		return nil, err
 	}
 
 	lines := strings.Split(output, "\n")
@@ -1032,7 +1164,7 @@ func (s *Service) getStashFileChanges(index int) ([]types.FileChange, error) {
 		parts := strings.Fields(line)
 		if len(parts) >= 2 {
 			status := parts[0]
-			path := parts[1]
+			path := parts[len(parts)-1] // the new path for renames
 
 			change := types.FileChange{
 				Path:   path,
@@ -1043,63 +1175,128 @@ func (s *Service) getStashFileChanges(index int) ([]types.FileChange, error) {
 		}
 	}
 
+	// git stash show leaves out untracked files saved with -u
+	for _, path := range s.stashUntrackedFiles(index) {
+		changes = append(changes, types.FileChange{Path: path, Status: "A"})
+	}
+
 	return changes, nil
 }
 
-// PullFromRemote pulls changes from a remote
+// PullFromRemote pulls changes from a remote. It fetches first and refuses fetched
+// commits that would replace protected .gait files before pulling them.
 func (s *Service) PullFromRemote(remote string, branch string) error {
-	args := []string{"pull"}
-	if remote != "" {
-		args = append(args, remote)
-		if branch != "" {
-			args = append(args, branch)
-		}
+	fetchArgs, err := remoteBranchArgs([]string{"fetch", "--update-head-ok"}, remote, branch)
+	if err != nil {
+		return err
 	}
-	_, err := s.runGitCommand(args...)
+	if _, err := s.runRemoteCommand(remote, fetchArgs...); err != nil {
+		return err
+	}
+	if err := s.checkFetchedGaitFiles(); err != nil {
+		return err
+	}
+
+	args, _ := remoteBranchArgs([]string{"pull"}, remote, branch)
+	_, err = s.runRemoteCommand(remote, args...)
 	return err
 }
 
-// PushToRemote pushes changes to a remote
+// PushToRemote pushes changes to a remote. Force pushes use --force-with-lease unless the
+// repository opted out, and are refused for protected branches.
 func (s *Service) PushToRemote(remote string, branch string, force bool) error {
+	if err := s.guardPush(remote, branch, force); err != nil {
+		return err
+	}
 	args := []string{"push"}
 	if force {
-		args = append(args, "--force")
+		args = append(args, s.forceFlag())
 	}
-	if remote != "" {
-		args = append(args, remote)
-		if branch != "" {
-			args = append(args, branch)
-		}
+	args, err := remoteBranchArgs(args, remote, branch)
+	if err != nil {
+		return err
 	}
-	_, err := s.runGitCommand(args...)
+	_, err = s.runRemoteCommand(remote, args...)
 	return err
 }
 
-// GetRemoteInfo gets detailed information about a remote
+// remoteBranchArgs appends the remote and the branch or refspec of a pull or push to
+// args, after --
+func remoteBranchArgs(args []string, remote, branch string) ([]string, error) {
+	if remote == "" {
+		return args, nil
+	}
+	if err := ValidateRemoteName(remote); err != nil {
+		return nil, err
+	}
+	args = append(args, "--", remote)
+	if branch != "" {
+		if err := ValidateRefspec(branch); err != nil {
+			return nil, err
+		}
+		args = append(args, branch)
+	}
+	return args, nil
+}
+
+// GetRemoteInfo gets detailed information about a remote, including its branches and which
+// of them local branches track. Only local data is shown when the remote cannot be reached.
 func (s *Service) GetRemoteInfo(remoteName string) (*types.Remote, error) {
-	output, err := s.runGitCommand("remote", "show", remoteName)
-	if err != nil {
+	if err := s.requireRemote(remoteName); err != nil {
 		return nil, err
 	}
 
+	output, err := s.runRemoteCommand(remoteName, "remote", "show", remoteName)
+	if err != nil {
+		var authErr *AuthError
+		if errors.As(err, &authErr) {
+			return nil, err
+		}
+		if output, err = s.runGitCommand("remote", "show", "-n", remoteName); err != nil {
+			return nil, err
+		}
+	}
+
 	remote := &types.Remote{Name: remoteName}
 	
+	inBranches := false
 	lines := strings.Split(output, "\n")
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
+	for _, raw := range lines {
+		line := strings.TrimSpace(raw)
+		if inBranches {
+			if strings.HasPrefix(raw, "    ") && line != "" {
+				remote.Branches = append(remote.Branches, parseRemoteShowBranch(remoteName, line))
+				continue
+			}
+			inBranches = false
+		}
+
 		if strings.HasPrefix(line, "Fetch URL:") {
 			remote.FetchURL = strings.TrimSpace(strings.TrimPrefix(line, "Fetch URL:"))
 		} else if strings.HasPrefix(line, "Push  URL:") {
 			remote.PushURL = strings.TrimSpace(strings.TrimPrefix(line, "Push  URL:"))
+		} else if strings.HasPrefix(line, "HEAD branch:") {
+			if head := strings.TrimSpace(strings.TrimPrefix(line, "HEAD branch:")); !strings.HasPrefix(head, "(") {
+				remote.HeadBranch = head
+			}
+		} else if strings.HasPrefix(line, "Remote branch") {
+			inBranches = true
 		}
 	}
 
+	remote.FetchRefspecs, _ = s.GetRemoteRefspecs(remoteName)
+	s.markLocallyTracked(remoteName, remote.Branches)
 	return remote, nil
 }
 
 // RenameBranch renames a branch
 func (s *Service) RenameBranch(oldName string, newName string) error {
-	_, err := s.runGitCommand("branch", "-m", oldName, newName)
+	for _, name := range []string{oldName, newName} {
+		if err := ValidateRefName(name); err != nil {
+			return err
+		}
+	}
+	_, err := s.runGitCommand("branch", "-m", "--", oldName, newName)
 	if err == nil {
 		s.invalidateBranchesCache()
 	}
@@ -1108,23 +1305,42 @@ func (s *Service) RenameBranch(oldName string, newName string) error {
 
 // CherryPickCommit cherry picks a commit
 func (s *Service) CherryPickCommit(commitHash string) error {
-	_, err := s.runGitCommand("cherry-pick", commitHash)
+	if err := ValidateRevision(commitHash); err != nil {
+		return err
+	}
+	_, err := s.runGitCommand("cherry-pick", "--", commitHash)
 	return err
 }
 
 // RevertCommit reverts a commit
 func (s *Service) RevertCommit(commitHash string, noCommit bool) error {
+	if err := ValidateRevision(commitHash); err != nil {
+		return err
+	}
 	args := []string{"revert"}
 	if noCommit {
 		args = append(args, "--no-commit")
 	}
-	args = append(args, commitHash)
+	args = append(args, "--", commitHash)
 	_, err := s.runGitCommand(args...)
 	return err
 }
 
-// ResetBranch resets the current branch to a specific commit
+// ResetBranch resets the current branch to a specific commit. Hard resets of protected
+// branches are refused.
 func (s *Service) ResetBranch(commitHash string, resetType string) error {
+	if err := ValidateRevision(commitHash); err != nil {
+		return err
+	}
+	if err := s.checkProtectedGaitFiles(commitHash); err != nil {
+		return err
+	}
+	if resetType == "hard" {
+		current, _ := s.runGitCommand("symbolic-ref", "--short", "HEAD")
+		if err := s.checkProtected(OperationHardReset, current, ""); err != nil {
+			return err
+		}
+	}
 	args := []string{"reset"}
 	switch resetType {
 	case "soft":
@@ -1136,30 +1352,73 @@ func (s *Service) ResetBranch(commitHash string, resetType string) error {
 	default:
 		args = append(args, "--mixed") // default to mixed
 	}
-	args = append(args, commitHash)
+	args = append(args, commitHash, "--")
 	_, err := s.runGitCommand(args...)
 	return err
 }
 
 // RebaseBranch rebases the current branch onto another branch
 func (s *Service) RebaseBranch(targetBranch string, interactive bool) error {
+	if err := ValidateRevision(targetBranch); err != nil {
+		return err
+	}
+	if err := s.checkProtectedGaitFiles(targetBranch); err != nil {
+		return err
+	}
 	args := []string{"rebase"}
 	if interactive {
 		args = append(args, "-i")
 	}
-	args = append(args, targetBranch)
+	args = append(args, "--", targetBranch)
 	_, err := s.runGitCommand(args...)
 	return err
 }
 
 // CreateTag creates a new tag
 func (s *Service) CreateTag(tagName string, commitHash string, message string, annotated bool) error {
+	if err := validateTagTarget(tagName, commitHash); err != nil {
+		return err
+	}
 	args := []string{"tag"}
 	if annotated && message != "" {
-		args = append(args, "-a", tagName, "-m", message)
+		args = append(args, "-a", "-m", message)
+	}
+	args = append(args, "--", tagName)
+	if commitHash != "" {
+		args = append(args, commitHash)
+	}
+	_, err := s.runGitCommand(args...)
+	if err == nil {
+		s.invalidateTagsCache()
+	}
+	return err
+}
+
+// CreateSignedTag creates a signed annotated tag using GPG or SSH signing.
+// An empty signingKey uses the configured user.signingkey.
+func (s *Service) CreateSignedTag(tagName string, commitHash string, message string, signingKey string, format string) error {
+	if err := validateTagTarget(tagName, commitHash); err != nil {
+		return err
+	}
+	args := []string{}
+	switch format {
+	case "", "gpg", "openpgp":
+	case "ssh", "x509":
+		args = append(args, "-c", "gpg.format="+format)
+	default:
+		return fmt.Errorf("unsupported signature format: %s", format)
+	}
+
+	if message == "" {
+		message = tagName
+	}
+	args = append(args, "tag")
+	if signingKey != "" {
+		args = append(args, "-u", signingKey)
 	} else {
-		args = append(args, tagName)
+		args = append(args, "-s")
 	}
+	args = append(args, "-m", message, "--", tagName)
 	if commitHash != "" {
 		args = append(args, commitHash)
 	}
@@ -1170,9 +1429,24 @@ func (s *Service) CreateTag(tagName string, commitHash string, message string, a
 	return err
 }
 
+// validateTagTarget checks the name of a new tag and the commit it tags, which may be
+// empty for HEAD
+func validateTagTarget(tagName, commitHash string) error {
+	if err := ValidateRefName(tagName); err != nil {
+		return err
+	}
+	if commitHash != "" {
+		return ValidateRevision(commitHash)
+	}
+	return nil
+}
+
 // DeleteTag deletes a tag
 func (s *Service) DeleteTag(tagName string) error {
-	_, err := s.runGitCommand("tag", "-d", tagName)
+	if err := ValidateRefName(tagName); err != nil {
+		return err
+	}
+	_, err := s.runGitCommand("tag", "-d", "--", tagName)
 	if err == nil {
 		s.invalidateTagsCache()
 	}
@@ -1181,20 +1455,32 @@ func (s *Service) DeleteTag(tagName string) error {
 
 // PushTag pushes a tag to remote
 func (s *Service) PushTag(remote string, tagName string) error {
-	args := []string{"push", remote, tagName}
-	_, err := s.runGitCommand(args...)
+	if err := ValidateRemoteName(remote); err != nil {
+		return err
+	}
+	if err := ValidateRefName(tagName); err != nil {
+		return err
+	}
+	args := []string{"push", "--", remote, "refs/tags/" + tagName}
+	_, err := s.runRemoteCommand(remote, args...)
 	return err
 }
 
 // PushAllTags pushes all tags to remote
 func (s *Service) PushAllTags(remote string) error {
-	args := []string{"push", remote, "--tags"}
-	_, err := s.runGitCommand(args...)
+	if err := ValidateRemoteName(remote); err != nil {
+		return err
+	}
+	args := []string{"push", "--tags", "--", remote}
+	_, err := s.runRemoteCommand(remote, args...)
 	return err
 }
 
 // GetAnnotatedTagDetails gets detailed information about an annotated tag
 func (s *Service) GetAnnotatedTagDetails(tagName string) (*types.Tag, error) {
+	if err := ValidateRefName(tagName); err != nil {
+		return nil, err
+	}
 	// First check if it's an annotated tag
 	output, err := s.runGitCommand("cat-file", "-t", tagName)
 	if err != nil {
@@ -1255,8 +1541,12 @@ func (s *Service) GetAnnotatedTagDetails(tagName string) (*types.Tag, error) {
 		}
 	}
 	
-	tag.Message = strings.Join(messageLines, "\n")
-	tag.Message = strings.TrimSpace(tag.Message)
+	tag.Message = stripSignature(strings.Join(messageLines, "\n"))
+	
+	// Attach signature verification for signed tags
+	if signature, err := s.VerifyTag(tagName); err == nil && signature.Signed {
+		tag.Signature = signature
+	}
 	
 	// Get the tag's own hash
 	hashOutput, err := s.runGitCommand("rev-parse", tagName)
@@ -1269,19 +1559,14 @@ func (s *Service) GetAnnotatedTagDetails(tagName string) (*types.Tag, error) {
 
 // CreateStash creates a new stash
 func (s *Service) CreateStash(message string, includeUntracked bool) error {
-	args := []string{"stash", "push"}
-	if includeUntracked {
-		args = append(args, "-u")
-	}
-	if message != "" {
-		args = append(args, "-m", message)
-	}
-	_, err := s.runGitCommand(args...)
-	return err
+	return s.CreateStashWithOptions(StashOptions{Message: message, IncludeUntracked: includeUntracked})
 }
 
 // CreateBranchFromStash creates a new branch from a stash
 func (s *Service) CreateBranchFromStash(branchName string, stashIndex int) error {
+	if err := ValidateRefName(branchName); err != nil {
+		return err
+	}
 	_, err := s.runGitCommand("stash", "branch", branchName, fmt.Sprintf("stash@{%d}", stashIndex))
 	if err == nil {
 		s.invalidateBranchesCache()
@@ -1289,9 +1574,10 @@ func (s *Service) CreateBranchFromStash(branchName string, stashIndex int) error
 	return err
 }
 
-// CleanWorkingDirectory cleans untracked files from working directory
+// CleanWorkingDirectory cleans untracked files from working directory, always keeping
+// the .gait directory
 func (s *Service) CleanWorkingDirectory(dryRun bool, includeDirectories bool) (string, error) {
-	args := []string{"clean"}
+	args := []string{"clean", "-e", gaitCleanPattern}
 	if dryRun {
 		args = append(args, "-n")
 	} else {
@@ -1433,47 +1719,74 @@ func (s *Service) GetUncommittedChanges() ([]types.FileChange, error) {
 
 // StageFile stages a file for commit
 func (s *Service) StageFile(filePath string) error {
-	if filePath == "" {
-		return fmt.Errorf("file path cannot be empty")
+	filePath, err := CleanPath(filePath)
+	if err != nil {
+		return err
 	}
-	_, err := s.runGitCommand("add", "--", filePath)
+	_, err = s.runGitCommand("add", "--", literalPathspec(filePath))
 	if err != nil {
-		return fmt.Errorf("failed to stage file %s: %v", filePath, err)
+		return fmt.Errorf("failed to stage file %s: %w", filePath, err)
 	}
 	return nil
 }
 
 // UnstageFile unstages a file
 func (s *Service) UnstageFile(filePath string) error {
-	if filePath == "" {
-		return fmt.Errorf("file path cannot be empty")
+	filePath, err := CleanPath(filePath)
+	if err != nil {
+		return err
 	}
-	_, err := s.runGitCommand("reset", "HEAD", "--", filePath)
+	_, err = s.runGitCommand("reset", "HEAD", "--", literalPathspec(filePath))
 	if err != nil {
-		return fmt.Errorf("failed to unstage file %s: %v", filePath, err)
+		return fmt.Errorf("failed to unstage file %s: %w", filePath, err)
 	}
 	return nil
 }
 
 // DiscardFileChanges discards changes to a file
 func (s *Service) DiscardFileChanges(filePath string) error {
-	if filePath == "" {
-		return fmt.Errorf("file path cannot be empty")
+	filePath, err := CleanPath(filePath)
+	if err != nil {
+		return err
 	}
-	_, err := s.runGitCommand("checkout", "HEAD", "--", filePath)
+	_, err = s.runGitCommand("checkout", "HEAD", "--", literalPathspec(filePath))
 	if err != nil {
-		return fmt.Errorf("failed to discard changes for file %s: %v", filePath, err)
+		return fmt.Errorf("failed to discard changes for file %s: %w", filePath, err)
 	}
 	return nil
 }
 
 // CreateCommit creates a new commit with the given message and returns the commit hash
 func (s *Service) CreateCommit(message string) (string, error) {
+	return s.CreateCommitWithOptions(message, CommitOptions{})
+}
+
+// CreateCommitWithOptions validates the message against the commit lint policy,
+// creates the commit and returns its hash. A *CommitLintError is returned for
+// policy violations unless the policy allows overrides and SkipLint is set.
+func (s *Service) CreateCommitWithOptions(message string, opts CommitOptions) (string, error) {
 	if message == "" {
 		return "", fmt.Errorf("commit message cannot be empty")
 	}
+
+	config, err := s.LoadCommitLintConfig()
+	if err != nil {
+		return "", err
+	}
+	if config.Enabled && !(opts.SkipLint && config.AllowOverride) {
+		if violations := LintCommitMessage(config, message, opts.Signoff); len(violations) > 0 {
+			return "", &CommitLintError{Violations: violations, Overridable: config.AllowOverride}
+		}
+	}
 	
-	_, err := s.runGitCommand("commit", "-m", message)
+	args := []string{"commit", "-m", message}
+	if opts.Amend {
+		args = append(args, "--amend")
+	}
+	if opts.Signoff {
+		args = append(args, "--signoff")
+	}
+	_, err = s.runGitCommand(args...)
 	if err != nil {
 		return "", err
 	}
@@ -1481,7 +1794,7 @@ func (s *Service) CreateCommit(message string) (string, error) {
 	// Get the hash of the newly created commit
 	commitHash, err := s.runGitCommand("rev-parse", "HEAD")
 	if err != nil {
-		return "", fmt.Errorf("failed to get commit hash: %v", err)
+		return "", fmt.Errorf("failed to get commit hash: %w", err)
 	}
 	
 	return strings.TrimSpace(commitHash), nil
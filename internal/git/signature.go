@@ -0,0 +1,460 @@
+package git
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/knoxai/gait/pkg/types"
+)
+
+var (
+	gpgStatusRegex    = regexp.MustCompile(`^\[GNUPG:\] (\S+)(?: (\S+))?(?: (.*))?$`)
+	sshGoodSigRegex   = regexp.MustCompile(`^Good "git" signature(?: for (\S+))? with (\S+) key (\S+)`)
+	sshSignatureBlock = "-----BEGIN SSH SIGNATURE-----"
+	pgpSignatureBlock = "-----BEGIN PGP SIGNATURE-----"
+)
+
+// signatureConfigFile holds the repository's verification settings, relative to the repository root
+const signatureConfigFile = ".gait/signatures.json"
+
+// SignatureFilters lists the values accepted by GetCommitsWithSignatureFilter
+var SignatureFilters = []string{"signed", "unsigned", "verified", "unverified"}
+
+// signatureFilterTimeout bounds how long GetCommitsWithSignatureFilter scans history for
+// matching commits, since a filter that few commits match would otherwise verify every
+// signature in the repository on each page request
+const signatureFilterTimeout = 30 * time.Second
+
+// signatureCacheDuration is how long verified signatures are reused by the commit list.
+// Changes made to the trust stores outside GAIT show up once it passes.
+const signatureCacheDuration = 5 * time.Minute
+
+// maxCachedSignatures bounds the signature cache, which is emptied when it is full
+const maxCachedSignatures = 10000
+
+// SignatureConfig points signature verification at repository-local trust stores.
+// Paths are relative to the repository root and are ignored when they do not exist.
+type SignatureConfig struct {
+	AllowedSignersFile string `json:"allowedSignersFile"` // SSH allowed signers file
+	GPGHome            string `json:"gpgHome"`            // GnuPG home directory holding the trusted keyring
+}
+
+// DefaultSignatureConfig returns the verification settings used when a repository has no config
+func DefaultSignatureConfig() *SignatureConfig {
+	return &SignatureConfig{
+		AllowedSignersFile: ".gait/allowed_signers",
+		GPGHome:            ".gait/gnupg",
+	}
+}
+
+// LoadSignatureConfig reads the repository's signature config, falling back to defaults
+func (s *Service) LoadSignatureConfig() (*SignatureConfig, error) {
+	config := DefaultSignatureConfig()
+
+	data, err := os.ReadFile(filepath.Join(s.repoPath, signatureConfigFile))
+	if os.IsNotExist(err) {
+		return config, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(data, config); err != nil {
+		return nil, fmt.Errorf("invalid signature config: %v", err)
+	}
+	return config, nil
+}
+
+// SaveSignatureConfig writes the repository's signature config
+func (s *Service) SaveSignatureConfig(config *SignatureConfig) error {
+	path := filepath.Join(s.repoPath, signatureConfigFile)
+	if err := s.prepareGaitDir(); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(config, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return err
+	}
+	s.invalidateSignaturesCache()
+	return nil
+}
+
+// verificationOptions returns the git config arguments and environment that point
+// signature checks at the configured allowed signers file and GnuPG home
+func (s *Service) verificationOptions() ([]string, []string) {
+	config, err := s.LoadSignatureConfig()
+	if err != nil {
+		config = DefaultSignatureConfig()
+	}
+
+	args := make([]string, 0, 2)
+	env := make([]string, 0, 1)
+
+	if path := s.resolveRepoFile(config.AllowedSignersFile); path != "" {
+		args = append(args, "-c", "gpg.ssh.allowedSignersFile="+path)
+	}
+	if path := s.resolveRepoFile(config.GPGHome); path != "" {
+		env = append(env, "GNUPGHOME="+path)
+	}
+	return args, env
+}
+
+// resolveRepoFile returns the absolute path of a repository-relative file, or "" if it does not exist
+func (s *Service) resolveRepoFile(path string) string {
+	if path == "" {
+		return ""
+	}
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(s.repoPath, path)
+	}
+	if _, err := os.Stat(path); err != nil {
+		return ""
+	}
+	if abs, err := filepath.Abs(path); err == nil {
+		return abs
+	}
+	return path
+}
+
+// VerifyCommit runs git verify-commit and reports the signature status of a commit
+func (s *Service) VerifyCommit(hash string) (*types.SignatureStatus, error) {
+	if hash == "" {
+		return nil, fmt.Errorf("commit hash cannot be empty")
+	}
+	if err := ValidateRevision(hash); err != nil {
+		return nil, err
+	}
+
+	content, err := s.runGitCommand("cat-file", "commit", hash)
+	if err != nil {
+		return nil, fmt.Errorf("commit not found: %s", hash)
+	}
+
+	status := &types.SignatureStatus{Status: "none"}
+	switch {
+	case strings.Contains(content, pgpSignatureBlock):
+		status.Format = "gpg"
+	case strings.Contains(content, sshSignatureBlock):
+		status.Format = "ssh"
+	default:
+		return status, nil
+	}
+	status.Signed = true
+
+	args, env := s.verificationOptions()
+	args = append(args, "verify-commit", "--raw", hash)
+	_, output, verifyErr := s.runGitCommandWithEnv(env, nil, args...)
+	parseVerifyOutput(status, output, verifyErr == nil)
+	return status, nil
+}
+
+// GetCommitsWithSignatureFilter retrieves commit history keeping only commits that match
+// a signature filter (see SignatureFilters). Offset counts matching commits.
+func (s *Service) GetCommitsWithSignatureFilter(limit int, offset int, branch string, showAll bool, filter string) ([]types.Commit, error) {
+	if filter == "" {
+		return s.GetCommitsWithOffset(limit, offset, branch, showAll)
+	}
+
+	known := false
+	for _, f := range SignatureFilters {
+		if f == filter {
+			known = true
+			break
+		}
+	}
+	if !known {
+		return nil, fmt.Errorf("unsupported signature filter: %s", filter)
+	}
+
+	batchSize := limit * 4
+	if batchSize < 200 {
+		batchSize = 200
+	}
+
+	ctx, cancel := context.WithTimeout(s.Context(), signatureFilterTimeout)
+	defer cancel()
+	scan := s.WithContext(ctx)
+
+	matched := make([]types.Commit, 0, limit)
+	skipped := 0
+	for scanned := 0; ; scanned += batchSize {
+		batch, err := scan.listCommits(batchSize, scanned, branch, showAll, true)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, commit := range batch {
+			if !matchesSignatureFilter(commit.Signature, filter) {
+				continue
+			}
+			if skipped < offset {
+				skipped++
+				continue
+			}
+			matched = append(matched, commit)
+			if limit > 0 && len(matched) == limit {
+				return matched, nil
+			}
+		}
+
+		if len(batch) < batchSize {
+			return matched, nil
+		}
+	}
+}
+
+// matchesSignatureFilter reports whether a commit's signature satisfies filter
+func matchesSignatureFilter(signature *types.SignatureStatus, filter string) bool {
+	signed := signature != nil && signature.Signed
+	switch filter {
+	case "signed":
+		return signed
+	case "unsigned":
+		return !signed
+	case "verified":
+		return signed && signature.Verified
+	case "unverified":
+		return !signed || !signature.Verified
+	}
+	return true
+}
+
+// parseLogSignature builds a signature status from the %G?, %GK and %GS log placeholders.
+// Unsigned commits yield nil.
+func parseLogSignature(code string, key string, signer string) *types.SignatureStatus {
+	if code == "" || code == "N" {
+		return nil
+	}
+
+	status := &types.SignatureStatus{
+		Signed: true,
+		Key:    key,
+		Signer: signer,
+		Format: "gpg",
+	}
+	if strings.HasPrefix(key, "SHA256:") {
+		status.Format = "ssh"
+	}
+
+	switch code {
+	case "G":
+		status.Status = "good"
+		status.Verified = true
+	case "U":
+		status.Status = "untrusted"
+	case "B":
+		status.Status = "bad"
+	case "X", "Y":
+		status.Status = "expired"
+	case "R":
+		status.Status = "revoked"
+	default: // "E": the signature could not be checked, usually a missing key
+		status.Status = "unknown-key"
+	}
+	return status
+}
+
+// attachSignatures sets the signature of each listed commit. Commits whose signature is
+// not cached are verified together by a single git log over just those commits.
+func (s *Service) attachSignatures(commits []types.Commit) error {
+	missing := s.cachedSignatures(commits)
+	if len(missing) == 0 {
+		return nil
+	}
+
+	args, env := s.verificationOptions()
+	args = append(args, "log", "--no-walk=unsorted", "--stdin", "--pretty=format:%H|%G?|%GK|%GS")
+	output, _, err := s.runGitCommandWithEnv(env, []byte(strings.Join(missing, "\n")+"\n"), args...)
+	if err != nil {
+		return err
+	}
+
+	verified := make(map[string]*types.SignatureStatus, len(missing))
+	for _, line := range strings.Split(string(output), "\n") {
+		parts := strings.SplitN(line, "|", 4)
+		if len(parts) < 4 {
+			continue
+		}
+		verified[parts[0]] = parseLogSignature(parts[1], parts[2], parts[3])
+	}
+	for i := range commits {
+		if status, ok := verified[commits[i].Hash]; ok {
+			commits[i].Signature = status
+		}
+	}
+	s.cacheSignatures(verified)
+	return nil
+}
+
+// cachedSignatures sets the signatures of commits found in the cache and returns the
+// hashes of the others
+func (s *Service) cachedSignatures(commits []types.Commit) []string {
+	s.cache.mu.RLock()
+	defer s.cache.mu.RUnlock()
+
+	fresh := time.Now().Before(s.cache.signaturesExpiry)
+	missing := make([]string, 0)
+	for i := range commits {
+		if status, ok := s.cache.signatures[commits[i].Hash]; ok && fresh {
+			commits[i].Signature = status
+			continue
+		}
+		missing = append(missing, commits[i].Hash)
+	}
+	return missing
+}
+
+// cacheSignatures adds verified signatures to the cache, starting a new one when the
+// cache has expired or is full
+func (s *Service) cacheSignatures(verified map[string]*types.SignatureStatus) {
+	s.cache.mu.Lock()
+	defer s.cache.mu.Unlock()
+
+	if s.cache.signatures == nil || time.Now().After(s.cache.signaturesExpiry) ||
+		len(s.cache.signatures)+len(verified) > maxCachedSignatures {
+		s.cache.signatures = make(map[string]*types.SignatureStatus, len(verified))
+		s.cache.signaturesExpiry = time.Now().Add(signatureCacheDuration)
+	}
+	for hash, status := range verified {
+		s.cache.signatures[hash] = status
+	}
+}
+
+// invalidateSignaturesCache drops cached signatures, whose verification depends on the
+// signature config
+func (s *Service) invalidateSignaturesCache() {
+	s.cache.mu.Lock()
+	s.cache.signatures = nil
+	s.cache.mu.Unlock()
+}
+
+// logSignatureFields extracts the trailing %G?|%GK|%GS fields of a signedCommitLogFormat line
+func logSignatureFields(parts []string) *types.SignatureStatus {
+	if len(parts) < 11 {
+		return nil
+	}
+	n := len(parts)
+	return parseLogSignature(parts[n-3], parts[n-2], parts[n-1])
+}
+
+// VerifyTag runs git verify-tag and reports the signature status of a tag
+func (s *Service) VerifyTag(tagName string) (*types.SignatureStatus, error) {
+	if tagName == "" {
+		return nil, fmt.Errorf("tag name cannot be empty")
+	}
+	if err := ValidateRefName(tagName); err != nil {
+		return nil, err
+	}
+
+	// Read the raw tag object to know whether there is a signature at all
+	objectType, err := s.runGitCommand("cat-file", "-t", tagName)
+	if err != nil {
+		return nil, err
+	}
+	if objectType != "tag" {
+		return &types.SignatureStatus{Status: "none"}, nil
+	}
+	content, err := s.runGitCommand("cat-file", "-p", tagName)
+	if err != nil {
+		return nil, err
+	}
+
+	status := &types.SignatureStatus{Status: "none"}
+	switch {
+	case strings.Contains(content, pgpSignatureBlock):
+		status.Format = "gpg"
+	case strings.Contains(content, sshSignatureBlock):
+		status.Format = "ssh"
+	default:
+		return status, nil
+	}
+	status.Signed = true
+
+	// verify-tag exits non-zero for anything but a good signature; the output still says why
+	args, env := s.verificationOptions()
+	args = append(args, "verify-tag", "--raw", tagName)
+	_, output, verifyErr := s.runGitCommandWithEnv(env, nil, args...)
+	parseVerifyOutput(status, output, verifyErr == nil)
+	return status, nil
+}
+
+// parseVerifyOutput fills a signature status from git verify-tag/verify-commit --raw output
+func parseVerifyOutput(status *types.SignatureStatus, output string, ok bool) {
+	status.Output = strings.TrimSpace(output)
+	status.Status = "unknown-key"
+
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+
+		if matches := sshGoodSigRegex.FindStringSubmatch(line); len(matches) == 4 {
+			status.Format = "ssh"
+			status.Signer = matches[1]
+			status.Key = matches[3]
+			status.Status = "good"
+			continue
+		}
+		if line == "No principal matched." || strings.Contains(line, "allowedSignersFile needs to be configured") {
+			status.Status = "unknown-key"
+			continue
+		}
+
+		matches := gpgStatusRegex.FindStringSubmatch(line)
+		if len(matches) < 2 {
+			continue
+		}
+		status.Format = "gpg"
+		switch matches[1] {
+		case "GOODSIG":
+			status.Status = "good"
+			status.Key = matches[2]
+			status.Signer = matches[3]
+		case "BADSIG":
+			status.Status = "bad"
+			status.Key = matches[2]
+			status.Signer = matches[3]
+		case "EXPSIG", "EXPKEYSIG":
+			status.Status = "expired"
+			status.Key = matches[2]
+			status.Signer = matches[3]
+		case "REVKEYSIG":
+			status.Status = "revoked"
+			status.Key = matches[2]
+			status.Signer = matches[3]
+		case "ERRSIG", "NO_PUBKEY":
+			status.Status = "unknown-key"
+			if status.Key == "" {
+				status.Key = matches[2]
+			}
+		case "TRUST_UNDEFINED", "TRUST_NEVER":
+			if status.Status == "good" {
+				status.Status = "untrusted"
+			}
+		}
+	}
+
+	status.Verified = ok && status.Status == "good"
+	if !ok && status.Status == "good" {
+		// git rejected a cryptographically good signature, e.g. because of trust policy
+		status.Status = "untrusted"
+	}
+}
+
+// stripSignature removes a trailing GPG or SSH signature block from a tag message
+func stripSignature(message string) string {
+	for _, marker := range []string{pgpSignatureBlock, sshSignatureBlock} {
+		if idx := strings.Index(message, marker); idx >= 0 {
+			message = message[:idx]
+		}
+	}
+	return strings.TrimSpace(message)
+}
@@ -0,0 +1,177 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/knoxai/gait/pkg/types"
+)
+
+// cloneFilterRegex matches the partial clone filters clones may ask for
+var cloneFilterRegex = regexp.MustCompile(`^(blob:none|tree:0|blob:limit=[0-9]+[kmg]?)$`)
+
+// cloneArgs returns the git clone flags for opts
+func cloneArgs(opts types.CloneOptions) ([]string, error) {
+	args := make([]string, 0)
+	if opts.Branch != "" {
+		if err := ValidateRefName(opts.Branch); err != nil {
+			return nil, err
+		}
+		args = append(args, "--branch", opts.Branch)
+	}
+	if opts.SingleBranch {
+		args = append(args, "--single-branch")
+	}
+	if opts.Depth < 0 {
+		return nil, fmt.Errorf("depth cannot be negative")
+	}
+	if opts.Depth > 0 {
+		args = append(args, "--depth", strconv.Itoa(opts.Depth))
+	}
+	if opts.Filter != "" {
+		if !cloneFilterRegex.MatchString(opts.Filter) {
+			return nil, fmt.Errorf("unsupported filter %q: expected blob:none, tree:0 or blob:limit=<size>", opts.Filter)
+		}
+		args = append(args, "--filter="+opts.Filter)
+	}
+	if len(opts.Sparse) > 0 {
+		args = append(args, "--sparse")
+	}
+	return args, nil
+}
+
+// GetSparseCheckout returns the sparse-checkout state of the working tree
+func (s *Service) GetSparseCheckout() (*types.SparseCheckout, error) {
+	sparse := &types.SparseCheckout{Patterns: []string{}}
+	enabled, _ := s.runGitCommand("config", "--bool", "core.sparseCheckout")
+	if enabled != "true" {
+		return sparse, nil
+	}
+	sparse.Enabled = true
+	cone, _ := s.runGitCommand("config", "--bool", "core.sparseCheckoutCone")
+	sparse.Cone = cone == "true"
+
+	output, err := s.runGitCommand("sparse-checkout", "list")
+	if err != nil {
+		return nil, err
+	}
+	for _, line := range strings.Split(output, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			sparse.Patterns = append(sparse.Patterns, line)
+		}
+	}
+	return sparse, nil
+}
+
+// SetSparseCheckout enables sparse checkout with exactly patterns checked out. In cone
+// mode the patterns are directories; otherwise they are .gitignore-style patterns.
+func (s *Service) SetSparseCheckout(patterns []string, cone bool) (*types.SparseCheckout, error) {
+	if len(patterns) == 0 {
+		return nil, fmt.Errorf("at least one pattern is required")
+	}
+	mode := "--no-cone"
+	if cone {
+		mode = "--cone"
+	}
+	if _, _, err := s.runGitCommandWithInput(sparseInput(patterns), "sparse-checkout", "set", mode, "--stdin"); err != nil {
+		return nil, err
+	}
+	return s.GetSparseCheckout()
+}
+
+// AddSparseCheckout adds patterns to an enabled sparse checkout
+func (s *Service) AddSparseCheckout(patterns []string) (*types.SparseCheckout, error) {
+	if len(patterns) == 0 {
+		return nil, fmt.Errorf("at least one pattern is required")
+	}
+	if _, _, err := s.runGitCommandWithInput(sparseInput(patterns), "sparse-checkout", "add", "--stdin"); err != nil {
+		return nil, err
+	}
+	return s.GetSparseCheckout()
+}
+
+// DisableSparseCheckout checks out the whole tree again
+func (s *Service) DisableSparseCheckout() (*types.SparseCheckout, error) {
+	if _, err := s.runGitCommand("sparse-checkout", "disable"); err != nil {
+		return nil, err
+	}
+	return s.GetSparseCheckout()
+}
+
+// sparseInput returns patterns one per line, as sparse-checkout --stdin reads them
+func sparseInput(patterns []string) []byte {
+	var lines []string
+	for _, pattern := range patterns {
+		if pattern = strings.TrimSpace(pattern); pattern != "" {
+			lines = append(lines, pattern)
+		}
+	}
+	return []byte(strings.Join(lines, "\n") + "\n")
+}
+
+// GetCloneInfo reports whether the repository is shallow, partial, single-branch or
+// sparse
+func (s *Service) GetCloneInfo() (*types.CloneInfo, error) {
+	shallow, err := s.runGitCommand("rev-parse", "--is-shallow-repository")
+	if err != nil {
+		return nil, err
+	}
+	info := &types.CloneInfo{Shallow: shallow == "true"}
+	if info.Shallow {
+		if path, err := s.gitPath("shallow"); err == nil {
+			if data, err := os.ReadFile(path); err == nil {
+				info.ShallowCommits = len(strings.Fields(string(data)))
+			}
+		}
+	}
+	info.Filter, _ = s.runGitCommand("config", "remote.origin.partialclonefilter")
+	if refspecs, err := s.runGitCommand("config", "--get-all", "remote.origin.fetch"); err == nil {
+		info.SingleBranch = refspecs != "" && !strings.Contains(refspecs, "*")
+	}
+
+	sparse, err := s.GetSparseCheckout()
+	if err != nil {
+		return nil, err
+	}
+	info.SparseCheckout = *sparse
+	return info, nil
+}
+
+// DeepenWithProgress fetches more history into a shallow clone from remote (origin when
+// empty): depth more commits, or all of it when unshallow is set
+func (s *Service) DeepenWithProgress(ctx context.Context, remote string, depth int, unshallow bool, progress ProgressFunc) (*types.RemoteOperationResult, error) {
+	s = s.WithContext(ctx)
+	if remote == "" {
+		remote = "origin"
+	}
+	if err := ValidateRemoteName(remote); err != nil {
+		return nil, err
+	}
+	if shallow, err := s.runGitCommand("rev-parse", "--is-shallow-repository"); err != nil {
+		return nil, err
+	} else if shallow != "true" {
+		return nil, fmt.Errorf("repository is not a shallow clone")
+	}
+
+	args := []string{"fetch", "--progress"}
+	switch {
+	case unshallow:
+		args = append(args, "--unshallow")
+	case depth > 0:
+		args = append(args, "--deepen="+strconv.Itoa(depth))
+	default:
+		return nil, fmt.Errorf("depth must be positive unless unshallowing")
+	}
+	args = append(args, "--", remote)
+
+	_, stderr, err := runGitWithProgress(ctx, s.repoPath, s.environ(s.remoteAuthEnv(remote)...), progress, args...)
+	err = classifyRemoteError(remote, err)
+	result := &types.RemoteOperationResult{Operation: "deepen", Remote: remote, Output: strings.TrimSpace(stderr)}
+	result.Updated, result.Rejected = parseRefUpdates(stderr, false)
+	s.invalidateBranchesCache()
+	return result, err
+}
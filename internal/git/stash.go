@@ -0,0 +1,150 @@
+package git
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/knoxai/gait/pkg/types"
+)
+
+// StashOptions controls what git stash push saves
+type StashOptions struct {
+	Message          string   `json:"message"`
+	IncludeUntracked bool     `json:"includeUntracked"`
+	KeepIndex        bool     `json:"keepIndex"` // leave staged changes in the index and working tree
+	Staged           bool     `json:"staged"`    // stash only the staged changes
+	Paths            []string `json:"paths"`     // limit the stash to these paths
+}
+
+// CreateStashWithOptions creates a new stash, optionally of selected paths only
+func (s *Service) CreateStashWithOptions(opts StashOptions) error {
+	if opts.Staged && (opts.KeepIndex || opts.IncludeUntracked) {
+		return fmt.Errorf("staged stashes cannot keep the index or include untracked files")
+	}
+
+	args := []string{"stash", "push"}
+	if opts.IncludeUntracked {
+		args = append(args, "-u")
+	}
+	if opts.KeepIndex {
+		args = append(args, "--keep-index")
+	}
+	if opts.Staged {
+		args = append(args, "--staged")
+	}
+	if opts.Message != "" {
+		args = append(args, "-m", opts.Message)
+	}
+
+	paths := make([]string, 0, len(opts.Paths))
+	for _, path := range opts.Paths {
+		if path = strings.TrimSpace(path); path != "" {
+			cleaned, err := CleanPath(path)
+			if err != nil {
+				return err
+			}
+			paths = append(paths, literalPathspec(cleaned))
+		}
+	}
+	if len(paths) > 0 {
+		args = append(append(args, "--"), paths...)
+	}
+
+	_, err := s.runGitCommand(args...)
+	return err
+}
+
+// stashUntrackedFiles returns the untracked files saved in a stash, which git keeps in
+// the stash commit's third parent
+func (s *Service) stashUntrackedFiles(index int) []string {
+	output, err := s.runGitCommand("ls-tree", "-r", "--name-only", fmt.Sprintf("stash@{%d}^3", index))
+	if err != nil || output == "" {
+		return nil
+	}
+	return strings.Split(output, "\n")
+}
+
+// isStashUntrackedFile reports whether path was saved as an untracked file in a stash
+func (s *Service) isStashUntrackedFile(index int, path string) bool {
+	_, err := s.runGitCommand("cat-file", "-e", fmt.Sprintf("stash@{%d}^3:%s", index, path))
+	return err == nil
+}
+
+// GetStashFileDiff returns the diff of a single file in a stash against the commit the
+// stash was created on
+func (s *Service) GetStashFileDiff(index int, path string) (*types.FileDiff, error) {
+	if path == "" {
+		return nil, fmt.Errorf("file path is required")
+	}
+	path, err := CleanPath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	stashRef := fmt.Sprintf("stash@{%d}", index)
+	if _, err := s.runGitCommand("rev-parse", "--verify", "--quiet", stashRef); err != nil {
+		return nil, fmt.Errorf("stash not found: %s", stashRef)
+	}
+
+	if s.isStashUntrackedFile(index, path) {
+		// The untracked files commit has no parent, so this diffs against the empty tree
+		return s.GetFileDiff(stashRef+"^3", path)
+	}
+	return s.GetFileDiff(stashRef, path)
+}
+
+// ApplyStashFile applies the stashed changes of a single file to the working tree,
+// leaving the stash in place. Without overwrite the changes are applied as a patch and
+// fail if they conflict with local changes; with overwrite the stashed version of the
+// file replaces the working tree copy.
+func (s *Service) ApplyStashFile(index int, path string, overwrite bool) error {
+	if path == "" {
+		return fmt.Errorf("file path is required")
+	}
+	path, err := CleanPath(path)
+	if err != nil {
+		return err
+	}
+
+	stashRef := fmt.Sprintf("stash@{%d}", index)
+	if _, err := s.runGitCommand("rev-parse", "--verify", "--quiet", stashRef); err != nil {
+		return fmt.Errorf("stash not found: %s", stashRef)
+	}
+
+	if s.isStashUntrackedFile(index, path) {
+		target, err := s.resolvePath(path)
+		if err != nil {
+			return err
+		}
+		if _, err := os.Stat(target); err == nil && !overwrite {
+			return fmt.Errorf("%s already exists; overwrite it to apply the stashed file", path)
+		}
+		content, _, err := s.runGitCommandWithInput(nil, "show", stashRef+"^3:"+path)
+		if err != nil {
+			return err
+		}
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return err
+		}
+		return os.WriteFile(target, content, 0644)
+	}
+
+	if overwrite {
+		_, err := s.runGitCommand("restore", "--source="+stashRef, "--worktree", "--", literalPathspec(path))
+		return err
+	}
+
+	patch, _, err := s.runGitCommandWithInput(nil, "diff", "--binary", stashRef+"^1", stashRef, "--", literalPathspec(path))
+	if err != nil {
+		return err
+	}
+	if len(strings.TrimSpace(string(patch))) == 0 {
+		return fmt.Errorf("stash %s has no changes to %s", stashRef, path)
+	}
+	if _, stderr, err := s.runGitCommandWithInput(patch, "apply", "-"); err != nil {
+		return fmt.Errorf("stashed changes to %s do not apply cleanly; overwrite the file instead: %s", path, strings.TrimSpace(stderr))
+	}
+	return nil
+}
@@ -0,0 +1,186 @@
+package git
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/knoxai/gait/pkg/types"
+)
+
+// parseUpstreamTrack parses %(upstream:track,nobracket) output such as
+// "ahead 1, behind 2" or "gone"
+func parseUpstreamTrack(track string) (ahead int, behind int, gone bool) {
+	if track == "gone" {
+		return 0, 0, true
+	}
+	for _, part := range strings.Split(track, ",") {
+		fields := strings.Fields(part)
+		if len(fields) != 2 {
+			continue
+		}
+		count, err := strconv.Atoi(fields[1])
+		if err != nil {
+			continue
+		}
+		switch fields[0] {
+		case "ahead":
+			ahead = count
+		case "behind":
+			behind = count
+		}
+	}
+	return ahead, behind, false
+}
+
+// getLastFetchTimes returns, per remote name, when FETCH_HEAD was last written by a
+// fetch from that remote. Remotes missing from FETCH_HEAD are left out.
+func (s *Service) getLastFetchTimes() map[string]time.Time {
+	times := make(map[string]time.Time)
+
+	fetchHead, err := s.gitPath("FETCH_HEAD")
+	if err != nil {
+		return times
+	}
+
+	info, err := os.Stat(fetchHead)
+	if err != nil {
+		return times
+	}
+	content, err := os.ReadFile(fetchHead)
+	if err != nil {
+		return times
+	}
+
+	urls, err := s.runGitCommand("config", "--get-regexp", `^remote\..*\.url$`)
+	if err != nil {
+		return times
+	}
+	for _, line := range strings.Split(urls, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			continue
+		}
+		name := strings.TrimSuffix(strings.TrimPrefix(fields[0], "remote."), ".url")
+		if strings.Contains(string(content), " of "+fields[1]) || strings.Contains(string(content), " of "+strings.TrimSuffix(fields[1], ".git")) {
+			times[name] = info.ModTime()
+		}
+	}
+	return times
+}
+
+// getAheadBehind counts the commits HEAD has that upstream lacks, and vice versa
+func (s *Service) getAheadBehind(upstream string) (int, int, error) {
+	output, err := s.runGitCommand("rev-list", "--left-right", "--count", "HEAD..."+upstream)
+	if err != nil {
+		return 0, 0, err
+	}
+	fields := strings.Fields(output)
+	if len(fields) != 2 {
+		return 0, 0, fmt.Errorf("unexpected rev-list output: %s", output)
+	}
+	ahead, _ := strconv.Atoi(fields[0])
+	behind, _ := strconv.Atoi(fields[1])
+	return ahead, behind, nil
+}
+
+// SyncBranch brings the current branch in line with its upstream: it fetches,
+// pulls with rebase or fast-forward only, then pushes any local commits. Each
+// step is reported; a failed step stops the sync and skips the rest.
+func (s *Service) SyncBranch(mode string) (*types.SyncResult, error) {
+	if mode == "" {
+		mode = "ff-only"
+	}
+	if mode != "rebase" && mode != "ff-only" {
+		return nil, fmt.Errorf("unsupported sync mode: %s", mode)
+	}
+
+	branch, err := s.runGitCommand("rev-parse", "--abbrev-ref", "HEAD")
+	if err != nil || branch == "HEAD" {
+		return nil, fmt.Errorf("cannot sync a detached HEAD")
+	}
+
+	upstream, err := s.runGitCommand("rev-parse", "--abbrev-ref", "--symbolic-full-name", "@{upstream}")
+	if err != nil || upstream == "" {
+		return nil, fmt.Errorf("branch %s has no upstream", branch)
+	}
+	remote, _ := s.runGitCommand("config", "branch."+branch+".remote")
+	mergeRef, _ := s.runGitCommand("config", "branch."+branch+".merge")
+	if remote == "" || remote == "." || mergeRef == "" {
+		return nil, fmt.Errorf("branch %s does not track a remote branch", branch)
+	}
+
+	result := &types.SyncResult{
+		Branch:   branch,
+		Upstream: upstream,
+		Mode:     mode,
+		Steps:    make([]types.SyncStep, 0, 3),
+	}
+	defer s.invalidateBranchesCache()
+
+	// fail records a failed step and marks the remaining steps as skipped
+	fail := func(step string, err error, remaining ...string) (*types.SyncResult, error) {
+		result.Steps = append(result.Steps, types.SyncStep{Name: step, Status: "failed", Output: err.Error()})
+		for _, name := range remaining {
+			result.Steps = append(result.Steps, types.SyncStep{Name: name, Status: "skipped"})
+		}
+		result.Ahead, result.Behind, _ = s.getAheadBehind(upstream)
+		return result, nil
+	}
+
+	output, err := s.runRemoteCommand(remote, "fetch", remote)
+	if err != nil {
+		return fail("fetch", err, "pull", "push")
+	}
+	result.Steps = append(result.Steps, types.SyncStep{Name: "fetch", Status: "success", Output: output})
+
+	if _, err := s.runGitCommand("rev-parse", "--verify", "--quiet", upstream); err != nil {
+		return fail("pull", fmt.Errorf("upstream %s no longer exists on %s", upstream, remote), "push")
+	}
+
+	_, behind, err := s.getAheadBehind(upstream)
+	if err != nil {
+		return fail("pull", err, "push")
+	}
+	if behind == 0 {
+		result.Steps = append(result.Steps, types.SyncStep{Name: "pull", Status: "skipped", Output: "Already up to date"})
+	} else {
+		if err := s.checkProtectedGaitFiles(upstream); err != nil {
+			return fail("pull", err, "push")
+		}
+		// Integrate the upstream just fetched and checked, rather than pulling, which
+		// would fetch again
+		if mode == "rebase" {
+			output, err = s.runGitCommand("rebase", "--fork-point", upstream)
+		} else {
+			output, err = s.runGitCommand("merge", "--ff-only", upstream)
+		}
+		if err != nil {
+			if mode == "rebase" {
+				s.runGitCommand("rebase", "--abort")
+			}
+			return fail("pull", err, "push")
+		}
+		result.Steps = append(result.Steps, types.SyncStep{Name: "pull", Status: "success", Output: output})
+	}
+
+	ahead, _, err := s.getAheadBehind(upstream)
+	if err != nil {
+		return fail("push", err)
+	}
+	if ahead == 0 {
+		result.Steps = append(result.Steps, types.SyncStep{Name: "push", Status: "skipped", Output: "Nothing to push"})
+	} else {
+		output, err = s.runRemoteCommand(remote, "push", remote, "HEAD:"+mergeRef)
+		if err != nil {
+			return fail("push", err)
+		}
+		result.Steps = append(result.Steps, types.SyncStep{Name: "push", Status: "success", Output: output})
+	}
+
+	result.Ahead, result.Behind, _ = s.getAheadBehind(upstream)
+	result.Success = true
+	return result, nil
+}
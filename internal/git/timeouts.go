@@ -0,0 +1,120 @@
+package git
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// Duration is a time.Duration that reads and writes as a string such as "90s" in JSON
+type Duration time.Duration
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var value string
+	if err := json.Unmarshal(data, &value); err != nil {
+		return fmt.Errorf("durations are strings such as \"90s\" or \"10m\"")
+	}
+	parsed, err := time.ParseDuration(value)
+	if err != nil {
+		return err
+	}
+	if parsed < 0 {
+		return fmt.Errorf("duration cannot be negative: %s", value)
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+// Timeouts limits how long git commands may run. A command gets the timeout of its
+// subcommand (log, fetch, ...) if one is set, otherwise the timeout its caller asked
+// for, otherwise Default. Zero means no limit.
+type Timeouts struct {
+	Default  Duration            `json:"default"`
+	Commands map[string]Duration `json:"commands,omitempty"`
+}
+
+// DefaultTimeouts returns the timeouts used unless SetTimeouts replaces them: two
+// minutes for local commands, half an hour for those that talk to remotes and no limit
+// for bisect, whose runs last as long as the test command and can be cancelled
+func DefaultTimeouts() Timeouts {
+	remote := Duration(30 * time.Minute)
+	return Timeouts{
+		Default: Duration(2 * time.Minute),
+		Commands: map[string]Duration{
+			"bisect": 0,
+			"clone":  remote,
+			"fetch":  remote,
+			"pull":   remote,
+			"push":   remote,
+		},
+	}
+}
+
+var (
+	timeoutsMu sync.RWMutex
+	timeouts   = DefaultTimeouts()
+)
+
+// SetTimeouts replaces the timeouts of git commands. Subcommands it does not mention
+// keep their default timeouts.
+func SetTimeouts(t Timeouts) {
+	merged := DefaultTimeouts()
+	if t.Default != 0 {
+		merged.Default = t.Default
+	}
+	for command, timeout := range t.Commands {
+		merged.Commands[command] = timeout
+	}
+
+	timeoutsMu.Lock()
+	defer timeoutsMu.Unlock()
+	timeouts = merged
+}
+
+// LoadTimeouts reads timeouts from a JSON file such as
+// {"default": "2m", "commands": {"log": "30s", "fetch": "1h"}}; a missing file
+// returns nil
+func LoadTimeouts(path string) (*Timeouts, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var t Timeouts
+	if err := json.Unmarshal(data, &t); err != nil {
+		return nil, fmt.Errorf("invalid %s: %v", path, err)
+	}
+	return &t, nil
+}
+
+// timeoutFor returns the timeout of a git command, given the timeout its caller asked
+// for (zero for none)
+func timeoutFor(args []string, requested time.Duration) time.Duration {
+	timeoutsMu.RLock()
+	defer timeoutsMu.RUnlock()
+	if timeout, ok := timeouts.Commands[subcommand(args)]; ok {
+		return time.Duration(timeout)
+	}
+	if requested > 0 {
+		return requested
+	}
+	return time.Duration(timeouts.Default)
+}
+
+// withTimeout returns a context for a git command that ends with parent or when the
+// command's timeout passes
+func withTimeout(parent context.Context, args []string, requested time.Duration) (context.Context, context.CancelFunc) {
+	if timeout := timeoutFor(args, requested); timeout > 0 {
+		return context.WithTimeout(parent, timeout)
+	}
+	return context.WithCancel(parent)
+}
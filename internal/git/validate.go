@@ -0,0 +1,251 @@
+package git
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// InvalidInputError is returned when a path, ref or other argument from a request is
+// rejected before it reaches git or the file system
+type InvalidInputError struct {
+	Kind   string // path, ref, revision, refspec, remote or url
+	Value  string
+	Reason string
+}
+
+func (e *InvalidInputError) Error() string {
+	return fmt.Sprintf("invalid %s %q: %s", e.Kind, e.Value, e.Reason)
+}
+
+// ErrorCode identifies rejected input to API clients
+func (e *InvalidInputError) ErrorCode() string {
+	return "invalid_input"
+}
+
+func invalidInput(kind, value, reason string) error {
+	return &InvalidInputError{Kind: kind, Value: value, Reason: reason}
+}
+
+// hasControlChar reports whether s contains a NUL or another ASCII control character
+func hasControlChar(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < 0x20 || s[i] == 0x7f {
+			return true
+		}
+	}
+	return false
+}
+
+// CleanPath canonicalises a path relative to the working tree: separators become /,
+// . and .. elements are resolved and a leading ./ is dropped. Absolute paths, paths
+// that climb out of the working tree, paths into the .git directory and paths naming
+// the .gait directory or its credentials, branch protection and signer trust, which
+// only admins may change, are rejected.
+func CleanPath(p string) (string, error) {
+	if p == "" {
+		return "", invalidInput("path", p, "path is empty")
+	}
+	if hasControlChar(p) {
+		return "", invalidInput("path", p, "path contains control characters")
+	}
+	slashed := filepath.ToSlash(p)
+	if path.IsAbs(slashed) || filepath.IsAbs(p) || filepath.VolumeName(p) != "" {
+		return "", invalidInput("path", p, "path must be relative to the repository")
+	}
+	cleaned := path.Clean(slashed)
+	if cleaned == "." {
+		return "", invalidInput("path", p, "path names the repository root")
+	}
+	if cleaned == ".." || strings.HasPrefix(cleaned, "../") {
+		return "", invalidInput("path", p, "path is outside the repository")
+	}
+	elements := strings.Split(cleaned, "/")
+	for i, element := range elements {
+		if strings.EqualFold(element, ".git") {
+			return "", invalidInput("path", p, "path is inside the .git directory")
+		}
+		if strings.EqualFold(element, gaitDir) && (i == len(elements)-1 || isProtectedGaitEntry(elements[i+1])) {
+			return "", invalidInput("path", p, "path names protected GAIT settings in the .gait directory")
+		}
+	}
+	return cleaned, nil
+}
+
+// CleanPaths canonicalises each of paths with CleanPath
+func CleanPaths(paths []string) ([]string, error) {
+	cleaned := make([]string, 0, len(paths))
+	for _, p := range paths {
+		c, err := CleanPath(p)
+		if err != nil {
+			return nil, err
+		}
+		cleaned = append(cleaned, c)
+	}
+	return cleaned, nil
+}
+
+// resolvePath returns the file system path of a working tree path, after checking with
+// CleanPath that it stays inside the working tree and that no symlink along it, the
+// file itself included, leads out of the working tree. The file need not exist yet.
+func (s *Service) resolvePath(p string) (string, error) {
+	cleaned, err := CleanPath(p)
+	if err != nil {
+		return "", err
+	}
+	root, err := filepath.EvalSymlinks(s.repoPath)
+	if err != nil {
+		return "", fmt.Errorf("cannot resolve repository path: %v", err)
+	}
+	full := filepath.Join(s.repoPath, filepath.FromSlash(cleaned))
+
+	// Resolve the longest part of the path that exists; the rest is created as plain
+	// directories and files, so it cannot contain links
+	existing, rest := full, ""
+	for {
+		if _, err := os.Lstat(existing); err == nil {
+			break
+		}
+		parent := filepath.Dir(existing)
+		if parent == existing {
+			break
+		}
+		rest = filepath.Join(filepath.Base(existing), rest)
+		existing = parent
+	}
+	resolved, err := filepath.EvalSymlinks(existing)
+	if err != nil {
+		return "", invalidInput("path", p, "path cannot be resolved")
+	}
+	if !withinDir(root, resolved) {
+		return "", invalidInput("path", p, "path leads outside the repository through a symlink")
+	}
+	if rel, err := filepath.Rel(root, resolved); err == nil && rel != "." {
+		if _, err := CleanPath(filepath.Join(rel, rest)); err != nil {
+			return "", invalidInput("path", p, "path leads into a protected directory through a symlink")
+		}
+	}
+	return filepath.Join(resolved, rest), nil
+}
+
+// withinDir reports whether path is dir or below it; both must be clean and absolute
+func withinDir(dir, path string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) && !filepath.IsAbs(rel)
+}
+
+// literalPathspec marks a working tree path as a literal pathspec, so that git treats
+// glob characters and pathspec magic such as :(top) or :! in it as part of the name
+func literalPathspec(p string) string {
+	return ":(literal)" + p
+}
+
+// ValidateRefName checks a branch or tag name against the rules of
+// git check-ref-format --allow-onelevel, and rejects names that git would read as an
+// option
+func ValidateRefName(name string) error {
+	reason := refNameProblem(name)
+	if reason != "" {
+		return invalidInput("ref", name, reason)
+	}
+	return nil
+}
+
+func refNameProblem(name string) string {
+	switch {
+	case name == "":
+		return "name is empty"
+	case name == "@":
+		return "name cannot be @"
+	case strings.HasPrefix(name, "-"):
+		return "name cannot start with -"
+	case hasControlChar(name):
+		return "name contains control characters"
+	case strings.ContainsAny(name, " ~^:?*[\\"):
+		return "name cannot contain spaces or any of ~ ^ : ? * [ \\"
+	case strings.Contains(name, ".."):
+		return "name cannot contain .."
+	case strings.Contains(name, "@{"):
+		return "name cannot contain @{"
+	case strings.HasPrefix(name, "/") || strings.HasSuffix(name, "/") || strings.Contains(name, "//"):
+		return "name cannot start or end with / or contain //"
+	case strings.HasSuffix(name, "."):
+		return "name cannot end with ."
+	}
+	for _, component := range strings.Split(name, "/") {
+		if strings.HasPrefix(component, ".") {
+			return "name components cannot start with ."
+		}
+		if strings.HasSuffix(component, ".lock") {
+			return "name components cannot end with .lock"
+		}
+	}
+	return ""
+}
+
+// ValidateRevision checks an expression naming a commit, such as a hash, a ref name or
+// main~2, that is passed to git as an argument. Revision syntax is too rich to check
+// fully, so only what could change the meaning of the command is rejected.
+func ValidateRevision(rev string) error {
+	switch {
+	case rev == "":
+		return invalidInput("revision", rev, "revision is empty")
+	case strings.HasPrefix(rev, "-"):
+		return invalidInput("revision", rev, "revision cannot start with -")
+	case hasControlChar(rev):
+		return invalidInput("revision", rev, "revision contains control characters")
+	}
+	return nil
+}
+
+// ValidateRemoteName checks a remote name, which git requires to work as a ref name
+// component
+func ValidateRemoteName(name string) error {
+	if reason := refNameProblem(name); reason != "" {
+		return invalidInput("remote", name, reason)
+	}
+	return nil
+}
+
+// ValidateRefspec checks a push or fetch refspec of the form [+]<src>[:<dst>]. The
+// source may be any revision, or empty to delete <dst>; the destination must be a valid
+// ref name, where a single * stands for a pattern.
+func ValidateRefspec(spec string) error {
+	if spec == "" || spec == "+" {
+		return invalidInput("refspec", spec, "refspec is empty")
+	}
+	if strings.HasPrefix(spec, "-") {
+		return invalidInput("refspec", spec, "refspec cannot start with -")
+	}
+	if hasControlChar(spec) {
+		return invalidInput("refspec", spec, "refspec contains control characters")
+	}
+	src, dst := strings.TrimPrefix(spec, "+"), ""
+	if idx := strings.LastIndex(src, ":"); idx >= 0 {
+		src, dst = src[:idx], src[idx+1:]
+	}
+	if src != "" && ValidateRevision(src) != nil {
+		return invalidInput("refspec", spec, "source cannot start with -")
+	}
+	if dst != "" {
+		if reason := refNameProblem(strings.Replace(dst, "*", "x", 1)); reason != "" {
+			return invalidInput("refspec", spec, reason)
+		}
+	}
+	return nil
+}
+
+// ValidateRemoteURL checks a remote URL, rejecting values git would read as an option
+func ValidateRemoteURL(url string) error {
+	switch {
+	case url == "":
+		return invalidInput("url", url, "URL is empty")
+	case strings.HasPrefix(url, "-"):
+		return invalidInput("url", url, "URL cannot start with -")
+	case hasControlChar(url):
+		return invalidInput("url", url, "URL contains control characters")
+	}
+	return nil
+}
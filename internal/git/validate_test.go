@@ -0,0 +1,229 @@
+package git
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func TestCleanPath(t *testing.T) {
+	tests := []struct {
+		path    string
+		want    string
+		wantErr bool
+	}{
+		{path: "README.md", want: "README.md"},
+		{path: "./docs/guide.md", want: "docs/guide.md"},
+		{path: "docs/../src/main.go", want: "src/main.go"},
+		{path: ".github/workflows/ci.yml", want: ".github/workflows/ci.yml"},
+		{path: ".gitignore", want: ".gitignore"},
+		{path: "", wantErr: true},
+		{path: ".", wantErr: true},
+		{path: "..", wantErr: true},
+		{path: "../secret", wantErr: true},
+		{path: "docs/../../secret", wantErr: true},
+		{path: "/etc/passwd", wantErr: true},
+		{path: ".git/config", wantErr: true},
+		{path: ".git", wantErr: true},
+		{path: "sub/.git/hooks/pre-commit", wantErr: true},
+		{path: ".GIT/config", wantErr: true},
+		{path: "docs/../.git/config", wantErr: true},
+		{path: ".gait/commit-lint.json", want: ".gait/commit-lint.json"},
+		{path: ".gait", wantErr: true},
+		{path: ".gait/protection.json", wantErr: true},
+		{path: ".gait/credentials.enc", wantErr: true},
+		{path: ".gait/gnupg/pubring.kbx", wantErr: true},
+		{path: "sub/.Gait/allowed_signers", wantErr: true},
+		{path: "file\x00name", wantErr: true},
+		{path: "line\nbreak", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := CleanPath(tt.path)
+		if tt.wantErr {
+			var inputErr *InvalidInputError
+			if !errors.As(err, &inputErr) {
+				t.Errorf("CleanPath(%q) = %q, %v; want an InvalidInputError", tt.path, got, err)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("CleanPath(%q) = %q, %v; want %q", tt.path, got, err, tt.want)
+		}
+	}
+}
+
+func TestResolvePath(t *testing.T) {
+	repo := t.TempDir()
+	outside := t.TempDir()
+	mustWrite(t, filepath.Join(outside, "secret"), "secret")
+	mustWrite(t, filepath.Join(repo, "docs", "guide.md"), "guide")
+	mustWrite(t, filepath.Join(repo, ".git", "config"), "[core]")
+	mustWrite(t, filepath.Join(repo, ".gait", "protection.json"), "{}")
+	for link, target := range map[string]string{
+		"escape":      outside,
+		"secret-link": filepath.Join(outside, "secret"),
+		"docs-link":   filepath.Join(repo, "docs"),
+		"git-link":    filepath.Join(repo, ".git"),
+		"policy-link": filepath.Join(repo, ".gait", "protection.json"),
+	} {
+		if err := os.Symlink(target, filepath.Join(repo, link)); err != nil {
+			t.Skipf("symlinks are not available: %v", err)
+		}
+	}
+
+	s := NewService(repo)
+	tests := []struct {
+		path    string
+		wantErr bool
+	}{
+		{path: "docs/guide.md"},
+		{path: "docs/new/file.txt"},
+		{path: "docs-link/guide.md"},
+		{path: "escape", wantErr: true},
+		{path: "escape/secret", wantErr: true},
+		{path: "escape/new/file.txt", wantErr: true},
+		{path: "secret-link", wantErr: true},
+		{path: "../outside", wantErr: true},
+		{path: ".git/config", wantErr: true},
+		{path: "git-link/config", wantErr: true},
+		{path: "policy-link", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := s.resolvePath(tt.path)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("resolvePath(%q) = %q; want an error", tt.path, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("resolvePath(%q) failed: %v", tt.path, err)
+		}
+	}
+}
+
+func mustWrite(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// refNameTests are checked against ValidateRefName and, when git is installed,
+// against git check-ref-format itself
+var refNameTests = []struct {
+	name  string
+	valid bool
+}{
+	{"main", true},
+	{"feature/login", true},
+	{"release-1.2", true},
+	{"v1.0.0", true},
+	{"user@host", true},
+	{"", false},
+	{"@", false},
+	{"-delete", false},
+	{"--force", false},
+	{"a..b", false},
+	{"HEAD@{1}", false},
+	{"topic@{upstream}", false},
+	{"has space", false},
+	{"tilde~1", false},
+	{"caret^", false},
+	{"colon:ref", false},
+	{"question?", false},
+	{"star*", false},
+	{"bracket[", false},
+	{`back\slash`, false},
+	{"/leading", false},
+	{"trailing/", false},
+	{"double//slash", false},
+	{"ends.", false},
+	{".hidden", false},
+	{"feature/.hidden", false},
+	{"branch.lock", false},
+	{"feature/x.lock/y", false},
+	{"tab\tname", false},
+}
+
+func TestValidateRefName(t *testing.T) {
+	for _, tt := range refNameTests {
+		err := ValidateRefName(tt.name)
+		if (err == nil) != tt.valid {
+			t.Errorf("ValidateRefName(%q) = %v; want valid %v", tt.name, err, tt.valid)
+		}
+	}
+}
+
+func TestValidateRefNameMatchesGit(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	for _, tt := range refNameTests {
+		if tt.name == "" || tt.name[0] == '-' {
+			continue // git would read these as options, which is why they are rejected
+		}
+		gitValid := exec.Command("git", "check-ref-format", "--allow-onelevel", tt.name).Run() == nil
+		if gitValid != tt.valid {
+			t.Errorf("git check-ref-format says %q is valid: %v; the test expects %v", tt.name, gitValid, tt.valid)
+		}
+	}
+}
+
+func TestValidateRemoteName(t *testing.T) {
+	tests := []struct {
+		name  string
+		valid bool
+	}{
+		{"origin", true},
+		{"upstream", true},
+		{"fork-1", true},
+		{"", false},
+		{"-origin", false},
+		{"--upload-pack=touch /tmp/x", false},
+		{"has space", false},
+		{"a..b", false},
+		{".hidden", false},
+	}
+	for _, tt := range tests {
+		err := ValidateRemoteName(tt.name)
+		if (err == nil) != tt.valid {
+			t.Errorf("ValidateRemoteName(%q) = %v; want valid %v", tt.name, err, tt.valid)
+		}
+	}
+}
+
+func TestValidateRefspec(t *testing.T) {
+	tests := []struct {
+		spec  string
+		valid bool
+	}{
+		{"main", true},
+		{"main:main", true},
+		{"+main:refs/heads/main", true},
+		{"HEAD:refs/heads/feature", true},
+		{":refs/heads/old", true},
+		{"refs/heads/*:refs/remotes/origin/*", true},
+		{"", false},
+		{"+", false},
+		{"-f", false},
+		{"--force", false},
+		{"--receive-pack=touch /tmp/x", false},
+		{"+-f:main", false},
+		{"main:-f", false},
+		{"main:bad..name", false},
+		{"main:a*b*c", false},
+		{"main\n", false},
+	}
+	for _, tt := range tests {
+		err := ValidateRefspec(tt.spec)
+		if (err == nil) != tt.valid {
+			t.Errorf("ValidateRefspec(%q) = %v; want valid %v", tt.spec, err, tt.valid)
+		}
+	}
+}
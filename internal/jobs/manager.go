@@ -0,0 +1,238 @@
+package jobs
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/knoxai/gait/pkg/types"
+)
+
+// Job statuses
+const (
+	StatusRunning   = "running"
+	StatusSucceeded = "succeeded"
+	StatusFailed    = "failed"
+	StatusCancelled = "cancelled"
+)
+
+// retention is how long finished jobs are kept for clients to read their results
+const retention = time.Hour
+
+// Func is the body of a background job. It should stop when ctx is cancelled and
+// report progress through progress as it goes.
+type Func func(ctx context.Context, progress func(types.JobProgress)) (interface{}, error)
+
+// codedError is implemented by errors that carry a machine-readable code for clients,
+// such as git.AuthError
+type codedError interface {
+	error
+	ErrorCode() string
+}
+
+// Manager runs background jobs and fans their updates out to subscribers
+type Manager struct {
+	mu       sync.RWMutex
+	jobs     map[string]*job
+	onFinish []func(types.Job)
+}
+
+// job is the internal state of a background job
+type job struct {
+	info        types.Job
+	cancel      context.CancelFunc
+	subscribers map[chan types.Job]bool
+}
+
+// NewManager creates a new job manager
+func NewManager() *Manager {
+	return &Manager{jobs: make(map[string]*job)}
+}
+
+// Start runs fn in the background and returns the new job. repositoryID is the
+// repository the job runs in, or "" for jobs that run in none, such as clones.
+func (m *Manager) Start(jobType string, repositoryID string, target string, fn Func) types.Job {
+	ctx, cancel := context.WithCancel(context.Background())
+	j := &job{
+		info: types.Job{
+			ID:           newJobID(),
+			Type:         jobType,
+			RepositoryID: repositoryID,
+			Target:       target,
+			Status:       StatusRunning,
+			StartedAt:    time.Now(),
+		},
+		cancel:      cancel,
+		subscribers: make(map[chan types.Job]bool),
+	}
+
+	m.mu.Lock()
+	m.pruneLocked()
+	m.jobs[j.info.ID] = j
+	info := j.info
+	m.mu.Unlock()
+
+	go func() {
+		defer cancel()
+		result, err := fn(ctx, func(p types.JobProgress) {
+			m.update(j, func(info *types.Job) { info.Progress = &p })
+		})
+		m.finish(ctx, j, result, err)
+	}()
+
+	return info
+}
+
+// OnFinish registers hook to be called with each job once it has finished
+func (m *Manager) OnFinish(hook func(types.Job)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.onFinish = append(m.onFinish, hook)
+}
+
+// update applies change to a running job and notifies its subscribers
+func (m *Manager) update(j *job, change func(*types.Job)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	change(&j.info)
+	for ch := range j.subscribers {
+		select {
+		case ch <- j.info:
+		default:
+			// Slow subscriber; it will catch up with a later update
+		}
+	}
+}
+
+// finish records the outcome of a job and closes its subscriptions
+func (m *Manager) finish(ctx context.Context, j *job, result interface{}, err error) {
+	m.mu.Lock()
+	defer func() {
+		info, hooks := j.info, m.onFinish
+		m.mu.Unlock()
+		for _, hook := range hooks {
+			hook(info)
+		}
+	}()
+
+	now := time.Now()
+	j.info.FinishedAt = &now
+	j.info.Result = result
+	switch {
+	case errors.Is(ctx.Err(), context.Canceled):
+		j.info.Status = StatusCancelled
+		j.info.Error = "cancelled"
+	case err != nil:
+		j.info.Status = StatusFailed
+		j.info.Error = err.Error()
+		var coded codedError
+		if errors.As(err, &coded) {
+			j.info.ErrorCode = coded.ErrorCode()
+		}
+	default:
+		j.info.Status = StatusSucceeded
+	}
+
+	for ch := range j.subscribers {
+		close(ch)
+		delete(j.subscribers, ch)
+	}
+}
+
+// Get returns a job by ID
+func (m *Manager) Get(id string) (types.Job, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	j, ok := m.jobs[id]
+	if !ok {
+		return types.Job{}, false
+	}
+	return j.info, true
+}
+
+// List returns all known jobs, newest first
+func (m *Manager) List() []types.Job {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	jobs := make([]types.Job, 0, len(m.jobs))
+	for _, j := range m.jobs {
+		jobs = append(jobs, j.info)
+	}
+	sort.Slice(jobs, func(i, k int) bool {
+		return jobs[i].StartedAt.After(jobs[k].StartedAt)
+	})
+	return jobs
+}
+
+// Cancel stops a running job
+func (m *Manager) Cancel(id string) error {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	j, ok := m.jobs[id]
+	if !ok {
+		return fmt.Errorf("job not found: %s", id)
+	}
+	if j.info.Status != StatusRunning {
+		return fmt.Errorf("job %s is not running", id)
+	}
+	j.cancel()
+	return nil
+}
+
+// Subscribe returns a channel receiving a job's updates. The channel is closed when
+// the job finishes; the final state is then available from Get. The returned
+// function unsubscribes early.
+func (m *Manager) Subscribe(id string) (<-chan types.Job, func(), error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	j, ok := m.jobs[id]
+	if !ok {
+		return nil, nil, fmt.Errorf("job not found: %s", id)
+	}
+
+	ch := make(chan types.Job, 16)
+	if j.info.Status != StatusRunning {
+		close(ch)
+		return ch, func() {}, nil
+	}
+
+	j.subscribers[ch] = true
+	unsubscribe := func() {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		if j.subscribers[ch] {
+			delete(j.subscribers, ch)
+			close(ch)
+		}
+	}
+	return ch, unsubscribe, nil
+}
+
+// pruneLocked drops finished jobs older than the retention period
+func (m *Manager) pruneLocked() {
+	cutoff := time.Now().Add(-retention)
+	for id, j := range m.jobs {
+		if j.info.FinishedAt != nil && j.info.FinishedAt.Before(cutoff) {
+			delete(m.jobs, id)
+		}
+	}
+}
+
+// newJobID returns a random job identifier
+func newJobID() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Sprintf("%x", time.Now().UnixNano())
+	}
+	return hex.EncodeToString(b)
+}
@@ -2,12 +2,51 @@
 class GaitAPI {
     constructor() {
         this.baseUrl = '';
+        // The repository this tab works on, sent with every request; kept per tab so
+        // tabs on different repositories don't affect each other
+        this.repository = sessionStorage.getItem('gait-repository') || '';
+    }
+
+    // Select the repository ID sent with requests, or '' for the server default
+    setRepository(id) {
+        this.repository = id || '';
+        if (this.repository) {
+            sessionStorage.setItem('gait-repository', this.repository);
+        } else {
+            sessionStorage.removeItem('gait-repository');
+        }
+    }
+
+    // Add the repository header to fetch options; the repository list itself is
+    // managed independently of the selected repository
+    withRepository(endpoint, options = {}) {
+        if (!this.repository || endpoint.startsWith('/api/repositories') || endpoint.startsWith('/api/repository/')) {
+            return options;
+        }
+        return {
+            ...options,
+            headers: { ...(options.headers || {}), 'X-Gait-Repository': this.repository }
+        };
+    }
+
+    // Add the repository to a URL used where headers can't be set, such as download
+    // links, EventSource streams and WebSockets
+    repositoryURL(url) {
+        if (!this.repository) return url;
+        const separator = url.includes('?') ? '&' : '?';
+        return `${url}${separator}repo=${encodeURIComponent(this.repository)}`;
     }
 
     // Safe API call with error handling
     async call(endpoint, options = {}) {
         try {
-            const response = await fetch(endpoint, options);
+            const response = await fetch(endpoint, this.withRepository(endpoint, options));
+            if (response.status === 400 || response.status === 401 || response.status === 403) {
+                // Rejected input and authentication failures carry a message such as
+                // "requires the admin role"
+                const body = await response.json().catch(() => ({}));
+                throw new Error(body.error || `HTTP ${response.status}: ${response.statusText}`);
+            }
             if (!response.ok) {
                 throw new Error(`HTTP ${response.status}: ${response.statusText}`);
             }
@@ -22,7 +61,7 @@ class GaitAPI {
     // Safe HTML API call for server-side rendered content
     async callHTML(endpoint, options = {}) {
         try {
-            const response = await fetch(endpoint, options);
+            const response = await fetch(endpoint, this.withRepository(endpoint, options));
             if (!response.ok) {
                 throw new Error(`HTTP ${response.status}: ${response.statusText}`);
             }
@@ -33,6 +72,11 @@ class GaitAPI {
         }
     }
 
+    // The signed-in user and their role
+    async getCurrentUser() {
+        return this.call('/api/auth/me');
+    }
+
     // Repository Management API calls
     async getRepositories() {
         return this.call('/api/repositories');
@@ -46,11 +90,12 @@ class GaitAPI {
         });
     }
 
-    async cloneRepository(url, name = '') {
+    // options: branch, singleBranch, depth, filter and sparse cone directories
+    async cloneRepository(url, name = '', options = {}) {
         return this.call('/api/repositories/clone', {
             method: 'POST',
             headers: { 'Content-Type': 'application/json' },
-            body: JSON.stringify({ url, name })
+            body: JSON.stringify({ url, name, ...options })
         });
     }
 
@@ -62,6 +107,38 @@ class GaitAPI {
         });
     }
 
+    // Update a repository's display name, group or pinned state
+    async updateRepositoryMetadata(path, metadata) {
+        return this.call('/api/repositories/metadata', {
+            method: 'PUT',
+            headers: { 'Content-Type': 'application/json' },
+            body: JSON.stringify({ path, ...metadata })
+        });
+    }
+
+    // List the named repository groups
+    async getRepositoryGroups() {
+        return this.call('/api/repositories/groups');
+    }
+
+    // Cross-repository view: 'commits', 'uncommitted' or 'behind', over a group and tag
+    async getRepositoryView(view, group = '', tag = '') {
+        const params = new URLSearchParams();
+        if (group) params.set('group', group);
+        if (tag) params.set('tag', tag);
+        return this.call(`/api/repositories/${view}?${params}`);
+    }
+
+    // Search commits across the repositories in a group and with a tag
+    async searchRepositories(query, types = [], group = '', tag = '') {
+        return this.call('/api/repositories/search', {
+            method: 'POST',
+            headers: { 'Content-Type': 'application/json' },
+            body: JSON.stringify({ query, types, group, tag })
+        });
+    }
+
+    // Change the server's default repository, used by clients that don't select one
     async switchRepository(path) {
         return this.call('/api/repository/switch', {
             method: 'POST',
@@ -77,11 +154,21 @@ class GaitAPI {
         });
     }
 
-    async discoverRepositories(maxDepth = 3) {
+    // Repositories discovery would find in the workspace, without adding them
+    async previewDiscovery(maxDepth = 3, exclude = []) {
+        return this.call('/api/repositories/discover/preview', {
+            method: 'POST',
+            headers: { 'Content-Type': 'application/json' },
+            body: JSON.stringify({ maxDepth, exclude })
+        });
+    }
+
+    // Add discovered repositories; with paths, only those
+    async discoverRepositories(maxDepth = 3, exclude = [], paths = []) {
         return this.call('/api/repositories/discover', {
             method: 'POST',
             headers: { 'Content-Type': 'application/json' },
-            body: JSON.stringify({ maxDepth })
+            body: JSON.stringify({ maxDepth, exclude, paths })
         });
     }
 
@@ -91,13 +178,15 @@ class GaitAPI {
     }
 
     // Get server-side rendered commits for better performance
-    async getCommitsHTML(limit = 50, offset = 0) {
-        return this.callHTML(`/api/commits/html?limit=${limit}&offset=${offset}`);
+    async getCommitsHTML(limit = 50, offset = 0, signature = '') {
+        const filter = signature ? `&signature=${encodeURIComponent(signature)}` : '';
+        return this.callHTML(`/api/commits/html?limit=${limit}&offset=${offset}${filter}`);
     }
 
     // Get commits with pagination support (fallback for JSON)
-    async getCommits(limit = 50, offset = 0) {
-        return this.call(`/api/commits?limit=${limit}&offset=${offset}`);
+    async getCommits(limit = 50, offset = 0, signature = '') {
+        const filter = signature ? `&signature=${encodeURIComponent(signature)}` : '';
+        return this.call(`/api/commits?limit=${limit}&offset=${offset}${filter}`);
     }
 
     // Get branches
@@ -185,6 +274,54 @@ class GaitAPI {
         return this.call(`/api/remote/${encodeURIComponent(remoteName)}/info`);
     }
 
+    async addRemote(name, url, fetch = true) {
+        return this.call('/api/remotes', {
+            method: 'POST',
+            headers: { 'Content-Type': 'application/json' },
+            body: JSON.stringify({ name, url, fetch })
+        });
+    }
+
+    async renameRemote(name, newName) {
+        return this.call(`/api/remote/${encodeURIComponent(name)}/rename`, {
+            method: 'POST',
+            headers: { 'Content-Type': 'application/json' },
+            body: JSON.stringify({ newName })
+        });
+    }
+
+    async removeRemote(name) {
+        return this.call(`/api/remote/${encodeURIComponent(name)}`, { method: 'DELETE' });
+    }
+
+    async setRemoteURL(name, url, push = false) {
+        return this.call(`/api/remote/${encodeURIComponent(name)}/url`, {
+            method: 'PUT',
+            headers: { 'Content-Type': 'application/json' },
+            body: JSON.stringify({ url, push })
+        });
+    }
+
+    async getRemoteRefspecs(name) {
+        return this.call(`/api/remote/${encodeURIComponent(name)}/refspecs`);
+    }
+
+    async setRemoteRefspecs(name, refspecs) {
+        return this.call(`/api/remote/${encodeURIComponent(name)}/refspecs`, {
+            method: 'PUT',
+            headers: { 'Content-Type': 'application/json' },
+            body: JSON.stringify({ refspecs })
+        });
+    }
+
+    async pruneRemote(name, dryRun = false) {
+        return this.call(`/api/remote/${encodeURIComponent(name)}/prune`, {
+            method: 'POST',
+            headers: { 'Content-Type': 'application/json' },
+            body: JSON.stringify({ dryRun })
+        });
+    }
+
     // Get commit details
     async getCommitDetails(hash) {
         return this.call(`/api/commit/${hash}`);
@@ -211,14 +348,64 @@ class GaitAPI {
 
     // Create commit
     async createCommit(message, options = {}) {
-        return this.call('/api/commit/create', {
+        const response = await fetch('/api/commit/create', this.withRepository('/api/commit/create', {
             method: 'POST',
             headers: { 'Content-Type': 'application/json' },
             body: JSON.stringify({ 
                 message,
                 amend: options.amend || false,
-                signoff: options.signoff || false
+                signoff: options.signoff || false,
+                override: options.override || false
             })
+        }));
+        const data = await response.json().catch(() => ({}));
+        if (!response.ok) {
+            // Commit policy violations come back as structured data
+            const error = new Error(data.error || `HTTP ${response.status}: ${response.statusText}`);
+            error.violations = data.violations || [];
+            error.overridable = data.overridable || false;
+            throw error;
+        }
+        return data;
+    }
+
+    // Check a commit message against the commit policy
+    async lintCommitMessage(message, signoff = false) {
+        return this.call('/api/commit/lint', {
+            method: 'POST',
+            headers: { 'Content-Type': 'application/json' },
+            body: JSON.stringify({ message, signoff })
+        });
+    }
+
+    // Commit policy configuration
+    async getCommitLintConfig() {
+        return this.call('/api/commit/lint/config');
+    }
+
+    async saveCommitLintConfig(config) {
+        return this.call('/api/commit/lint/config', {
+            method: 'POST',
+            headers: { 'Content-Type': 'application/json' },
+            body: JSON.stringify(config)
+        });
+    }
+
+    // Verify a commit signature
+    async verifyCommit(hash) {
+        return this.call(`/api/commit/${hash}/verify`);
+    }
+
+    // Get and save the signature verification config
+    async getSignatureConfig() {
+        return this.call('/api/signature/config');
+    }
+
+    async saveSignatureConfig(config) {
+        return this.call('/api/signature/config', {
+            method: 'POST',
+            headers: { 'Content-Type': 'application/json' },
+            body: JSON.stringify(config)
         });
     }
 
@@ -305,8 +492,12 @@ class GaitAPI {
     }
 
     // Search commits
-    async search(query) {
-        return this.call(`/api/search?q=${encodeURIComponent(query)}`);
+    async search(query, types = []) {
+        return this.call('/api/search', {
+            method: 'POST',
+            headers: { 'Content-Type': 'application/json' },
+            body: JSON.stringify({ query, types })
+        });
     }
 
     // Rename branch
@@ -336,6 +527,209 @@ class GaitAPI {
         });
     }
 
+    // Remote credentials (secrets are write-only)
+    async getCredentials() {
+        return this.call('/api/credentials');
+    }
+
+    async saveCredential(remote, username = '', token = '', sshKeyPath = '') {
+        return this.call(`/api/credentials/${encodeURIComponent(remote)}`, {
+            method: 'PUT',
+            headers: { 'Content-Type': 'application/json' },
+            body: JSON.stringify({ username, token, sshKeyPath })
+        });
+    }
+
+    async deleteCredential(remote) {
+        return this.call(`/api/credentials/${encodeURIComponent(remote)}`, { method: 'DELETE' });
+    }
+
+    // Protected branches and force-push settings
+    async getProtectionConfig() {
+        return this.call('/api/protection/config');
+    }
+
+    async saveProtectionConfig(config) {
+        return this.call('/api/protection/config', {
+            method: 'POST',
+            headers: { 'Content-Type': 'application/json' },
+            body: JSON.stringify(config)
+        });
+    }
+
+    async getBlockedOperations(limit = 100) {
+        return this.call(`/api/protection/blocked?limit=${limit}`);
+    }
+
+    // Start background jobs for remote operations; each resolves with the new job
+    async startFetchJob(remote = '', prune = false) {
+        return this.call('/api/jobs/fetch', {
+            method: 'POST',
+            headers: { 'Content-Type': 'application/json' },
+            body: JSON.stringify({ remote, prune })
+        });
+    }
+
+    async startPullJob(remote = '', branch = '') {
+        return this.call('/api/jobs/pull', {
+            method: 'POST',
+            headers: { 'Content-Type': 'application/json' },
+            body: JSON.stringify({ remote, branch })
+        });
+    }
+
+    async startPushJob(remote = '', branch = '', force = false) {
+        return this.call('/api/jobs/push', {
+            method: 'POST',
+            headers: { 'Content-Type': 'application/json' },
+            body: JSON.stringify({ remote, branch, force })
+        });
+    }
+
+    async startCloneJob(url, name = '', options = {}) {
+        return this.call('/api/jobs/clone', {
+            method: 'POST',
+            headers: { 'Content-Type': 'application/json' },
+            body: JSON.stringify({ url, name, ...options })
+        });
+    }
+
+    // Fetch more history into a shallow clone: depth more commits, or all of it
+    async startDeepenJob(repositoryId, depth = 0, unshallow = false, remote = '') {
+        return this.call(`/api/repos/${repositoryId}/jobs/deepen`, {
+            method: 'POST',
+            headers: { 'Content-Type': 'application/json' },
+            body: JSON.stringify({ remote, depth, unshallow })
+        });
+    }
+
+    // Shallow, partial, single-branch and sparse-checkout state of a repository
+    async getCloneInfo(repositoryId) {
+        return this.call(`/api/repos/${repositoryId}/clone-info`);
+    }
+
+    async setSparseCheckout(repositoryId, patterns, cone = true) {
+        return this.call(`/api/repos/${repositoryId}/sparse-checkout`, {
+            method: 'PUT',
+            headers: { 'Content-Type': 'application/json' },
+            body: JSON.stringify({ patterns, cone })
+        });
+    }
+
+    async disableSparseCheckout(repositoryId) {
+        return this.call(`/api/repos/${repositoryId}/sparse-checkout`, { method: 'DELETE' });
+    }
+
+    async getJob(id) {
+        return this.call(`/api/jobs/${id}`);
+    }
+
+    async cancelJob(id) {
+        return this.call(`/api/jobs/${id}`, { method: 'DELETE' });
+    }
+
+    // Follow a job over Server-Sent Events until it finishes. Resolves with the
+    // finished job, or rejects with an error carrying the job when it fails.
+    watchJob(id, onProgress = null) {
+        return new Promise((resolve, reject) => {
+            const settle = (job) => {
+                if (job.status === 'succeeded') {
+                    resolve(job);
+                } else {
+                    const error = new Error(job.error || `Job ${job.status}`);
+                    error.job = job;
+                    reject(error);
+                }
+            };
+
+            const source = new EventSource(this.repositoryURL(`/api/jobs/${id}/events`));
+            source.addEventListener('progress', (event) => {
+                if (onProgress) {
+                    onProgress(JSON.parse(event.data));
+                }
+            });
+            source.addEventListener('done', (event) => {
+                source.close();
+                settle(JSON.parse(event.data));
+            });
+            source.onerror = async () => {
+                source.close();
+                try {
+                    const job = await this.getJob(id);
+                    if (job.status === 'running') {
+                        reject(new Error('Lost connection to job progress'));
+                    } else {
+                        settle(job);
+                    }
+                } catch (error) {
+                    reject(error);
+                }
+            };
+        });
+    }
+
+    // Bisect
+    async getBisectStatus() {
+        return this.call('/api/bisect');
+    }
+
+    async bisectStart(bad, good) {
+        return this.call('/api/bisect/start', {
+            method: 'POST',
+            headers: { 'Content-Type': 'application/json' },
+            body: JSON.stringify({ bad, good })
+        });
+    }
+
+    // Mark a commit (default: the one checked out) as 'good', 'bad' or 'skip'
+    async bisectMark(term, commit = '') {
+        return this.call('/api/bisect/mark', {
+            method: 'POST',
+            headers: { 'Content-Type': 'application/json' },
+            body: JSON.stringify({ term, commit })
+        });
+    }
+
+    async bisectReset() {
+        return this.call('/api/bisect/reset', { method: 'POST' });
+    }
+
+    async getBisectLog() {
+        return this.call('/api/bisect/log');
+    }
+
+    async startBisectRunJob(command) {
+        return this.call('/api/jobs/bisect-run', {
+            method: 'POST',
+            headers: { 'Content-Type': 'application/json' },
+            body: JSON.stringify({ command })
+        });
+    }
+
+    // Sync the current branch with its upstream (mode: 'ff-only' or 'rebase')
+    async syncBranch(mode = 'ff-only') {
+        return this.call('/api/branch/sync', {
+            method: 'POST',
+            headers: { 'Content-Type': 'application/json' },
+            body: JSON.stringify({ mode })
+        });
+    }
+
+    // Analyze branches for cleanup (merged, squash-merged, stale, orphaned)
+    async analyzeBranchCleanup(base = '', staleDays = 0, remotes = true) {
+        const params = new URLSearchParams({ base, staleDays, remotes });
+        return this.call(`/api/branches/cleanup?${params}`);
+    }
+
+    // Delete branches in bulk; branches are { name, remote } objects
+    async cleanupBranches(branches, force = false, dryRun = true) {
+        return this.call('/api/branches/cleanup', {
+            method: 'POST',
+            headers: { 'Content-Type': 'application/json' },
+            body: JSON.stringify({ branches, force, dryRun })
+        });
+    }
+
     // Cherry pick commit
     async cherryPickCommit(commitHash) {
         return this.call('/api/commit/cherry-pick', {
@@ -354,15 +748,64 @@ class GaitAPI {
         });
     }
 
+    // Cherry-pick a range or list of commits - {commits, range, branch, recordOrigin, mainline, noCommit}
+    async cherryPick(options) {
+        return this.call('/api/cherry-pick', {
+            method: 'POST',
+            headers: { 'Content-Type': 'application/json' },
+            body: JSON.stringify(options)
+        });
+    }
+
+    // Revert a range or list of commits - {commits, range, branch, mainline, noCommit}
+    async revert(options) {
+        return this.call('/api/revert', {
+            method: 'POST',
+            headers: { 'Content-Type': 'application/json' },
+            body: JSON.stringify(options)
+        });
+    }
+
+    // State of an interrupted cherry-pick or revert
+    async getSequenceStatus() {
+        return this.call('/api/sequence/status');
+    }
+
+    async continueSequence() {
+        return this.call('/api/sequence/continue', { method: 'POST' });
+    }
+
+    async abortSequence() {
+        return this.call('/api/sequence/abort', { method: 'POST' });
+    }
+
     // Create tag
-    async createTag(tagName, commitHash = '', message = '', annotated = false) {
+    async createTag(tagName, commitHash = '', message = '', annotated = false, signing = {}) {
         return this.call('/api/tag/create', {
             method: 'POST',
             headers: { 'Content-Type': 'application/json' },
-            body: JSON.stringify({ tagName, commitHash, message, annotated })
+            body: JSON.stringify({
+                tagName,
+                commitHash,
+                message,
+                annotated,
+                sign: signing.sign || false,
+                signingKey: signing.signingKey || '',
+                format: signing.format || ''
+            })
         });
     }
 
+    // Verify a tag signature
+    async verifyTag(tagName) {
+        return this.call(`/api/tag/${encodeURIComponent(tagName)}/verify`);
+    }
+
+    // Get release notes for a tag
+    async getReleaseNotes(tagName) {
+        return this.call(`/api/tag/${encodeURIComponent(tagName)}/release-notes`);
+    }
+
     // Delete tag
     async deleteTag(tagName) {
         return this.call(`/api/tag/${encodeURIComponent(tagName)}`, {
@@ -380,11 +823,26 @@ class GaitAPI {
     }
 
     // Create stash
-    async createStash(message = '', includeUntracked = false) {
+    async createStash(message = '', includeUntracked = false, options = {}) {
+        const { keepIndex = false, staged = false, paths = [] } = options;
         return this.call('/api/stash/create', {
             method: 'POST',
             headers: { 'Content-Type': 'application/json' },
-            body: JSON.stringify({ message, includeUntracked })
+            body: JSON.stringify({ message, includeUntracked, keepIndex, staged, paths })
+        });
+    }
+
+    // Get the diff of a single file in a stash
+    async getStashFileDiff(index, path) {
+        return this.call(`/api/stash/${index}/diff?path=${encodeURIComponent(path)}`);
+    }
+
+    // Apply a single file from a stash to the working tree
+    async applyStashFile(index, path, overwrite = false) {
+        return this.call(`/api/stash/${index}/apply-file`, {
+            method: 'POST',
+            headers: { 'Content-Type': 'application/json' },
+            body: JSON.stringify({ path, overwrite })
         });
     }
 
@@ -405,6 +863,72 @@ class GaitAPI {
             body: JSON.stringify({ dryRun, includeDirectories })
         });
     }
+
+    // Download format-patch output for a commit or range
+    getPatchURL(revision) {
+        return this.repositoryURL(`/api/patch/format?rev=${encodeURIComponent(revision)}`);
+    }
+
+    // Download the working tree diff
+    getWorkingTreeDiffURL(staged = false) {
+        return this.repositoryURL(`/api/patch/diff?staged=${staged}`);
+    }
+
+    // Apply a patch or mbox file, optionally as a check-only preview
+    async applyPatch(file, options = {}) {
+        const formData = new FormData();
+        formData.append('patch', file);
+        const params = new URLSearchParams({
+            mode: options.mode || 'auto',
+            check: options.check || false,
+            threeWay: options.threeWay || false
+        });
+        const response = await fetch(`/api/patch/apply?${params}`, this.withRepository('/api/patch/apply', {
+            method: 'POST',
+            body: formData
+        }));
+        // A 409 still carries the structured per-hunk failures
+        if (!response.ok && response.status !== 409) {
+            throw new Error(`HTTP ${response.status}: ${response.statusText}`);
+        }
+        return response.json();
+    }
+
+    // Download a git bundle for a branch, tag or commit
+    getBundleURL(ref) {
+        return this.repositoryURL(`/api/export/bundle?ref=${encodeURIComponent(ref)}`);
+    }
+
+    // Download a tar.gz or zip archive, optionally limited to paths
+    getArchiveURL(ref, format = 'tar.gz', paths = []) {
+        const params = new URLSearchParams({ ref, format });
+        paths.forEach(path => params.append('path', path));
+        return this.repositoryURL(`/api/export/archive?${params}`);
+    }
+
+    // Verify a bundle and fetch it into the current repository
+    async importBundle(file, remote = 'bundle') {
+        const formData = new FormData();
+        formData.append('bundle', file);
+        return this.call(`/api/import/bundle?remote=${encodeURIComponent(remote)}`, {
+            method: 'POST',
+            body: formData
+        });
+    }
+
+    // Suggest the next semantic version from commits since the latest tag
+    async suggestVersion() {
+        return this.call('/api/version/suggest');
+    }
+
+    // Create an annotated version tag with a generated changelog
+    async createVersionTag(bump = '') {
+        return this.call('/api/version/tag', {
+            method: 'POST',
+            headers: { 'Content-Type': 'application/json' },
+            body: JSON.stringify({ bump })
+        });
+    }
 }
 
 // Create global API instance
@@ -3,6 +3,17 @@
  * Interactive dashboard with real-time updates and advanced visualizations
  */
 
+// The repository the dashboard shows: the page's ?repo= or the tab's selection
+const dashboardRepository = new URLSearchParams(window.location.search).get('repo')
+    || sessionStorage.getItem('gait-repository') || '';
+
+// Add the dashboard's repository to an API or WebSocket URL
+function repositoryURL(url) {
+    if (!dashboardRepository) return url;
+    const separator = url.includes('?') ? '&' : '?';
+    return `${url}${separator}repo=${encodeURIComponent(dashboardRepository)}`;
+}
+
 class ADESDashboard {
     constructor() {
         this.charts = new Map();
@@ -83,7 +94,7 @@ class ADESDashboard {
             this.showLoadingState();
             
             // Load dashboard data from single endpoint
-            const response = await fetch('/api/ades/dashboard');
+            const response = await fetch(repositoryURL('/api/ades/dashboard'));
             if (!response.ok) throw new Error('Failed to fetch dashboard data');
             const data = await response.json();
 
@@ -100,25 +111,25 @@ class ADESDashboard {
     }
 
     async fetchAnalytics() {
-        const response = await fetch('/api/ades/analytics');
+        const response = await fetch(repositoryURL('/api/ades/analytics'));
                     if (!response.ok) throw new Error('Failed to fetch analytics');
         return response.json();
     }
 
     async fetchInsights() {
-        const response = await fetch('/api/ades/insights');
+        const response = await fetch(repositoryURL('/api/ades/insights'));
                     if (!response.ok) throw new Error('Failed to fetch insights');
         return response.json();
     }
 
     async fetchPatterns() {
-        const response = await fetch('/api/ades/patterns');
+        const response = await fetch(repositoryURL('/api/ades/patterns'));
                     if (!response.ok) throw new Error('Failed to fetch patterns');
         return response.json();
     }
 
     async fetchSemantics() {
-        const response = await fetch('/api/ades/semantic/trends');
+        const response = await fetch(repositoryURL('/api/ades/semantic/trends'));
                     if (!response.ok) throw new Error('Failed to fetch semantic trends');
         return response.json();
     }
@@ -748,7 +759,7 @@ class ADESDashboard {
 
     connectWebSocket() {
         const protocol = window.location.protocol === 'https:' ? 'wss:' : 'ws:';
-        const wsUrl = `${protocol}//${window.location.host}/ws/dashboard`;
+        const wsUrl = `${protocol}//${window.location.host}${repositoryURL('/ws/dashboard')}`;
 
         this.websocket = new WebSocket(wsUrl);
 
@@ -946,7 +957,7 @@ class ADESDashboard {
 
     async analyzeRepository() {
         try {
-            const response = await fetch('/api/ades/analyze/comprehensive', {
+            const response = await fetch(repositoryURL('/api/ades/analyze/comprehensive'), {
                 method: 'POST'
             });
             
@@ -964,7 +975,7 @@ class ADESDashboard {
 
     async exportInsights() {
         try {
-            const response = await fetch('/api/ades/insights/export');
+            const response = await fetch(repositoryURL('/api/ades/insights/export'));
             const blob = await response.blob();
             
             const url = window.URL.createObjectURL(blob);
@@ -18,6 +18,7 @@ class GaitUI {
         this.commitsOffset = 0;
         this.commitsLimit = 50;
         this.isSearchMode = false;
+        this.signatureFilter = '';
         this.useServerSideRendering = true; // Enable SSR for better performance
         
         // Operation state flags to prevent multiple simultaneous operations
@@ -41,6 +42,11 @@ class GaitUI {
             this.commitsOffset = 0;
             this.hasMoreCommits = true;
             this.isSearchMode = false;
+            this.signatureFilter = '';
+            const signatureSelect = document.getElementById('signatureFilter');
+            if (signatureSelect) {
+                signatureSelect.value = '';
+            }
             
             // Use optimized single API call to get all data including uncommitted changes
             const allData = await gAItAPI.getAllData(this.commitsLimit);
@@ -125,7 +131,7 @@ class GaitUI {
         const list = document.getElementById('commitsList');
         
         try {
-            const html = await gAItAPI.getCommitsHTML(this.commitsLimit, replace ? 0 : this.commitsOffset);
+            const html = await gAItAPI.getCommitsHTML(this.commitsLimit, replace ? 0 : this.commitsOffset, this.signatureFilter);
             
             if (replace) {
                 // Add uncommitted changes at the top if there are any
@@ -198,7 +204,7 @@ class GaitUI {
         
         commitsHtml += commits.map(commit => `
             <li class="commit-item" onclick="gAItUI.selectCommit('${commit.hash}')" data-hash="${commit.hash}">
-                <div class="commit-hash">${commit.shortHash || commit.hash.substring(0, 7)}</div>
+                <div class="commit-hash">${commit.shortHash || commit.hash.substring(0, 7)}${this.renderSignatureBadge(commit.signature)}</div>
                 <div class="commit-message">${this.escapeHtml(commit.message || 'No message')}</div>
                 <div class="commit-meta">
                     <span class="commit-author">${this.escapeHtml(commit.author?.name || 'Unknown')}</span>
@@ -219,7 +225,7 @@ class GaitUI {
             // Append new commits (without uncommitted changes for append mode)
             const newCommitsHtml = commits.map(commit => `
                 <li class="commit-item" onclick="gAItUI.selectCommit('${commit.hash}')" data-hash="${commit.hash}">
-                    <div class="commit-hash">${commit.shortHash || commit.hash.substring(0, 7)}</div>
+                    <div class="commit-hash">${commit.shortHash || commit.hash.substring(0, 7)}${this.renderSignatureBadge(commit.signature)}</div>
                     <div class="commit-message">${this.escapeHtml(commit.message || 'No message')}</div>
                     <div class="commit-meta">
                         <span class="commit-author">${this.escapeHtml(commit.author?.name || 'Unknown')}</span>
@@ -246,12 +252,30 @@ class GaitUI {
         }
         list.innerHTML = branches.map(branch => `
             <li class="${branch.isCurrent ? 'current' : ''}" onclick="gAItUI.selectBranch('${branch.name}', ${branch.isCurrent})">
-                <span>${this.escapeHtml(branch.name)}</span>
+                <span>${this.escapeHtml(branch.name)}${this.renderTrackingStatus(branch)}</span>
                 <span>${branch.hash}</span>
             </li>
         `).join('');
     }
 
+    // Render ahead/behind counts against the upstream of a branch
+    renderTrackingStatus(branch) {
+        if (!branch.upstream) {
+            return '';
+        }
+        const fetched = branch.lastFetch ? `, last fetched ${this.formatDate(branch.lastFetch)}` : '';
+        if (branch.upstreamGone) {
+            return ` <span class="tracking-status gone" title="Upstream ${this.escapeHtml(branch.upstream)} is gone${fetched}">gone</span>`;
+        }
+        if (!branch.ahead && !branch.behind) {
+            return ` <span class="tracking-status" title="Up to date with ${this.escapeHtml(branch.upstream)}${fetched}">✓</span>`;
+        }
+        const counts = [];
+        if (branch.ahead) counts.push(`↑${branch.ahead}`);
+        if (branch.behind) counts.push(`↓${branch.behind}`);
+        return ` <span class="tracking-status diverged" title="${branch.ahead} ahead, ${branch.behind} behind ${this.escapeHtml(branch.upstream)}${fetched}">${counts.join(' ')}</span>`;
+    }
+
     // Enhanced branch selection with action menu
     selectBranch(branchName, isCurrent) {
         // If we're in tag mode, offer to exit tag mode and checkout the branch
@@ -313,6 +337,15 @@ class GaitUI {
                         <button class="action-btn secondary" onclick="gAItUI.showRebaseBranchDialog('${branchName}');">
                             🔗 ${'Rebase Branch'}
                         </button>
+                        <button class="action-btn secondary" onclick="gAItUI.performBranchActionWithButton(event, 'sync', '${branchName}', 'ff-only');" title="Fetch, fast-forward and push">
+                            🔃 ${'Sync (fast-forward)'}
+                        </button>
+                        <button class="action-btn secondary" onclick="gAItUI.performBranchActionWithButton(event, 'sync', '${branchName}', 'rebase');" title="Fetch, rebase onto upstream and push">
+                            🔃 ${'Sync (rebase)'}
+                        </button>
+                        <button class="action-btn secondary" onclick="gAItUI.showBranchCleanupDialog('${branchName}');" title="Find merged, stale and orphaned branches">
+                            🧹 ${'Clean Up Branches'}
+                        </button>
                     `}
                 </div>
             </div>
@@ -332,6 +365,84 @@ class GaitUI {
         this.showStatus(`Branch ${branchName} actions available`, 'info');
     }
 
+    // Show the branch cleanup assistant for branches relative to base
+    async showBranchCleanupDialog(base = '') {
+        this.closeAllMenus();
+        this.showStatus('Analyzing branches...', 'info');
+
+        let report;
+        try {
+            report = await gAItAPI.analyzeBranchCleanup(base, 0, true);
+        } catch (error) {
+            this.showStatus(`Branch analysis failed: ${error.message}`, 'error');
+            return;
+        }
+        if (!report.branches || report.branches.length === 0) {
+            this.showStatus(`No branches to clean up against ${report.base}`, 'success');
+            return;
+        }
+
+        const rows = report.branches.map((branch, index) => `
+            <label class="branch-cleanup-item">
+                <input type="checkbox" data-index="${index}" ${branch.reasons.includes('merged') || branch.reasons.includes('orphaned') ? 'checked' : ''}>
+                <span class="branch-cleanup-name">${this.escapeHtml(branch.remote ? `${branch.remote}/${branch.name}` : branch.name)}</span>
+                <span class="branch-cleanup-reasons">${branch.reasons.map(reason => `<span class="cleanup-reason ${reason}">${reason}</span>`).join(' ')}</span>
+                <span class="branch-cleanup-date">${this.formatDate(branch.lastCommitDate)}</span>
+            </label>
+        `).join('');
+
+        const modal = window.modalSystem;
+        modal.currentModal = 'branch-cleanup';
+        modal.title.textContent = `Clean Up Branches (base: ${report.base}, stale after ${report.staleDays} days)`;
+        modal.body.innerHTML = `<div class="branch-cleanup-list">${rows}</div>`;
+        modal.confirmBtn.textContent = 'Preview Deletion';
+        modal.cancelBtn.textContent = 'Cancel';
+
+        const selected = await new Promise(resolve => {
+            modal.currentResolve = resolve;
+            modal.confirmBtn.onclick = () => {
+                const checked = Array.from(modal.body.querySelectorAll('input[type="checkbox"]:checked'))
+                    .map(input => report.branches[parseInt(input.dataset.index, 10)]);
+                modal.close(checked);
+            };
+            modal.show();
+        });
+        if (!selected || selected.length === 0) {
+            this.showStatus('Branch cleanup cancelled', 'info');
+            return;
+        }
+
+        const branches = selected.map(branch => ({ name: branch.name, remote: branch.remote || '' }));
+        // Squash-merged and stale local branches are not merged as far as git knows
+        const force = selected.some(branch => !branch.remote && !branch.reasons.includes('merged'));
+
+        try {
+            const preview = await gAItAPI.cleanupBranches(branches, force, true);
+            const confirmed = await showWarningDialog({
+                title: 'Delete Branches',
+                message: `Delete ${branches.length} branch${branches.length !== 1 ? 'es' : ''}?`,
+                details: preview.results.map(result => result.error ? `✕ ${result.name}: ${result.error}` : result.command).join('\n'),
+                confirmText: 'Delete',
+                cancelText: 'Cancel'
+            });
+            if (!confirmed) {
+                this.showStatus('Branch cleanup cancelled', 'info');
+                return;
+            }
+
+            const outcome = await gAItAPI.cleanupBranches(branches, force, false);
+            const failed = outcome.results.filter(result => !result.deleted);
+            if (failed.length > 0) {
+                this.showStatus(`Deleted ${outcome.results.length - failed.length} branches, ${failed.length} failed: ${failed.map(result => result.name).join(', ')}`, 'error');
+            } else {
+                this.showStatus(`Deleted ${outcome.results.length} branches`, 'success');
+            }
+            await this.loadData();
+        } catch (error) {
+            this.showStatus(`Branch cleanup failed: ${error.message}`, 'error');
+        }
+    }
+
     // Position branch menu properly
     positionBranchMenu(menu) {
         const rect = menu.getBoundingClientRect();
@@ -535,6 +646,21 @@ class GaitUI {
                     await this.loadData();
                     break;
 
+                case 'sync':
+                    this.showStatus(`Syncing ${branchName} with upstream...`, 'info');
+                    const syncResult = await gAItAPI.syncBranch(startPoint);
+                    const syncSteps = syncResult.steps.map(step => `${step.name}: ${step.status}`).join(', ');
+                    if (syncResult.success) {
+                        this.showStatus(`Synced ${branchName} (${syncSteps})`, 'success');
+                    } else {
+                        const failed = syncResult.steps.find(step => step.status === 'failed');
+                        this.showStatus(`Sync failed at ${failed ? failed.name : 'unknown step'}: ${failed ? failed.output : ''}`, 'error');
+                    }
+                    
+                    // Refresh data immediately
+                    await this.loadData();
+                    break;
+
                 case 'rename':
                     this.showStatus(`Renaming branch ${branchName} to ${startPoint}...`, 'info');
                     await gAItAPI.renameBranch(branchName, startPoint);
@@ -618,8 +744,9 @@ class GaitUI {
 
     renderRemotes(remotes) {
         const list = document.getElementById('remotesList');
+        const addItem = `<li class="add-remote-item" onclick="gAItUI.showAddRemoteDialog()"><span>+ Add remote</span></li>`;
         if (!Array.isArray(remotes) || remotes.length === 0) {
-            list.innerHTML = `<li class="loading">${'No remotes found'}</li>`;
+            list.innerHTML = `<li class="loading">${'No remotes found'}</li>` + addItem;
             return;
         }
         list.innerHTML = remotes.map(remote => `
@@ -627,14 +754,14 @@ class GaitUI {
                 <span>${this.escapeHtml(remote.name)}</span>
                 <span title="${this.escapeHtml(remote.fetchUrl || remote.pushUrl || '')}">📡</span>
             </li>
-        `).join('');
+        `).join('') + addItem;
     }
 
     async selectCommit(hash) {
         document.querySelectorAll('.commit-item').forEach(item => {
             item.classList.remove('selected');
         });
-        document.querySelector(`[data-hash="${hash}"]`).classList.add('selected');
+        document.querySelector(`[data-hash="${hash}"]`)?.classList.add('selected');
         
         this.selectedCommit = hash;
         // Don't clear expandedFiles - we want to maintain state across commits
@@ -668,12 +795,56 @@ class GaitUI {
         }
     }
 
+    // Render a verified/unverified badge for a commit signature
+    renderSignatureBadge(signature) {
+        if (!signature || !signature.signed) {
+            return '';
+        }
+        const labels = {
+            'good': 'Verified',
+            'untrusted': 'Untrusted',
+            'bad': 'Bad signature',
+            'expired': 'Expired',
+            'revoked': 'Revoked',
+            'unknown-key': 'Unverified'
+        };
+        const label = labels[signature.status] || 'Unverified';
+        const title = signature.signer ? `${label}: ${signature.signer}` : label;
+        return ` <span class="signature-badge signature-${this.escapeHtml(signature.status)}" title="${this.escapeHtml(title)}">${signature.verified ? '✓' : '⚠'} ${label}</span>`;
+    }
+
+    // Filter the commit list by signature status ('', signed, unsigned, verified, unverified)
+    async setSignatureFilter(filter) {
+        this.signatureFilter = filter;
+        this.commitsOffset = 0;
+        this.hasMoreCommits = true;
+
+        try {
+            const commits = await gAItAPI.getCommits(this.commitsLimit, 0, this.signatureFilter);
+            this.currentData.commits = commits;
+            this.commitsOffset = commits.length;
+            this.hasMoreCommits = commits.length === this.commitsLimit;
+
+            if (this.useServerSideRendering) {
+                await this.renderCommitsSSR(true);
+            } else {
+                this.renderCommits(commits, true);
+            }
+        } catch (error) {
+            this.showStatus(`Failed to filter commits: ${error.message}`, 'error');
+        }
+    }
+
     renderCommitDetails(commit) {
         const title = document.getElementById('detailsTitle');
         const content = document.getElementById('detailsContent');
         
         title.textContent = `${'Commit'} ${commit.shortHash}`;
         
+        // Stash entries get per-file diffs from the stash endpoint and an apply button
+        const stashRef = (commit.refs || []).find(ref => /^stash@\{\d+\}$/.test(ref));
+        this.currentStash = stashRef ? { hash: commit.hash, index: parseInt(stashRef.slice(7, -1), 10) } : null;
+        
         let totalAdditions = 0;
         let totalDeletions = 0;
         let filesChanged = 0;
@@ -692,6 +863,7 @@ class GaitUI {
                 <div class="meta">${'Author'}: ${this.escapeHtml(commit.author.name)} &lt;${this.escapeHtml(commit.author.email)}&gt;</div>
                 <div class="meta">${'Date'}: ${this.formatDate(commit.date)}</div>
                 <div class="meta">${'Hash'}: <code>${commit.hash}</code></div>
+                ${commit.signature ? `<div class="meta">${'Signature'}: ${this.renderSignatureBadge(commit.signature)} ${commit.signature.signer ? this.escapeHtml(commit.signature.signer) : ''} ${commit.signature.key ? `<code>${this.escapeHtml(commit.signature.key)}</code>` : ''}</div>` : ''}
                 ${commit.parents && commit.parents.length > 0 ? 
                     `<div class="meta">${'Parents'}: ${commit.parents.map(p => `<code>${p.substring(0, 7)}</code>`).join(', ')}</div>` : ''}
                 ${filesChanged > 0 ? `
@@ -812,6 +984,7 @@ class GaitUI {
                                 </div>
                                 <button class="diff-wrap-btn active" id="wrap-btn-${index}" onclick="gAItDiffViewer.toggleWrap(${index})">${'Wrap'}</button>
                                 <button class="diff-fullscreen-btn" onclick="gAItDiffViewer.openFullscreenDiff('${commitHash}', '${this.escapeHtml(file.path)}', ${index})">${'Fullscreen'}</button>
+                                ${this.currentStash && this.currentStash.hash === commitHash ? `<button class="diff-fullscreen-btn" onclick="gAItUI.applyStashFile(${this.currentStash.index}, '${this.escapeHtml(file.path)}')" title="Apply this file's stashed changes to the working tree">${'Apply file'}</button>` : ''}
                             </div>
                             <div class="diff-content" id="diff-content-${index}">
                                 <div class="loading">${'Loading diff...'}</div>
@@ -931,13 +1104,48 @@ class GaitUI {
         setTimeout(restoreFiles, 500);
     }
 
+    // Load a file's diff, using the stash endpoint when the details view shows a stash
+    async fetchFileDiff(hash, filePath) {
+        if (this.currentStash && this.currentStash.hash === hash) {
+            return gAItAPI.getStashFileDiff(this.currentStash.index, filePath);
+        }
+        return gAItAPI.getFileDiff(hash, filePath);
+    }
+
+    // Apply one file of the stash shown in the details view
+    async applyStashFile(index, filePath) {
+        try {
+            await gAItAPI.applyStashFile(index, filePath);
+            this.showStatus(`Applied ${filePath} from stash@{${index}}`, 'success');
+        } catch (error) {
+            const overwrite = await showConfirmDialog({
+                title: 'Apply File From Stash',
+                message: `Replace the working tree copy of ${filePath} with the stashed version?`,
+                details: error.message,
+                confirmText: 'Replace'
+            });
+            if (!overwrite) {
+                this.showStatus(`Failed to apply ${filePath}: ${error.message}`, 'error');
+                return;
+            }
+            try {
+                await gAItAPI.applyStashFile(index, filePath, true);
+                this.showStatus(`Replaced ${filePath} with the version from stash@{${index}}`, 'success');
+            } catch (overwriteError) {
+                this.showStatus(`Failed to apply ${filePath}: ${overwriteError.message}`, 'error');
+                return;
+            }
+        }
+        await this.loadData();
+    }
+
     // Helper function to load file diff
     async loadFileDiff(hash, filePath, index) {
         const diffContent = document.getElementById(`diff-content-${index}`);
         if (!diffContent) return;
         
         try {
-            const diff = await gAItAPI.getFileDiff(hash, filePath);
+            const diff = await this.fetchFileDiff(hash, filePath);
             gAItDiffViewer.renderFileDiff(diff, filePath, index);
         } catch (error) {
             diffContent.innerHTML = `<div class="error">Failed to load diff: ${error.message}</div>`;
@@ -1065,7 +1273,7 @@ class GaitUI {
                 this.showStatus(`Loading diff for ${filePath}...`, 'info');
                 
                 try {
-                    const diff = await gAItAPI.getFileDiff(hash, filePath);
+                    const diff = await this.fetchFileDiff(hash, filePath);
                     gAItDiffViewer.renderFileDiff(diff, filePath, index);
                     this.showStatus('Diff loaded', 'success');
                 } catch (error) {
@@ -1657,6 +1865,27 @@ class GaitUI {
                     <button class="action-btn secondary" onclick="gAItUI.performRemoteActionWithButton(event, 'push', '${name}'); gAItUI.closeAllMenus();">
                         ⬆️ Push
                     </button>
+                    <button class="action-btn secondary" onclick="gAItUI.closeAllMenus(); gAItUI.showCredentialDialog('${name}');">
+                        🔑 Credentials
+                    </button>
+                    <button class="action-btn secondary" onclick="gAItUI.performRemoteActionWithButton(event, 'prune', '${name}'); gAItUI.closeAllMenus();">
+                        🧹 Prune
+                    </button>
+                    <button class="action-btn secondary" onclick="gAItUI.performRemoteActionWithButton(event, 'set-url', '${name}'); gAItUI.closeAllMenus();">
+                        🔗 Set Fetch URL
+                    </button>
+                    <button class="action-btn secondary" onclick="gAItUI.performRemoteActionWithButton(event, 'set-push-url', '${name}'); gAItUI.closeAllMenus();">
+                        🔗 Set Push URL
+                    </button>
+                    <button class="action-btn secondary" onclick="gAItUI.performRemoteActionWithButton(event, 'refspecs', '${name}'); gAItUI.closeAllMenus();">
+                        ⚙️ Fetch Refspecs
+                    </button>
+                    <button class="action-btn secondary" onclick="gAItUI.performRemoteActionWithButton(event, 'rename', '${name}'); gAItUI.closeAllMenus();">
+                        ✏️ Rename
+                    </button>
+                    <button class="action-btn danger" onclick="gAItUI.performRemoteActionWithButton(event, 'remove', '${name}'); gAItUI.closeAllMenus();">
+                        🗑️ Remove
+                    </button>
                 </div>
             </div>
         `;
@@ -1787,25 +2016,109 @@ class GaitUI {
                     break;
 
                 case 'fetch':
-                    this.showStatus(`Fetching from ${remoteName}...`, 'info');
-                    await gAItAPI.fetch();
-                    this.showStatus(`Fetched from ${remoteName} successfully`, 'success');
+                    const fetchResult = await this.runRemoteJob(`Fetching from ${remoteName}`, gAItAPI.startFetchJob(remoteName));
+                    this.showStatus(`Fetched from ${remoteName}: ${this.describeRemoteResult(fetchResult)}`, 'success');
                     // Refresh data immediately
                     await this.loadData();
                     break;
 
                 case 'pull':
-                    this.showStatus(`Pulling from ${remoteName}...`, 'info');
-                    await gAItAPI.pullFromRemote(remoteName, '');
+                    await this.runRemoteJob(`Pulling from ${remoteName}`, gAItAPI.startPullJob(remoteName, ''));
                     this.showStatus(`Pulled from ${remoteName} successfully`, 'success');
                     // Refresh data immediately
                     await this.loadData();
                     break;
 
                 case 'push':
-                    this.showStatus(`Pushing to ${remoteName}...`, 'info');
-                    await gAItAPI.pushToRemote(remoteName, '', false);
-                    this.showStatus(`Pushed to ${remoteName} successfully`, 'success');
+                    const pushResult = await this.runRemoteJob(`Pushing to ${remoteName}`, gAItAPI.startPushJob(remoteName, '', false));
+                    this.showStatus(`Pushed to ${remoteName}: ${this.describeRemoteResult(pushResult)}`, 'success');
+                    break;
+
+                case 'prune':
+                    this.showStatus(`Checking ${remoteName} for stale branches...`, 'info');
+                    const preview = await gAItAPI.pruneRemote(remoteName, true);
+                    if (preview.pruned.length === 0) {
+                        this.showStatus(`No stale remote-tracking branches for ${remoteName}`, 'success');
+                        break;
+                    }
+                    const confirmedPrune = await showConfirmDialog({
+                        title: 'Prune Remote',
+                        message: `Delete ${preview.pruned.length} stale remote-tracking branch${preview.pruned.length !== 1 ? 'es' : ''}?`,
+                        details: preview.pruned.join('\n'),
+                        confirmText: 'Prune'
+                    });
+                    if (confirmedPrune) {
+                        const pruneResult = await gAItAPI.pruneRemote(remoteName, false);
+                        this.showStatus(`Pruned ${pruneResult.pruned.length} branches from ${remoteName}`, 'success');
+                        await this.loadData();
+                    }
+                    break;
+
+                case 'set-url':
+                case 'set-push-url':
+                    const isPush = action === 'set-push-url';
+                    const current = (this.currentData.remotes || []).find(remote => remote.name === remoteName) || {};
+                    const url = await showInputDialog({
+                        title: `Set ${isPush ? 'Push' : 'Fetch'} URL`,
+                        label: `${isPush ? 'Push' : 'Fetch'} URL for ${remoteName}`,
+                        value: (isPush ? current.pushUrl : current.fetchUrl) || '',
+                        required: !isPush,
+                        tip: isPush ? 'Leave empty to push to the fetch URL' : '',
+                        confirmText: 'Save'
+                    });
+                    if (url === false) {
+                        break;
+                    }
+                    await gAItAPI.setRemoteURL(remoteName, url, isPush);
+                    this.showStatus(`Updated ${isPush ? 'push' : 'fetch'} URL of ${remoteName}`, 'success');
+                    await this.loadData();
+                    break;
+
+                case 'refspecs':
+                    const { refspecs } = await gAItAPI.getRemoteRefspecs(remoteName);
+                    const refspecInput = await showInputDialog({
+                        title: 'Fetch Refspecs',
+                        label: `Fetch refspecs for ${remoteName}`,
+                        value: refspecs.join(' '),
+                        required: true,
+                        tip: 'Separate multiple refspecs with spaces',
+                        confirmText: 'Save'
+                    });
+                    if (!refspecInput) {
+                        break;
+                    }
+                    await gAItAPI.setRemoteRefspecs(remoteName, refspecInput.split(/\s+/).filter(Boolean));
+                    this.showStatus(`Updated fetch refspecs of ${remoteName}`, 'success');
+                    break;
+
+                case 'rename':
+                    const newName = await showInputDialog({
+                        title: 'Rename Remote',
+                        label: 'New name',
+                        value: remoteName,
+                        required: true,
+                        confirmText: 'Rename'
+                    });
+                    if (!newName || newName === remoteName) {
+                        break;
+                    }
+                    await gAItAPI.renameRemote(remoteName, newName);
+                    this.showStatus(`Renamed remote ${remoteName} to ${newName}`, 'success');
+                    await this.loadData();
+                    break;
+
+                case 'remove':
+                    const confirmedRemove = await showConfirmDialog({
+                        title: 'Remove Remote',
+                        message: `Remove remote ${remoteName}?`,
+                        details: 'Its remote-tracking branches, settings and stored credentials will be deleted.',
+                        confirmText: 'Remove'
+                    });
+                    if (confirmedRemove) {
+                        await gAItAPI.removeRemote(remoteName);
+                        this.showStatus(`Removed remote ${remoteName}`, 'success');
+                        await this.loadData();
+                    }
                     break;
             }
         } catch (error) {
@@ -1836,6 +2149,21 @@ class GaitUI {
                     <div class="info-item">
                         <strong>Push URL:</strong> ${this.escapeHtml(remote.pushUrl || 'Not set')}
                     </div>
+                    ${remote.headBranch ? `<div class="info-item"><strong>HEAD branch:</strong> ${this.escapeHtml(remote.headBranch)}</div>` : ''}
+                    ${remote.fetchRefspecs && remote.fetchRefspecs.length > 0 ? `<div class="info-item"><strong>Fetch refspecs:</strong> ${remote.fetchRefspecs.map(refspec => `<code>${this.escapeHtml(refspec)}</code>`).join(' ')}</div>` : ''}
+                    ${remote.branches && remote.branches.length > 0 ? `
+                    <div class="info-item">
+                        <strong>Branches:</strong>
+                        <ul class="remote-branch-list">
+                            ${remote.branches.map(branch => `
+                                <li>
+                                    <span>${this.escapeHtml(branch.name)}</span>
+                                    <span class="remote-branch-status ${this.escapeHtml(branch.status)}">${this.escapeHtml(branch.status)}</span>
+                                    ${branch.trackedLocally ? `<span class="remote-branch-local" title="Tracked by local branch">↔ ${branch.localBranches.map(local => this.escapeHtml(local)).join(', ')}</span>` : ''}
+                                </li>
+                            `).join('')}
+                        </ul>
+                    </div>` : ''}
                 </div>
             </div>
         `;
@@ -2059,7 +2387,7 @@ class GaitUI {
                 await this.renderCommitsSSR(false);
                 
                 // Update state - we need to fetch the actual commit data for state management
-                const newCommits = await gAItAPI.getCommits(this.commitsLimit, this.commitsOffset);
+                const newCommits = await gAItAPI.getCommits(this.commitsLimit, this.commitsOffset, this.signatureFilter);
                 this.currentData.commits.push(...newCommits);
                 this.commitsOffset += newCommits.length;
                 this.hasMoreCommits = newCommits.length === this.commitsLimit;
@@ -2080,7 +2408,7 @@ class GaitUI {
                 this.showStatus(`Loaded ${newCommits.length} more commits`, 'success');
             } else {
                 // Fallback to client-side rendering
-                const newCommits = await gAItAPI.getCommits(this.commitsLimit, this.commitsOffset);
+                const newCommits = await gAItAPI.getCommits(this.commitsLimit, this.commitsOffset, this.signatureFilter);
                 
                 if (newCommits.length > 0) {
                     // Add to current data
@@ -3183,22 +3511,22 @@ class GaitUI {
         try {
             switch (operation) {
                 case 'fetch':
-                    this.showStatus('Fetching from all remotes...', 'info');
-                    await gAItAPI.fetch('', true); // Fetch all with prune
-                    this.showStatus('Successfully fetched from all remotes', 'success');
+                    // Fetch all with prune
+                    const fetchResult = await this.runRemoteJob('Fetching from all remotes', gAItAPI.startFetchJob('', true));
+                    this.showStatus(`Fetched from all remotes: ${this.describeRemoteResult(fetchResult)}`, 'success');
                     await this.loadData();
                     break;
                     
                 case 'pull':
-                    this.showStatus('Pulling current branch...', 'info');
-                    await gAItAPI.pullFromRemote('', ''); // Pull current branch from default remote
+                    // Pull current branch from default remote
+                    await this.runRemoteJob('Pulling current branch', gAItAPI.startPullJob('', ''));
                     this.showStatus('Successfully pulled current branch', 'success');
                     await this.loadData();
                     break;
                     
                 case 'push':
-                    this.showStatus('Pushing current branch...', 'info');
-                    await gAItAPI.pushToRemote('', '', false); // Push current branch to default remote
+                    // Push current branch to default remote
+                    await this.runRemoteJob('Pushing current branch', gAItAPI.startPushJob('', '', false));
                     this.showStatus('Successfully pushed current branch', 'success');
                     break;
                     
@@ -3276,17 +3604,42 @@ class GaitUI {
         const existingMenu = document.querySelector('.action-menu');
         if (existingMenu) existingMenu.remove();
 
-        const message = prompt('Stash message (optional):');
-        if (message !== null) {
-            const includeUntracked = confirm('Include untracked files?');
-            this.performCreateStash(message.trim(), includeUntracked);
-        }
+        const modal = window.modalSystem;
+        modal.currentModal = 'stash';
+        modal.title.textContent = 'Create Stash';
+        modal.body.innerHTML = `
+            <div class="credential-form stash-form">
+                <label>Message <input type="text" id="stashMessage" placeholder="Optional"></label>
+                <label>Paths <input type="text" id="stashPaths" placeholder="Leave empty to stash everything, e.g. src/ README.md"></label>
+                <label class="stash-option"><input type="checkbox" id="stashUntracked"> Include untracked files</label>
+                <label class="stash-option"><input type="checkbox" id="stashKeepIndex"> Keep staged changes (--keep-index)</label>
+                <label class="stash-option"><input type="checkbox" id="stashStaged"> Stash staged changes only (--staged)</label>
+            </div>
+        `;
+        modal.confirmBtn.textContent = 'Stash';
+        modal.cancelBtn.textContent = 'Cancel';
+
+        new Promise(resolve => {
+            modal.currentResolve = resolve;
+            modal.confirmBtn.onclick = () => modal.close({
+                message: document.getElementById('stashMessage').value.trim(),
+                paths: document.getElementById('stashPaths').value.split(/\s+/).filter(path => path),
+                includeUntracked: document.getElementById('stashUntracked').checked,
+                keepIndex: document.getElementById('stashKeepIndex').checked,
+                staged: document.getElementById('stashStaged').checked
+            });
+            modal.show();
+        }).then(options => {
+            if (options) {
+                this.performCreateStash(options.message, options.includeUntracked, options);
+            }
+        });
     }
 
-    async performCreateStash(message, includeUntracked) {
+    async performCreateStash(message, includeUntracked, options = {}) {
         try {
             this.showStatus('Creating stash...', 'info');
-            await gAItAPI.createStash(message, includeUntracked);
+            await gAItAPI.createStash(message, includeUntracked, options);
             this.showStatus('Stash created successfully', 'success');
             await this.loadData();
         } catch (error) {
@@ -3342,8 +3695,7 @@ class GaitUI {
 
     async performPullOperation(remote, branch) {
         try {
-            this.showStatus(`Pulling ${branch || 'current branch'} from ${remote || 'default remote'}...`, 'info');
-            await gAItAPI.pullFromRemote(remote, branch);
+            await this.runRemoteJob(`Pulling ${branch || 'current branch'} from ${remote || 'default remote'}`, gAItAPI.startPullJob(remote, branch));
             this.showStatus('Pull completed successfully', 'success');
             await this.loadData();
         } catch (error) {
@@ -3366,22 +3718,167 @@ class GaitUI {
             prompt(`Push to remote (${remotes.map(r => r.name).join(', ')}):`) || '';
         
         const branchName = prompt('Push branch:') || '';
-        const force = confirm('Force push?');
+        const force = confirm('Force push? The remote branch is only overwritten if nobody else pushed since your last fetch (--force-with-lease).');
         
         this.performPushOperation(remoteName, branchName, force);
     }
 
     async performPushOperation(remote, branch, force) {
         try {
-            this.showStatus(`${force ? 'Force p' : 'P'}ushing ${branch || 'current branch'} to ${remote || 'default remote'}...`, 'info');
-            await gAItAPI.pushToRemote(remote, branch, force);
-            this.showStatus('Push completed successfully', 'success');
+            const result = await this.runRemoteJob(`${force ? 'Force p' : 'P'}ushing ${branch || 'current branch'} to ${remote || 'default remote'}`, gAItAPI.startPushJob(remote, branch, force));
+            this.showStatus(`Push completed: ${this.describeRemoteResult(result)}`, 'success');
         } catch (error) {
             console.error('Push failed:', error);
             this.showStatus(`Push failed: ${error.message}`, 'error');
         }
     }
 
+    // Run a background remote job, streaming its progress to the status bar.
+    // Resolves with the job's structured result.
+    async runRemoteJob(label, startJob) {
+        const job = await startJob;
+        this.activeJobId = job.id;
+        this.showJobProgress(label, job);
+        try {
+            const finished = await gAItAPI.watchJob(job.id, update => this.showJobProgress(label, update));
+            return finished.result;
+        } catch (error) {
+            if (error.job && error.job.errorCode === 'auth_failed') {
+                // Offer to store credentials; the caller reports the failure itself
+                this.showCredentialDialog((error.job.result && error.job.result.remote) || '');
+            }
+            const rejected = error.job && error.job.result && error.job.result.rejected;
+            if (rejected && rejected.length > 0) {
+                error.message = `${error.message}: ${rejected.map(ref => `${ref.to}${ref.reason ? ` (${ref.reason})` : ''}`).join(', ')}`;
+            }
+            throw error;
+        } finally {
+            this.activeJobId = null;
+        }
+    }
+
+    // Add a remote and fetch it
+    async showAddRemoteDialog() {
+        try {
+            const name = await showInputDialog({
+                title: 'Add Remote',
+                label: 'Remote name',
+                value: (this.currentData.remotes || []).length === 0 ? 'origin' : '',
+                required: true,
+                pattern: /^[^\s]+$/,
+                confirmText: 'Next'
+            });
+            if (!name) {
+                return;
+            }
+            const url = await showInputDialog({
+                title: 'Add Remote',
+                label: `URL for ${name}`,
+                placeholder: 'https://github.com/user/repo.git',
+                required: true,
+                confirmText: 'Add'
+            });
+            if (!url) {
+                return;
+            }
+
+            this.showStatus(`Adding remote ${name}...`, 'info');
+            await gAItAPI.addRemote(name, url, true);
+            this.showStatus(`Added remote ${name}`, 'success');
+            await this.loadData();
+        } catch (error) {
+            this.showStatus(`Failed to add remote: ${error.message}`, 'error');
+        }
+    }
+
+    // Ask for and store the HTTPS token or SSH key used for a remote
+    async showCredentialDialog(remote = '') {
+        let existing = null;
+        try {
+            const credentials = await gAItAPI.getCredentials();
+            existing = credentials.find(credential => credential.remote === (remote || 'origin')) || null;
+        } catch (error) {
+            console.error('Failed to load credentials:', error);
+        }
+
+        const modal = window.modalSystem;
+        modal.currentModal = 'credentials';
+        modal.title.textContent = 'Remote Credentials';
+        modal.body.innerHTML = `
+            <div class="credential-form">
+                <label>Remote <input type="text" id="credentialRemote" value="${this.escapeHtml(remote || 'origin')}"></label>
+                <label>Username <input type="text" id="credentialUsername" value="${this.escapeHtml(existing ? existing.username || '' : '')}" placeholder="git"></label>
+                <label>HTTPS token <input type="password" id="credentialToken" placeholder="${existing && existing.hasToken ? 'Stored - leave empty to keep' : 'Personal access token'}"></label>
+                <label>SSH key path <input type="text" id="credentialSSHKey" value="${this.escapeHtml(existing ? existing.sshKeyPath || '' : '')}" placeholder="~/.ssh/id_ed25519"></label>
+                <div class="credential-help">Credentials are stored encrypted in .gait/ and used for fetch, pull, push and clone.</div>
+            </div>
+        `;
+        modal.confirmBtn.textContent = 'Save';
+        modal.cancelBtn.textContent = 'Cancel';
+
+        const credential = await new Promise(resolve => {
+            modal.currentResolve = resolve;
+            modal.confirmBtn.onclick = () => modal.close({
+                remote: document.getElementById('credentialRemote').value.trim(),
+                username: document.getElementById('credentialUsername').value.trim(),
+                token: document.getElementById('credentialToken').value,
+                sshKeyPath: document.getElementById('credentialSSHKey').value.trim()
+            });
+            modal.show();
+        });
+        if (!credential || !credential.remote) {
+            return;
+        }
+
+        try {
+            await gAItAPI.saveCredential(credential.remote, credential.username, credential.token, credential.sshKeyPath);
+            this.showStatus(`Credentials saved for ${credential.remote}`, 'success');
+        } catch (error) {
+            this.showStatus(`Failed to save credentials: ${error.message}`, 'error');
+        }
+    }
+
+    // Show a running job's progress in the status bar with a cancel button
+    showJobProgress(label, job) {
+        const statusBar = document.getElementById('statusBar');
+        const progress = job.progress;
+        let detail = '';
+        if (progress) {
+            detail = progress.total ? ` ${progress.phase} ${progress.percent}% (${progress.current}/${progress.total})` : ` ${progress.phase}`;
+        }
+        statusBar.className = 'status-bar info';
+        statusBar.innerHTML = `${this.escapeHtml(label)}...${this.escapeHtml(detail)} <button class="job-cancel-btn" onclick="gAItUI.cancelActiveJob()">Cancel</button>`;
+    }
+
+    // Cancel the running remote job, if any
+    async cancelActiveJob() {
+        if (!this.activeJobId) {
+            return;
+        }
+        try {
+            await gAItAPI.cancelJob(this.activeJobId);
+        } catch (error) {
+            this.showStatus(`Failed to cancel: ${error.message}`, 'error');
+        }
+    }
+
+    // Summarize the ref updates of a fetch or push result
+    describeRemoteResult(result) {
+        if (!result) {
+            return 'done';
+        }
+        const updated = (result.updated || []).filter(ref => ref.status !== 'up-to-date');
+        const rejected = result.rejected || [];
+        if (updated.length === 0 && rejected.length === 0) {
+            return 'everything up to date';
+        }
+        const parts = [`${updated.length} ref${updated.length !== 1 ? 's' : ''} updated`];
+        if (rejected.length > 0) {
+            parts.push(`${rejected.length} rejected`);
+        }
+        return parts.join(', ');
+    }
+
     // Placeholder methods for advanced dialogs
     showBranchListDialog() {
         this.showStatus('Advanced branch management coming soon...', 'info');
@@ -3403,25 +3900,330 @@ class GaitUI {
         this.showStatus('Create branch from stash coming soon...', 'info');
     }
 
-    // Commit-level operations
-    async performCommitAction(action, commitHash) {
+    // Open a commit in the details view, even when it is not in the loaded commit list
+    async openCommitDetails(hash) {
+        if (document.querySelector(`[data-hash="${hash}"]`)) {
+            await this.selectCommit(hash);
+            return;
+        }
+        document.getElementById('commitDetails').classList.remove('hidden');
+        try {
+            const commit = await gAItAPI.getCommitDetails(hash);
+            this.renderCommitDetails(commit);
+        } catch (error) {
+            this.showStatus(`Failed to load commit details: ${error.message}`, 'error');
+        }
+    }
+
+    // Bisect assistant: start a session, or mark, skip, run and reset the current one
+    async showBisectDialog() {
+        let status;
+        try {
+            status = await gAItAPI.getBisectStatus();
+        } catch (error) {
+            this.showStatus(`Failed to load bisect status: ${error.message}`, 'error');
+            return;
+        }
+
+        const modal = window.modalSystem;
+        modal.currentModal = 'bisect';
+        modal.title.textContent = 'Bisect';
+
+        if (!status.inProgress) {
+            modal.body.innerHTML = `
+                <div class="credential-form">
+                    <label>Bad commit <input type="text" id="bisectBad" value="${this.escapeHtml(this.selectedCommit && this.selectedCommit !== 'uncommitted' ? this.selectedCommit : 'HEAD')}"></label>
+                    <label>Good commits <input type="text" id="bisectGood" placeholder="Known good commits or tags, e.g. v1.2.0"></label>
+                    <div class="credential-help">Bisect checks out commits between them until the first bad commit is found.</div>
+                </div>
+            `;
+            modal.confirmBtn.textContent = 'Start';
+            modal.cancelBtn.textContent = 'Cancel';
+            const refs = await new Promise(resolve => {
+                modal.currentResolve = resolve;
+                modal.confirmBtn.onclick = () => modal.close({
+                    bad: document.getElementById('bisectBad').value.trim(),
+                    good: document.getElementById('bisectGood').value.split(/[\s,]+/).filter(ref => ref)
+                });
+                modal.show();
+            });
+            if (!refs) {
+                return;
+            }
+            try {
+                const started = await gAItAPI.bisectStart(refs.bad, refs.good);
+                await this.loadData();
+                this.showStatus(`Bisecting: ${started.remaining} commits left (about ${started.steps} steps)`, 'info');
+                this.showBisectDialog();
+            } catch (error) {
+                this.showStatus(`Bisect start failed: ${error.message}`, 'error');
+            }
+            return;
+        }
+
+        const current = status.current ? status.current.substring(0, 7) : '';
+        modal.body.innerHTML = `
+            <div class="bisect-panel">
+                ${status.firstBad
+                    ? `<p>First bad commit: <code>${status.firstBad.substring(0, 7)}</code></p>
+                       <button class="action-btn primary" onclick="window.modalSystem.close('open')">Open first bad commit</button>`
+                    : `<p>Testing <code>${current}</code> ${this.escapeHtml(status.currentSubject || '')}</p>
+                       <p class="bisect-counts">${status.remaining} candidate${status.remaining !== 1 ? 's' : ''} left, about ${status.steps} more step${status.steps !== 1 ? 's' : ''}
+                       - ${status.good.length} good, ${status.skipped.length} skipped</p>
+                       <div class="bisect-actions">
+                           <button class="action-btn primary" onclick="window.modalSystem.close('good')">✅ Good</button>
+                           <button class="action-btn primary" onclick="window.modalSystem.close('bad')">❌ Bad</button>
+                           <button class="action-btn secondary" onclick="window.modalSystem.close('skip')">⏭️ Skip</button>
+                           <button class="action-btn secondary" onclick="window.modalSystem.close('run')">▶️ Run test command</button>
+                       </div>`}
+                <div class="bisect-actions">
+                    <button class="action-btn secondary" onclick="window.modalSystem.close('log')">📜 Log</button>
+                    <button class="action-btn secondary" onclick="window.modalSystem.close('reset')">↩️ End bisect</button>
+                </div>
+            </div>
+        `;
+        modal.confirmBtn.textContent = 'Close';
+        modal.cancelBtn.textContent = 'Cancel';
+
+        const action = await new Promise(resolve => {
+            modal.currentResolve = resolve;
+            modal.confirmBtn.onclick = () => modal.close(false);
+            modal.show();
+        });
+
         try {
             switch (action) {
-                case 'cherry-pick':
-                    if (confirm('Cherry-pick commit {0}?'.replace(/\{0\}/g, commitHash.substring(0, 7)))) {
-                        this.showStatus(`Cherry-picking commit ${commitHash.substring(0, 7)}...`, 'info');
-                        await gAItAPI.cherryPickCommit(commitHash);
-                        this.showStatus('Commit cherry-picked successfully', 'success');
-                        await this.loadData();
+                case 'good':
+                case 'bad':
+                case 'skip': {
+                    const result = await gAItAPI.bisectMark(action);
+                    await this.loadData();
+                    if (result.firstBad) {
+                        this.showStatus(`First bad commit: ${result.firstBad.substring(0, 7)}`, 'success');
+                        await this.openCommitDetails(result.firstBad);
                     }
+                    this.showBisectDialog();
+                    break;
+                }
+                case 'run':
+                    await this.runBisectCommand();
+                    break;
+                case 'log': {
+                    const { log } = await gAItAPI.getBisectLog();
+                    modal.currentModal = 'bisect-log';
+                    modal.title.textContent = 'Bisect Log';
+                    modal.body.innerHTML = `<pre class="sequence-output bisect-output">${this.escapeHtml(log)}</pre>`;
+                    modal.confirmBtn.textContent = 'Back';
+                    await new Promise(resolve => {
+                        modal.currentResolve = resolve;
+                        modal.confirmBtn.onclick = () => modal.close(true);
+                        modal.show();
+                    });
+                    this.showBisectDialog();
+                    break;
+                }
+                case 'open':
+                    await this.openCommitDetails(status.firstBad);
+                    break;
+                case 'reset':
+                    await gAItAPI.bisectReset();
+                    this.showStatus('Bisect ended', 'success');
+                    await this.loadData();
                     break;
+            }
+        } catch (error) {
+            this.showStatus(`Bisect ${action} failed: ${error.message}`, 'error');
+        }
+    }
+
+    // Run a test command at each bisect step, streaming its output
+    async runBisectCommand() {
+        const command = await showInputDialog({
+            title: 'Bisect Run',
+            label: 'Test command (exit 0 = good, 125 = skip, other = bad)',
+            placeholder: 'go test ./...',
+            value: localStorage.getItem('gait-bisect-command') || '',
+            required: true,
+            confirmText: 'Run'
+        });
+        if (!command) {
+            return;
+        }
+        localStorage.setItem('gait-bisect-command', command);
+
+        const modal = window.modalSystem;
+        modal.currentModal = 'bisect-run';
+        modal.title.textContent = 'Bisect Run';
+        modal.body.innerHTML = `<pre class="sequence-output bisect-output" id="bisectRunOutput"></pre>`;
+        modal.confirmBtn.textContent = 'Cancel run';
+        modal.cancelBtn.textContent = 'Hide';
+        modal.currentResolve = null;
+        modal.show();
+
+        const output = document.getElementById('bisectRunOutput');
+        let lastLine = 0;
+        const appendLine = (progress) => {
+            if (!progress || progress.current <= lastLine) {
+                return;
+            }
+            if (progress.current > lastLine + 1) {
+                output.textContent += '...\n';
+            }
+            lastLine = progress.current;
+            output.textContent += `${progress.message}\n`;
+            output.scrollTop = output.scrollHeight;
+        };
+
+        let job;
+        try {
+            job = await gAItAPI.startBisectRunJob(command);
+        } catch (error) {
+            modal.close(false);
+            this.showStatus(`Bisect run failed: ${error.message}`, 'error');
+            return;
+        }
+        this.activeJobId = job.id;
+        modal.confirmBtn.onclick = () => this.cancelActiveJob();
 
+        try {
+            const finished = await gAItAPI.watchJob(job.id, update => appendLine(update.progress));
+            const result = finished.result;
+            if (modal.currentModal === 'bisect-run') {
+                modal.close(true);
+            }
+            await this.loadData();
+            if (result && result.firstBad) {
+                this.showStatus(`First bad commit: ${result.firstBad.substring(0, 7)}`, 'success');
+                await this.openCommitDetails(result.firstBad);
+            }
+        } catch (error) {
+            if (modal.currentModal === 'bisect-run') {
+                modal.confirmBtn.textContent = 'Close';
+                modal.confirmBtn.onclick = () => modal.close(false);
+                output.textContent += `\n${error.message}\n`;
+            }
+            this.showStatus(`Bisect run failed: ${error.message}`, 'error');
+        } finally {
+            this.activeJobId = null;
+        }
+    }
+
+    // Cherry-pick or revert one or more commits, optionally onto another branch
+    async showSequenceDialog(operation, commitHash = '') {
+        const isCherryPick = operation === 'cherry-pick';
+        const branches = ((this.currentData && this.currentData.branches) || []).filter(branch => !branch.isRemote);
+        const branchOptions = branches.map(branch =>
+            `<option value="${this.escapeHtml(branch.name)}" ${branch.isCurrent ? 'selected' : ''}>${this.escapeHtml(branch.name)}</option>`
+        ).join('');
+
+        const modal = window.modalSystem;
+        modal.currentModal = 'sequence';
+        modal.title.textContent = isCherryPick ? 'Cherry-pick Commits' : 'Revert Commits';
+        modal.body.innerHTML = `
+            <div class="credential-form sequence-form">
+                <label>Commits <input type="text" id="sequenceCommits" value="${this.escapeHtml(commitHash)}" placeholder="Hashes, applied in the order given"></label>
+                <label>Range <input type="text" id="sequenceRange" placeholder="Optional, e.g. main..feature"></label>
+                <label>Onto branch <select id="sequenceBranch">${branchOptions}</select></label>
+                <label>Mainline parent <input type="number" id="sequenceMainline" min="0" value="0" title="Parent number to use for merge commits; 0 when there are none"></label>
+                ${isCherryPick ? '<label class="stash-option"><input type="checkbox" id="sequenceRecordOrigin" checked> Record origin (-x)</label>' : ''}
+                <label class="stash-option"><input type="checkbox" id="sequenceNoCommit"> Apply without committing</label>
+            </div>
+        `;
+        modal.confirmBtn.textContent = isCherryPick ? 'Cherry-pick' : 'Revert';
+        modal.cancelBtn.textContent = 'Cancel';
+
+        const options = await new Promise(resolve => {
+            modal.currentResolve = resolve;
+            modal.confirmBtn.onclick = () => {
+                const recordOrigin = document.getElementById('sequenceRecordOrigin');
+                modal.close({
+                    commits: document.getElementById('sequenceCommits').value.split(/[\s,]+/).filter(commit => commit),
+                    range: document.getElementById('sequenceRange').value.trim(),
+                    branch: document.getElementById('sequenceBranch').value,
+                    mainline: parseInt(document.getElementById('sequenceMainline').value, 10) || 0,
+                    recordOrigin: recordOrigin ? recordOrigin.checked : false,
+                    noCommit: document.getElementById('sequenceNoCommit').checked
+                });
+            };
+            modal.show();
+        });
+        if (!options) {
+            return;
+        }
+
+        this.showStatus(`${isCherryPick ? 'Cherry-picking' : 'Reverting'} commits...`, 'info');
+        const result = isCherryPick ? await gAItAPI.cherryPick(options) : await gAItAPI.revert(options);
+        await this.handleSequenceResult(result);
+    }
+
+    // Report a cherry-pick or revert result, offering continue/abort when it stopped
+    async handleSequenceResult(result) {
+        await this.loadData();
+        const label = result.operation === 'revert' ? 'Revert' : 'Cherry-pick';
+        if (!result.inProgress) {
+            const count = result.commits ? result.commits.length : 0;
+            this.showStatus(count ? `${label} of ${count} commit${count !== 1 ? 's' : ''} completed` : `${label} completed`, 'success');
+            return;
+        }
+
+        const modal = window.modalSystem;
+        modal.currentModal = 'sequence-stopped';
+        modal.title.textContent = `${label} Stopped`;
+        const conflicts = (result.conflictFiles || []).map(file => `<li>${this.escapeHtml(file)}</li>`).join('');
+        const remaining = result.remaining ? result.remaining.length : 0;
+        modal.body.innerHTML = `
+            <div class="sequence-stopped">
+                <p>${label} stopped${result.currentCommit ? ` at <code>${result.currentCommit.substring(0, 7)}</code>` : ''}${remaining ? `, ${remaining} more commit${remaining !== 1 ? 's' : ''} to go` : ''}.</p>
+                ${conflicts
+                    ? `<p>Resolve and stage these files, then continue:</p><ul>${conflicts}</ul>`
+                    : '<p>There are no conflicts left; continue to commit and carry on.</p>'}
+                ${result.output ? `<pre class="sequence-output">${this.escapeHtml(result.output)}</pre>` : ''}
+                <button class="action-btn secondary" onclick="window.modalSystem.close('abort')">Abort ${label.toLowerCase()}</button>
+            </div>
+        `;
+        modal.confirmBtn.textContent = 'Continue';
+        modal.cancelBtn.textContent = 'Later';
+
+        const choice = await new Promise(resolve => {
+            modal.currentResolve = resolve;
+            modal.confirmBtn.onclick = () => modal.close('continue');
+            modal.show();
+        });
+        if (choice === 'continue') {
+            await this.continueSequence();
+        } else if (choice === 'abort') {
+            await this.abortSequence();
+        } else {
+            this.showStatus(`${label} in progress - resolve conflicts, then continue or abort`, 'info');
+        }
+    }
+
+    async continueSequence() {
+        try {
+            const result = await gAItAPI.continueSequence();
+            await this.handleSequenceResult(result);
+        } catch (error) {
+            this.showStatus(`Continue failed: ${error.message}`, 'error');
+        }
+    }
+
+    async abortSequence() {
+        try {
+            await gAItAPI.abortSequence();
+            this.showStatus('Cherry-pick/revert aborted', 'success');
+            await this.loadData();
+        } catch (error) {
+            this.showStatus(`Abort failed: ${error.message}`, 'error');
+        }
+    }
+
+    // Commit-level operations
+    async performCommitAction(action, commitHash) {
+        try {
+            switch (action) {
+                case 'cherry-pick':
                 case 'revert':
-                    const noCommit = confirm('Revert commit {0}?'.replace(/\{0\}/g, commitHash.substring(0, 7)));
-                    this.showStatus(`Reverting commit ${commitHash.substring(0, 7)}...`, 'info');
-                    await gAItAPI.revertCommit(commitHash, !noCommit);
-                    this.showStatus(`Commit ${noCommit ? 'reverted' : 'revert staged'}`, 'success');
-                    await this.loadData();
+                    await this.showSequenceDialog(action, commitHash);
                     break;
 
                 case 'reset':
@@ -3708,10 +4510,39 @@ class GaitUI {
                 }, 300); // Give time for the DOM to update
             }
         } catch (error) {
+            if (error.violations && error.violations.length > 0) {
+                await this.handleCommitPolicyViolations(message, options, error);
+                return;
+            }
             console.error('Failed to create commit:', error);
             this.showStatus(`Failed to create commit: ${error.message}`, 'error');
         }
     }
+
+    async handleCommitPolicyViolations(message, options, error) {
+        const details = error.violations
+            .map(v => v.line ? `Line ${v.line}: ${v.message}` : v.message)
+            .join('\n');
+
+        if (!error.overridable) {
+            this.showStatus(`Commit message violates the commit policy: ${details}`, 'error');
+            return;
+        }
+
+        try {
+            const override = await showConfirmDialog({
+                title: 'Commit Policy Violations',
+                message: 'The commit message violates the commit policy. Commit anyway?',
+                details: details,
+                confirmText: 'Commit Anyway'
+            });
+            if (override) {
+                await this.createCommit(message, { ...options, override: true });
+            }
+        } catch (cancelled) {
+            this.showStatus('Commit cancelled', 'info');
+        }
+    }
 }
 
 // Repository Management UI Module
@@ -3738,6 +4569,18 @@ class RepositoryManager {
     async loadRepositories() {
         try {
             this.repositories = await gAItAPI.getRepositories();
+
+            // This tab's repository, or the server default if the tab hasn't chosen one
+            const selected = this.repositories.find(repo => repo.id === gAItAPI.repository);
+            if (gAItAPI.repository && !selected) {
+                // The repository was removed since this tab selected it
+                gAItAPI.setRepository('');
+                if (window.gAItUI) {
+                    window.gAItUI.loadData();
+                }
+            }
+            this.currentRepository = selected || this.repositories.find(repo => repo.current) || null;
+            this.updateRepositoryName();
             this.renderRepositoryList();
         } catch (error) {
             console.error('Failed to load repositories:', error);
@@ -3786,6 +4629,11 @@ class RepositoryManager {
                             <path d="M15.5 14h-.79l-.28-.27C15.41 12.59 16 11.11 16 9.5 16 5.91 13.09 3 9.5 3S3 5.91 3 9.5 5.91 16 9.5 16c1.61 0 3.09-.59 4.23-1.57l.27.28v.79l5 4.99L20.49 19l-4.99-5zm-6 0C7.01 14 5 11.99 5 9.5S7.01 5 9.5 5 14 7.01 14 9.5 11.99 14 9.5 14z"/>
                         </svg>
                     </button>
+                    <button class="btn btn-sm btn-secondary" onclick="repoManager.showOverviewDialog()" title="Overview Across Repositories">
+                        <svg width="14" height="14" viewBox="0 0 24 24" fill="currentColor">
+                            <path d="M3 13h8V3H3v10zm0 8h8v-6H3v6zm10 0h8V11h-8v10zm0-18v6h8V3h-8z"/>
+                        </svg>
+                    </button>
                     <button class="btn btn-sm btn-warning" onclick="repoManager.clearRepository()" title="Clear Repository Selection">
                         <svg width="14" height="14" viewBox="0 0 24 24" fill="currentColor">
                             <path d="M19 6.41L17.59 5 12 10.59 6.41 5 5 6.41 10.59 12 5 17.59 6.41 19 12 13.41 17.59 19 19 17.59 13.41 12z"/>
@@ -3799,19 +4647,46 @@ class RepositoryManager {
                 </div>
             </div>
             <div class="repository-list">
-                ${this.repositories.map(repo => this.renderRepositoryItem(repo)).join('')}
+                ${this.renderRepositoryGroups()}
             </div>
         `;
 
         container.innerHTML = html;
     }
 
+    // Render repositories with pinned ones first, then grouped by their group name
+    renderRepositoryGroups() {
+        const pinned = this.repositories.filter(repo => repo.pinned);
+        const groups = new Map();
+        this.repositories.filter(repo => !repo.pinned).forEach(repo => {
+            const group = repo.group || '';
+            if (!groups.has(group)) groups.set(group, []);
+            groups.get(group).push(repo);
+        });
+
+        const sections = [];
+        if (pinned.length > 0) {
+            sections.push({ title: 'Pinned', repos: pinned });
+        }
+        [...groups.keys()].sort((a, b) => (a === '') - (b === '') || a.localeCompare(b)).forEach(group => {
+            sections.push({ title: group || (sections.length > 0 ? 'Other' : ''), repos: groups.get(group) });
+        });
+
+        return sections.map(section => `
+            ${section.title ? `<div class="repository-group">${this.escapeHtml(section.title)}</div>` : ''}
+            ${section.repos.map(repo => this.renderRepositoryItem(repo)).join('')}
+        `).join('');
+    }
+
     renderRepositoryItem(repo) {
         const isActive = this.currentRepository && this.currentRepository.path === repo.path;
-        const repoName = repo.name || repo.path.split('/').pop();
+        const repoName = repo.displayName || repo.name || repo.path.split('/').pop();
+        const title = repo.lastOpened
+            ? `${repo.path}\nLast opened ${new Date(repo.lastOpened).toLocaleString()}`
+            : repo.path;
         
         return `
-            <div class="repository-item ${isActive ? 'active' : ''}" data-path="${repo.path}">
+            <div class="repository-item ${isActive ? 'active' : ''}" data-path="${repo.path}" title="${this.escapeHtml(title)}">
                 <div class="repository-info" onclick="repoManager.switchRepository('${repo.path}')">
                     <div class="repository-icon">
                         <svg width="16" height="16" viewBox="0 0 24 24" fill="currentColor">
@@ -3819,11 +4694,14 @@ class RepositoryManager {
                         </svg>
                     </div>
                     <div class="repository-details">
-                        <div class="repository-name">${this.escapeHtml(repoName)}</div>
+                        <div class="repository-name">${this.escapeHtml(repoName)}${repo.layout === 'bare' ? ' <span class="repository-tag">bare</span>' : ''}</div>
                         <div class="repository-path">${this.escapeHtml(repo.path)}</div>
+                        ${(repo.tags || []).length ? `<div class="repository-tags">${repo.tags.map(tag => `<span class="repository-tag">${this.escapeHtml(tag)}</span>`).join('')}</div>` : ''}
                     </div>
                 </div>
                 <div class="repository-actions">
+                    <button class="btn btn-xs btn-secondary ${repo.pinned ? 'pinned' : ''}" onclick="repoManager.togglePinned('${repo.path}')" title="${repo.pinned ? 'Unpin' : 'Pin'}">📌</button>
+                    <button class="btn btn-xs btn-secondary" onclick="repoManager.showRepositorySettingsDialog('${repo.path}')" title="Name and group">✎</button>
                     <button class="btn btn-xs btn-danger" onclick="repoManager.removeRepository('${repo.path}')" title="Remove">
                         <svg width="12" height="12" viewBox="0 0 24 24" fill="currentColor">
                             <path d="M19 6.41L17.59 5 12 10.59 6.41 5 5 6.41 10.59 12 5 17.59 6.41 19 12 13.41 17.59 19 19 17.59 13.41 12z"/>
@@ -3841,16 +4719,15 @@ class RepositoryManager {
             this.isLoading = true;
             this.showStatus('Switching repository...', 'info');
             
-            await gAItAPI.switchRepository(path);
-            
-            // Update current repository
-            this.currentRepository = this.repositories.find(repo => repo.path === path);
-            
-            // Update repository name immediately in the sidebar
-            const repoNameElement = document.querySelector('.repo-name');
-            if (repoNameElement && this.currentRepository) {
-                repoNameElement.textContent = this.currentRepository.name || this.currentRepository.path.split('/').pop();
+            const repository = this.repositories.find(repo => repo.path === path);
+            if (!repository) {
+                throw new Error(`Repository not found: ${path}`);
             }
+
+            // Only this tab switches; other tabs keep their own repository
+            gAItAPI.setRepository(repository.id);
+            this.currentRepository = repository;
+            this.updateRepositoryName();
             
             // Trigger repository changed event
             document.dispatchEvent(new CustomEvent('repositoryChanged', {
@@ -3875,6 +4752,299 @@ class RepositoryManager {
         }
     }
 
+    // Show recent commits, uncommitted work and branches behind upstream across a
+    // group of repositories
+    async showOverviewDialog() {
+        let groups = [];
+        try {
+            groups = await gAItAPI.getRepositoryGroups();
+        } catch (error) {
+            console.error('Failed to load repository groups:', error);
+        }
+        const tags = [...new Set(this.repositories.flatMap(repo => repo.tags || []))].sort();
+
+        const modal = window.modalSystem;
+        modal.currentModal = 'repository-overview';
+        modal.title.textContent = 'Repository Overview';
+        modal.body.innerHTML = `
+            <div class="repository-overview">
+                <div class="overview-filters">
+                    <select id="overviewGroup">
+                        <option value="">All repositories</option>
+                        ${groups.map(group => `<option value="${this.escapeHtml(group.name)}">${this.escapeHtml(group.name)} (${group.repositories})</option>`).join('')}
+                    </select>
+                    <select id="overviewTag">
+                        <option value="">Any tag</option>
+                        ${tags.map(tag => `<option value="${this.escapeHtml(tag)}">${this.escapeHtml(tag)}</option>`).join('')}
+                    </select>
+                </div>
+                <div class="overview-tabs">
+                    <button class="action-btn secondary active" data-view="commits">Recent commits</button>
+                    <button class="action-btn secondary" data-view="uncommitted">Uncommitted work</button>
+                    <button class="action-btn secondary" data-view="behind">Behind upstream</button>
+                    <button class="action-btn secondary" data-view="search">Search</button>
+                </div>
+                <form id="overviewSearch" class="overview-search hidden">
+                    <input type="text" id="overviewQuery" placeholder="Search commits in every repository...">
+                    ${['message', 'author', 'path', 'code'].map(type => `
+                        <label><input type="checkbox" name="overviewSearchType" value="${type}" ${type !== 'code' ? 'checked' : ''}> ${type}</label>
+                    `).join('')}
+                    <button type="submit" class="action-btn primary">Search</button>
+                </form>
+                <div id="overviewContent" class="overview-content"></div>
+            </div>
+        `;
+        modal.confirmBtn.textContent = 'Close';
+        modal.cancelBtn.textContent = 'Cancel';
+
+        let view = 'commits';
+        const searchForm = document.getElementById('overviewSearch');
+        const load = () => {
+            const group = document.getElementById('overviewGroup').value;
+            const tag = document.getElementById('overviewTag').value;
+            if (view === 'search') {
+                const types = [...searchForm.querySelectorAll('input[name="overviewSearchType"]:checked')].map(input => input.value);
+                this.loadSearchResults(document.getElementById('overviewQuery').value, types, group, tag);
+            } else {
+                this.loadOverview(view, group, tag);
+            }
+        };
+        modal.body.querySelectorAll('.overview-tabs button').forEach(button => {
+            button.onclick = () => {
+                modal.body.querySelectorAll('.overview-tabs button').forEach(b => b.classList.remove('active'));
+                button.classList.add('active');
+                view = button.dataset.view;
+                searchForm.classList.toggle('hidden', view !== 'search');
+                if (view === 'search') {
+                    document.getElementById('overviewQuery').focus();
+                }
+                load();
+            };
+        });
+        searchForm.onsubmit = event => {
+            event.preventDefault();
+            load();
+        };
+        document.getElementById('overviewGroup').onchange = load;
+        document.getElementById('overviewTag').onchange = load;
+        load();
+
+        // Rows close the dialog with the repository ID, search results with "id:hash"
+        const selection = await new Promise(resolve => {
+            modal.currentResolve = resolve;
+            modal.confirmBtn.onclick = () => modal.close(null);
+            modal.show();
+        });
+        const [repositoryId, hash] = (selection || '').split(':');
+        const repository = this.repositories.find(repo => repo.id === repositoryId);
+        if (repository) {
+            await this.switchRepository(repository.path);
+            if (hash && window.gAItUI) {
+                window.gAItUI.selectCommit(hash);
+            }
+        }
+    }
+
+    async loadSearchResults(query, types, group, tag) {
+        const content = document.getElementById('overviewContent');
+        if (!content) return;
+        if (!query.trim()) {
+            content.innerHTML = '<div class="overview-empty">Enter a query to search commit messages, authors, paths and code</div>';
+            return;
+        }
+        content.innerHTML = '<div class="overview-empty">Searching...</div>';
+
+        try {
+            const result = await gAItAPI.searchRepositories(query, types, group, tag);
+            const html = (result.results || []).map(match => `
+                <div class="overview-row" onclick="window.modalSystem.close('${match.repositoryId}:${match.commitHash}')" title="Open commit">
+                    <span class="overview-repo">${this.escapeHtml(match.repositoryName)}</span>
+                    <code>${this.escapeHtml(match.commitHash.substring(0, 7))}</code>
+                    <span class="overview-text">${this.escapeHtml(match.subject)}</span>
+                    <span class="overview-meta">${this.escapeHtml(match.type)}: ${this.escapeHtml(match.match)}</span>
+                </div>
+            `).join('');
+            const errors = (result.errors || []).map(error =>
+                `<div class="overview-error">${this.escapeHtml(error.repositoryName)}: ${this.escapeHtml(error.error)}</div>`
+            ).join('');
+            content.innerHTML = (html || `<div class="overview-empty">No matches in ${result.repositories} repositories</div>`) + errors;
+        } catch (error) {
+            content.innerHTML = `<div class="overview-error">${this.escapeHtml(error.message)}</div>`;
+        }
+    }
+
+    async loadOverview(view, group, tag) {
+        const content = document.getElementById('overviewContent');
+        if (!content) return;
+        content.innerHTML = '<div class="overview-empty">Loading...</div>';
+
+        try {
+            const result = await gAItAPI.getRepositoryView(view, group, tag);
+            const items = result.items || [];
+            const open = id => `onclick="window.modalSystem.close('${id}')" title="Open repository"`;
+            let html = '';
+            if (view === 'commits') {
+                html = items.map(commit => `
+                    <div class="overview-row" ${open(commit.repositoryId)}>
+                        <span class="overview-repo">${this.escapeHtml(commit.repositoryName)}</span>
+                        <code>${this.escapeHtml(commit.shortHash)}</code>
+                        <span class="overview-text">${this.escapeHtml(commit.message)}</span>
+                        <span class="overview-meta">${this.escapeHtml(commit.author.name)}, ${new Date(commit.date).toLocaleString()}</span>
+                    </div>
+                `).join('');
+            } else if (view === 'uncommitted') {
+                html = items.map(work => `
+                    <div class="overview-row" ${open(work.repositoryId)}>
+                        <span class="overview-repo">${this.escapeHtml(work.repositoryName)}</span>
+                        <span class="overview-text">${this.escapeHtml(work.branch || 'detached HEAD')}</span>
+                        <span class="overview-meta">${work.changes.length} changed file${work.changes.length !== 1 ? 's' : ''}</span>
+                    </div>
+                `).join('');
+            } else {
+                html = items.map(repo => repo.branches.map(branch => `
+                    <div class="overview-row" ${open(repo.repositoryId)}>
+                        <span class="overview-repo">${this.escapeHtml(repo.repositoryName)}</span>
+                        <span class="overview-text">${this.escapeHtml(branch.name)} ← ${this.escapeHtml(branch.upstream)}</span>
+                        <span class="overview-meta">${branch.behind} behind${branch.ahead ? `, ${branch.ahead} ahead` : ''}</span>
+                    </div>
+                `).join('')).join('');
+            }
+
+            const empty = {
+                commits: 'No commits',
+                uncommitted: 'No uncommitted work',
+                behind: 'Everything is up to date with its upstream (as of the last fetch)'
+            }[view];
+            const errors = (result.errors || []).map(error =>
+                `<div class="overview-error">${this.escapeHtml(error.repositoryName)}: ${this.escapeHtml(error.error)}</div>`
+            ).join('');
+            content.innerHTML = (html || `<div class="overview-empty">${empty}</div>`) + errors;
+        } catch (error) {
+            content.innerHTML = `<div class="overview-error">${this.escapeHtml(error.message)}</div>`;
+        }
+    }
+
+    async togglePinned(path) {
+        const repository = this.repositories.find(repo => repo.path === path);
+        if (!repository) return;
+        await this.updateMetadata(path, { pinned: !repository.pinned });
+    }
+
+    async showRepositorySettingsDialog(path) {
+        const repository = this.repositories.find(repo => repo.path === path);
+        if (!repository) return;
+
+        const groups = [...new Set(this.repositories.map(repo => repo.group).filter(group => group))];
+        const modal = window.modalSystem;
+        modal.currentModal = 'repository-settings';
+        modal.title.textContent = 'Repository Settings';
+        modal.body.innerHTML = `
+            <div class="credential-form">
+                <label>Display name <input type="text" id="repositoryDisplayName" value="${this.escapeHtml(repository.displayName || '')}" placeholder="${this.escapeHtml(repository.name)}"></label>
+                <label>Group <input type="text" id="repositoryGroup" list="repositoryGroups" value="${this.escapeHtml(repository.group || '')}" placeholder="None"></label>
+                <datalist id="repositoryGroups">${groups.map(group => `<option value="${this.escapeHtml(group)}">`).join('')}</datalist>
+                <label>Tags <input type="text" id="repositoryTags" value="${this.escapeHtml((repository.tags || []).join(', '))}" placeholder="Comma separated"></label>
+                <label class="stash-option"><input type="checkbox" id="repositoryPinned" ${repository.pinned ? 'checked' : ''}> Pinned</label>
+            </div>
+            <div id="repositoryCloneInfo" class="clone-info"></div>
+        `;
+        modal.confirmBtn.textContent = 'Save';
+        modal.cancelBtn.textContent = 'Cancel';
+        if (repository.layout !== 'bare') {
+            this.renderCloneInfo(repository.id);
+        }
+
+        const metadata = await new Promise(resolve => {
+            modal.currentResolve = resolve;
+            modal.confirmBtn.onclick = () => modal.close({
+                displayName: document.getElementById('repositoryDisplayName').value.trim(),
+                group: document.getElementById('repositoryGroup').value.trim(),
+                tags: document.getElementById('repositoryTags').value.split(',').map(tag => tag.trim()).filter(tag => tag),
+                pinned: document.getElementById('repositoryPinned').checked
+            });
+            modal.show();
+        });
+        if (metadata) {
+            await this.updateMetadata(path, metadata);
+        }
+    }
+
+    // Show how much of its remote a repository holds, with controls to change its
+    // sparse-checkout directories and fetch more history into a shallow clone
+    async renderCloneInfo(repositoryId) {
+        const container = document.getElementById('repositoryCloneInfo');
+        if (!container) return;
+
+        let info;
+        try {
+            info = await gAItAPI.getCloneInfo(repositoryId);
+        } catch (error) {
+            container.innerHTML = `<div class="overview-error">${this.escapeHtml(error.message)}</div>`;
+            return;
+        }
+
+        const facts = [];
+        if (info.shallow) facts.push(`Shallow clone (${info.shallowCommits} boundary commit${info.shallowCommits !== 1 ? 's' : ''})`);
+        if (info.filter) facts.push(`Partial clone (${this.escapeHtml(info.filter)})`);
+        if (info.singleBranch) facts.push('Single branch');
+        const sparse = info.sparseCheckout;
+        container.innerHTML = `
+            <div class="clone-info-facts">${facts.length ? facts.join(' · ') : 'Full clone'}</div>
+            ${info.shallow ? `
+                <div class="clone-info-actions">
+                    <button class="action-btn secondary" id="cloneDeepen">Fetch 100 more commits</button>
+                    <button class="action-btn secondary" id="cloneUnshallow">Fetch full history</button>
+                </div>
+            ` : ''}
+            <label class="clone-info-label">Sparse checkout ${sparse.enabled ? (sparse.cone ? '(directories)' : '(patterns)') : '(off)'}</label>
+            <textarea id="sparsePatterns" rows="3" placeholder="One directory per line">${this.escapeHtml(sparse.patterns.join('\n'))}</textarea>
+            <div class="clone-info-actions">
+                <button class="action-btn secondary" id="sparseApply">${sparse.enabled ? 'Apply' : 'Enable'}</button>
+                ${sparse.enabled ? '<button class="action-btn secondary" id="sparseDisable">Check out everything</button>' : ''}
+            </div>
+        `;
+
+        const run = async (label, action) => {
+            try {
+                await action();
+                this.showStatus(`${label} done`, 'success');
+                if (window.gAItUI && gAItAPI.repository === repositoryId) {
+                    window.gAItUI.loadData();
+                }
+            } catch (error) {
+                this.showStatus(`${label} failed: ${error.message}`, 'error');
+            }
+            this.renderCloneInfo(repositoryId);
+        };
+        const deepen = (depth, unshallow) => run('Fetching history', () =>
+            this.runRemoteJob('Fetching history', gAItAPI.startDeepenJob(repositoryId, depth, unshallow)));
+        const deepenButton = document.getElementById('cloneDeepen');
+        if (deepenButton) deepenButton.onclick = () => deepen(100, false);
+        const unshallowButton = document.getElementById('cloneUnshallow');
+        if (unshallowButton) unshallowButton.onclick = () => deepen(0, true);
+        document.getElementById('sparseApply').onclick = () => {
+            const patterns = document.getElementById('sparsePatterns').value.split('\n').map(line => line.trim()).filter(line => line);
+            run('Sparse checkout', () => gAItAPI.setSparseCheckout(repositoryId, patterns, !sparse.enabled || sparse.cone));
+        };
+        const disableButton = document.getElementById('sparseDisable');
+        if (disableButton) disableButton.onclick = () => run('Sparse checkout', () => gAItAPI.disableSparseCheckout(repositoryId));
+    }
+
+    async updateMetadata(path, metadata) {
+        try {
+            const updated = await gAItAPI.updateRepositoryMetadata(path, metadata);
+            this.repositories = this.repositories.map(repo => repo.path === path ? updated : repo);
+            if (this.currentRepository && this.currentRepository.path === path) {
+                this.currentRepository = updated;
+                this.updateRepositoryName();
+            }
+            this.renderRepositoryList();
+        } catch (error) {
+            console.error('Failed to update repository:', error);
+            this.showStatus('Failed to update repository: ' + error.message, 'error');
+        }
+    }
+
     showAddRepositoryDialog() {
         const path = prompt('Enter the path to the local Git repository:');
         if (path && path.trim()) {
@@ -3894,18 +5064,53 @@ class RepositoryManager {
         }
     }
 
-    showCloneRepositoryDialog() {
-        const url = prompt('Enter the Git repository URL to clone:');
-        if (url && url.trim()) {
-            const name = prompt('Enter repository name (optional):', '');
-            this.cloneRepository(url.trim(), name ? name.trim() : '');
+    async showCloneRepositoryDialog() {
+        const modal = window.modalSystem;
+        modal.currentModal = 'repository-clone';
+        modal.title.textContent = 'Clone Repository';
+        modal.body.innerHTML = `
+            <div class="credential-form">
+                <label>URL <input type="text" id="cloneURL" placeholder="https://github.com/user/repo.git"></label>
+                <label>Name <input type="text" id="cloneName" placeholder="From the URL"></label>
+                <label>Branch <input type="text" id="cloneBranch" placeholder="Remote default"></label>
+                <label class="stash-option"><input type="checkbox" id="cloneSingleBranch"> Only this branch</label>
+                <label>Depth <input type="number" id="cloneDepth" min="0" placeholder="Full history"></label>
+                <label>Partial clone
+                    <select id="cloneFilter">
+                        <option value="">Everything</option>
+                        <option value="blob:none">Without file contents (blob:none)</option>
+                        <option value="tree:0">Without trees (tree:0)</option>
+                    </select>
+                </label>
+                <label>Sparse checkout <input type="text" id="cloneSparse" placeholder="Directories, comma separated; empty for all"></label>
+            </div>
+        `;
+        modal.confirmBtn.textContent = 'Clone';
+        modal.cancelBtn.textContent = 'Cancel';
+
+        const request = await new Promise(resolve => {
+            modal.currentResolve = resolve;
+            modal.confirmBtn.onclick = () => modal.close({
+                url: document.getElementById('cloneURL').value.trim(),
+                name: document.getElementById('cloneName').value.trim(),
+                options: {
+                    branch: document.getElementById('cloneBranch').value.trim(),
+                    singleBranch: document.getElementById('cloneSingleBranch').checked,
+                    depth: parseInt(document.getElementById('cloneDepth').value, 10) || 0,
+                    filter: document.getElementById('cloneFilter').value,
+                    sparse: document.getElementById('cloneSparse').value.split(',').map(dir => dir.trim()).filter(dir => dir)
+                }
+            });
+            modal.show();
+        });
+        if (request && request.url) {
+            this.cloneRepository(request.url, request.name, request.options);
         }
     }
 
-    async cloneRepository(url, name = '') {
+    async cloneRepository(url, name = '', options = {}) {
         try {
-            this.showStatus('Cloning repository...', 'info');
-            await gAItAPI.cloneRepository(url, name);
+            await this.runRemoteJob('Cloning repository', gAItAPI.startCloneJob(url, name, options));
             await this.loadRepositories();
             this.showStatus('Repository cloned successfully', 'success');
         } catch (error) {
@@ -3946,16 +5151,12 @@ class RepositoryManager {
     async clearRepository() {
         try {
             this.showStatus('Clearing repository selection...', 'info');
+            // Clear this tab's selection and the server default it would fall back to
+            gAItAPI.setRepository('');
             await gAItAPI.clearRepository();
             
-            // Update current repository
             this.currentRepository = null;
-            
-            // Update repository name in the sidebar
-            const repoNameElement = document.querySelector('.repo-name');
-            if (repoNameElement) {
-                repoNameElement.textContent = 'No Repository Selected';
-            }
+            this.updateRepositoryName();
             
             // Trigger repository changed event
             document.dispatchEvent(new CustomEvent('repositoryChanged', {
@@ -3982,17 +5183,70 @@ class RepositoryManager {
         }
     }
 
+    // Preview the repositories in the workspace and add the ones chosen
     async discoverRepositories() {
-        try {
-            this.showStatus('Discovering repositories...', 'info');
-            const discovered = await gAItAPI.discoverRepositories();
-            
-            if (discovered && discovered.length > 0) {
-                await this.addMultipleRepositories(discovered);
-                this.showStatus(`Discovered and added ${discovered.length} repositories`, 'success');
-            } else {
-                this.showStatus('No new repositories discovered', 'info');
+        const modal = window.modalSystem;
+        modal.currentModal = 'repository-discovery';
+        modal.title.textContent = 'Discover Repositories';
+        modal.body.innerHTML = `
+            <div class="repository-discovery">
+                <div class="credential-form">
+                    <label>Max depth <input type="number" id="discoveryDepth" min="1" max="10" value="3"></label>
+                    <label>Exclude <input type="text" id="discoveryExclude" placeholder="node_modules, vendor/ (besides .gaitignore)"></label>
+                </div>
+                <div class="overview-tabs">
+                    <button class="action-btn secondary" id="discoveryScan">Scan</button>
+                </div>
+                <div id="discoveryResults" class="overview-content"></div>
+            </div>
+        `;
+        modal.confirmBtn.textContent = 'Add Selected';
+        modal.cancelBtn.textContent = 'Cancel';
+
+        const options = () => ({
+            maxDepth: parseInt(document.getElementById('discoveryDepth').value, 10) || 3,
+            exclude: document.getElementById('discoveryExclude').value.split(',').map(pattern => pattern.trim()).filter(pattern => pattern)
+        });
+        const scan = async () => {
+            const results = document.getElementById('discoveryResults');
+            results.innerHTML = '<div class="overview-empty">Scanning...</div>';
+            try {
+                const { maxDepth, exclude } = options();
+                const discovered = await gAItAPI.previewDiscovery(maxDepth, exclude);
+                results.innerHTML = discovered.map(repo => `
+                    <label class="overview-row">
+                        <input type="checkbox" name="discoveredRepository" value="${this.escapeHtml(repo.path)}" ${repo.managed ? 'disabled' : 'checked'}>
+                        <span class="overview-repo">${this.escapeHtml(repo.name)}</span>
+                        <span class="overview-text" title="${this.escapeHtml(repo.gitDir || repo.path)}">${this.escapeHtml(repo.path)}</span>
+                        <span class="overview-meta">${this.escapeHtml(repo.layout)}${repo.managed ? ', already added' : ''}</span>
+                    </label>
+                `).join('') || '<div class="overview-empty">No repositories found</div>';
+            } catch (error) {
+                results.innerHTML = `<div class="overview-error">${this.escapeHtml(error.message)}</div>`;
             }
+        };
+        document.getElementById('discoveryScan').onclick = scan;
+        scan();
+
+        const selection = await new Promise(resolve => {
+            modal.currentResolve = resolve;
+            modal.confirmBtn.onclick = () => modal.close({
+                ...options(),
+                paths: [...modal.body.querySelectorAll('input[name="discoveredRepository"]:checked:not(:disabled)')].map(input => input.value)
+            });
+            modal.show();
+        });
+        if (!selection) return;
+        if (selection.paths.length === 0) {
+            this.showStatus('No repositories selected', 'info');
+            return;
+        }
+
+        try {
+            this.showStatus('Adding repositories...', 'info');
+            const result = await gAItAPI.discoverRepositories(selection.maxDepth, selection.exclude, selection.paths);
+            await this.loadRepositories();
+            this.showStatus(`Added ${result.added.length} repositories`, 'success');
         } catch (error) {
             console.error('Failed to discover repositories:', error);
             this.showStatus('Failed to discover repositories: ' + error.message, 'error');
@@ -4010,15 +5264,19 @@ class RepositoryManager {
             
             // Update UI to reflect the new repository
             this.renderRepositoryList();
-            
-            // Update the repository name in the sidebar
-            const repoNameElement = document.querySelector('.repo-name');
-            if (repoNameElement) {
-                repoNameElement.textContent = detail.repository.name || detail.repository.path.split('/').pop();
-            }
+            this.updateRepositoryName();
         }
     }
 
+    // Show the current repository's name in the sidebar
+    updateRepositoryName() {
+        const repoNameElement = document.querySelector('.repo-name');
+        if (!repoNameElement) return;
+        repoNameElement.textContent = this.currentRepository
+            ? (this.currentRepository.displayName || this.currentRepository.name || this.currentRepository.path.split('/').pop())
+            : 'No Repository Selected';
+    }
+
     showStatus(message, type = 'info') {
         // Use the main UI's status system if available
         if (window.gAItUI && typeof window.gAItUI.showStatus === 'function') {
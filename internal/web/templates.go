@@ -5,6 +5,7 @@ import (
 	"html/template"
 	"net/http"
 	"path/filepath"
+	"sync"
 	"time"
 
 	"github.com/knoxai/gait/pkg/types"
@@ -36,7 +37,9 @@ type CommitListData struct {
 
 // Server handles web requests
 type Server struct {
-	repoName string
+	repoNameMu  sync.RWMutex
+	repoName    string
+	repoNameFor func(r *http.Request) string
 	template *template.Template
 	commitListTemplate *template.Template
 	dashboardTemplate *template.Template
@@ -54,6 +57,7 @@ func NewServer(repoName string) *Server {
 			}
 			return hash
 		},
+		"signatureLabel": signatureLabel,
 	}
 
 	// Parse the main template with helper functions
@@ -75,9 +79,10 @@ func NewServer(repoName string) *Server {
 
 // ServeIndex serves the main HTML page using embedded template
 func (s *Server) ServeIndex(w http.ResponseWriter, r *http.Request) {
+	repoName := s.requestRepoName(r)
 	data := TemplateData{
-		RepoName: s.repoName,
-		Title:    "GAIT - " + s.repoName,
+		RepoName: repoName,
+		Title:    "GAIT - " + repoName,
 	}
 
 	w.Header().Set("Content-Type", "text/html")
@@ -127,9 +132,10 @@ func (s *Server) ServeStatic(w http.ResponseWriter, r *http.Request) {
 
 // ServeDashboard serves the enhanced ADES dashboard
 func (s *Server) ServeDashboard(w http.ResponseWriter, r *http.Request) {
+	repoName := s.requestRepoName(r)
 	data := TemplateData{
-		RepoName: s.repoName,
-		Title:    "ADES Dashboard - " + s.repoName,
+		RepoName: repoName,
+		Title:    "ADES Dashboard - " + repoName,
 	}
 
 	w.Header().Set("Content-Type", "text/html")
@@ -139,11 +145,31 @@ func (s *Server) ServeDashboard(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-// UpdateRepoName updates the repository name
+// UpdateRepoName updates the name shown when a page request selects no repository
 func (s *Server) UpdateRepoName(repoPath string) {
+	s.repoNameMu.Lock()
+	defer s.repoNameMu.Unlock()
 	s.repoName = filepath.Base(repoPath)
 }
 
+// SetRepoNameResolver sets the function returning the name of the repository a page
+// request selects, or "" if it selects none
+func (s *Server) SetRepoNameResolver(resolver func(r *http.Request) string) {
+	s.repoNameFor = resolver
+}
+
+// requestRepoName returns the repository name to show for a page request
+func (s *Server) requestRepoName(r *http.Request) string {
+	if s.repoNameFor != nil {
+		if name := s.repoNameFor(r); name != "" {
+			return name
+		}
+	}
+	s.repoNameMu.RLock()
+	defer s.repoNameMu.RUnlock()
+	return s.repoName
+}
+
 // formatDate formats a time.Time to a readable string
 func formatDate(t time.Time) string {
 	if t.IsZero() {
@@ -152,10 +178,27 @@ func formatDate(t time.Time) string {
 	return t.Format("2006-01-02 15:04")
 }
 
+// signatureLabel returns the badge text for a commit signature status
+func signatureLabel(signature *types.SignatureStatus) string {
+	switch signature.Status {
+	case "good":
+		return "Verified"
+	case "untrusted":
+		return "Untrusted"
+	case "bad":
+		return "Bad signature"
+	case "expired":
+		return "Expired"
+	case "revoked":
+		return "Revoked"
+	}
+	return "Unverified"
+}
+
 // Commit list partial template for server-side rendering
 const commitListTemplate = `{{range .Commits}}
 <li class="commit-item" onclick="gAItUI.selectCommit('{{.Hash}}')" data-hash="{{.Hash}}">
-    <div class="commit-hash">{{shortHash .Hash}}</div>
+    <div class="commit-hash">{{shortHash .Hash}}{{with .Signature}} <span class="signature-badge signature-{{.Status}}" title="{{signatureLabel .}}{{if .Signer}}: {{.Signer}}{{end}}">{{if .Verified}}✓{{else}}⚠{{end}} {{signatureLabel .}}</span>{{end}}</div>
     <div class="commit-message">{{escapeHtml .Message}}</div>
     <div class="commit-meta">
         <span class="commit-author">{{escapeHtml .Author.Name}}</span>
@@ -249,10 +292,17 @@ const optimizedTemplate = `<!DOCTYPE html>
                         <span class="btn-text">Push</span>
                     </button>
                 </div>
+                <div class="toolbar-separator"></div>
+                <div class="toolbar-group">
+                    <button class="toolbar-btn" onclick="gAItUI.showBisectDialog()" title="Find the commit that introduced a bug">
+                        <span class="btn-icon">🔍</span>
+                        <span class="btn-text">Bisect</span>
+                    </button>
+                </div>
             </div>
         </div>
         <div class="controls">
-            <button class="btn" onclick="window.location.href='/dashboard'">🚀 Dashboard</button>
+            <button class="btn" onclick="window.location.href=gAItAPI.repositoryURL('/dashboard')">🚀 Dashboard</button>
             <button class="btn" onclick="toggleSearch()">Search (Ctrl+F)</button>
             <button class="btn secondary" onclick="refreshData()">Refresh (Ctrl+R)</button>
         </div>
@@ -325,6 +375,13 @@ const optimizedTemplate = `<!DOCTYPE html>
         <div class="commit-area">
             <div class="search-box" id="searchBox" style="display: none;">
                 <input type="text" class="search-input" id="searchInput" placeholder="Search commits..." onkeyup="handleSearch(event)">
+                <select class="signature-filter" id="signatureFilter" onchange="gAItUI.setSignatureFilter(this.value)" title="Filter by signature">
+                    <option value="">All commits</option>
+                    <option value="verified">Verified</option>
+                    <option value="unverified">Unverified</option>
+                    <option value="signed">Signed</option>
+                    <option value="unsigned">Unsigned</option>
+                </select>
             </div>
             
             <div style="display: flex; flex: 1; overflow: hidden;" id="mainPanels">
@@ -695,7 +752,7 @@ const dashboardTemplate = `<!DOCTYPE html>
                         <button class="interactive-button" data-action="view-patterns">
                             🔍 View Patterns
                         </button>
-                        <button class="interactive-button" onclick="window.location.href='/api/ades/knowledge/export'">
+                        <button class="interactive-button" onclick="window.location.href=repositoryURL('/api/ades/knowledge/export')">
                             📊 Export Knowledge Graph
                         </button>
                     </div>
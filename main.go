@@ -1,6 +1,8 @@
 package main
 
 import (
+	"bufio"
+	"context"
 	"encoding/json"
 	"flag"
 	"fmt"
@@ -8,17 +10,21 @@ import (
 	"net/http"
 	"os"
 	"path/filepath"
+	"strings"
+	"sync"
 
 	"github.com/knoxai/gait/internal/ades"
 	"github.com/knoxai/gait/internal/ades/mcp"
 	"github.com/knoxai/gait/internal/api"
+	"github.com/knoxai/gait/internal/audit"
+	"github.com/knoxai/gait/internal/auth"
 	"github.com/knoxai/gait/internal/git"
+	"github.com/knoxai/gait/internal/jobs"
 	"github.com/knoxai/gait/internal/web"
 	"github.com/knoxai/gait/internal/webhooks"
 	// "github.com/knoxai/gait/internal/graphql"  // Temporarily disabled due to network issues
 	"github.com/knoxai/gait/internal/docs"
 	"github.com/knoxai/gait/internal/ai"
-	"github.com/knoxai/gait/pkg/types"
 	"github.com/knoxai/gait/internal/ades/config"
 	"github.com/gorilla/mux"
 )
@@ -29,24 +35,70 @@ func main() {
 		repo      = flag.String("repo", "", "Path to the Git repository (optional, will discover repos if not provided)")
 		workspace = flag.String("workspace", ".", "Workspace path to discover repositories")
 		demoSprint2 = flag.Bool("demo-sprint2", false, "Run ADES Sprint 2 demo")
+		authConfig  = flag.String("auth-config", "", "Authentication config (default <workspace>/.gait/auth.json)")
+		hashPassword = flag.Bool("hash-password", false, "Read a password from stdin and print its bcrypt hash for the auth config")
+		timeoutConfig = flag.String("timeout-config", "", "Git command timeouts (default <workspace>/.gait/timeouts.json)")
 	)
 	flag.Parse()
 
+	if *hashPassword {
+		password, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil && password == "" {
+			log.Fatalf("Failed to read password: %v", err)
+		}
+		hash, err := auth.HashPassword(strings.TrimRight(password, "\r\n"))
+		if err != nil {
+			log.Fatalf("Failed to hash password: %v", err)
+		}
+		fmt.Println(hash)
+		return
+	}
+
 	// Check if demo is requested
 	if *demoSprint2 {
 		ades.RunSprint2Demo()
 		return
 	}
 
-	var gitService *git.Service
-	var repositories []types.Repository
-
 	// Initialize repository manager
 	workspacePath, err := filepath.Abs(*workspace)
 	if err != nil {
 		log.Fatalf("Invalid workspace path: %v", err)
 	}
 	
+	// Authentication is on when an auth config exists
+	if *authConfig == "" {
+		*authConfig = filepath.Join(workspacePath, ".gait", "auth.json")
+	}
+	authSettings, err := auth.LoadConfig(*authConfig)
+	if err != nil {
+		log.Fatalf("Failed to load auth config: %v", err)
+	}
+	var authService *auth.Service
+	if authSettings != nil {
+		if authService, err = auth.New(authSettings); err != nil {
+			log.Fatalf("Invalid auth config %s: %v", *authConfig, err)
+		}
+	}
+
+	// Git command timeouts, when configured, replace the defaults
+	if *timeoutConfig == "" {
+		*timeoutConfig = filepath.Join(workspacePath, ".gait", "timeouts.json")
+	}
+	timeouts, err := git.LoadTimeouts(*timeoutConfig)
+	if err != nil {
+		log.Fatalf("Failed to load timeout config: %v", err)
+	}
+	if timeouts != nil {
+		git.SetTimeouts(*timeouts)
+	}
+
+	// Audit log of every operation that changes something
+	auditLog, err := audit.Open(filepath.Join(workspacePath, ".gait", "audit.db"))
+	if err != nil {
+		log.Printf("Audit log disabled: %v", err)
+	}
+
 	repoManager := api.NewRepositoryManager(workspacePath)
 	if err := repoManager.LoadRepositories(); err != nil {
 		log.Printf("Warning: Failed to load saved repositories: %v", err)
@@ -54,50 +106,32 @@ func main() {
 
 	if *repo != "" {
 		// Single repository mode - add to manager if not already present
-		repoPath, err := filepath.Abs(*repo)
+		resolved, err := git.ResolveRepository(*repo)
 		if err != nil {
-			log.Fatalf("Invalid repository path: %v", err)
-		}
-
-		// Check if it's a valid Git repository
-		if _, err := os.Stat(filepath.Join(repoPath, ".git")); os.IsNotExist(err) {
-			log.Fatalf("Not a Git repository: %s", repoPath)
+			log.Fatalf("%v", err)
 		}
+		repoPath := resolved.Path
 
 		// Add to repository manager
 		if err := repoManager.AddRepository(repoPath); err != nil {
 			log.Printf("Warning: Failed to add repository to manager: %v", err)
 		}
 
-		gitService = git.NewService(repoPath)
-		repositories = []types.Repository{{Name: filepath.Base(repoPath), Path: repoPath}}
+		if _, err := repoManager.SwitchRepository(repoPath); err != nil {
+			log.Fatalf("Failed to open repository: %v", err)
+		}
 	} else {
 		// Multi-repository mode - only use explicitly managed repositories
-		repositories = repoManager.GetRepositories()
+		repositories := repoManager.GetRepositories()
 		if len(repositories) == 0 {
 			log.Printf("No managed repositories found. Use the web interface to add repositories.")
-			// Don't create any git service - let the frontend handle the empty state
-			gitService = nil
+		} else if _, err := repoManager.SwitchRepository(repositories[0].Path); err != nil {
+			// Default to first repository for requests that don't select one
+			log.Printf("Warning: Failed to set default repository: %v", err)
 		} else {
-			// Default to first repository and set it as current in the manager
-			firstRepoPath := repositories[0].Path
-			var err error
-			gitService, err = repoManager.SwitchRepository(firstRepoPath)
-			if err != nil {
-				log.Printf("Warning: Failed to set default repository: %v", err)
-				gitService = nil
-			} else {
-				// Update repositories list to get the current marking
-				repositories = repoManager.GetRepositories()
-				log.Printf("Found %d managed repositories, defaulting to: %s", len(repositories), repositories[0].Name)
-			}
+			log.Printf("Found %d managed repositories, defaulting to: %s", len(repositories), repositories[0].Name)
 		}
 	}
-
-	// Initialize ADES service if enabled
-	var adesService *ades.Service
-	var adesHandler *api.ADESHandler
-	var mcpServer *mcp.MCPServer
 	
 	// Load ADES configuration
 	adesConfig, err := config.LoadConfig(config.GetConfigPath())
@@ -110,47 +144,31 @@ func main() {
 	if err := adesConfig.Validate(); err != nil {
 		log.Printf("Warning: ADES configuration is invalid: %v", err)
 	}
-	
-	if gitService != nil {
-		log.Println("Initializing ADES service...")
-		adesService, err = ades.NewService(gitService, adesConfig)
-		if err != nil {
-			log.Printf("Warning: Failed to initialize ADES service: %v", err)
-		} else {
-			adesHandler = api.NewADESHandler(adesService)
-			log.Println("ADES service initialized successfully")
-			
-			// Initialize MCP server for Sprint 3
-			log.Println("Initializing MCP server...")
-			mcpServer = mcp.NewMCPServer(adesService)
-			log.Println("MCP server initialized successfully")
-		}
-	}
+
+	// ADES, MCP, the dashboard WebSocket and webhooks are started per repository the
+	// first time a request selects it
+	adesPool := newADESPool(adesConfig, os.Getenv("ADES_WEBHOOK_SECRET"))
 
 	// Create web server and handlers with optimized architecture
-	var repoName string
-	if gitService != nil {
-		repoName = filepath.Base(gitService.GetRepoPath())
-	} else {
-		repoName = "No Repository Selected"
+	repoName := "No Repository Selected"
+	if defaultService := repoManager.DefaultService(); defaultService != nil {
+		repoName = filepath.Base(defaultService.GetRepoPath())
 	}
 	webServer := web.NewServer(repoName)
-	apiHandler := api.NewHandler(gitService, repositories, webServer)
-	
-	// Initialize WebSocket hub for real-time dashboard updates
-	var dashboardHub *web.DashboardHub
-	if adesService != nil {
-		dashboardHub = web.NewDashboardHub(adesService)
-		go dashboardHub.Run()
-		log.Println("Dashboard WebSocket hub initialized")
-	}
+	webServer.SetRepoNameResolver(func(r *http.Request) string {
+		if service := api.GitServiceFromContext(r.Context()); service != nil {
+			return filepath.Base(service.GetRepoPath())
+		}
+		return ""
+	})
+	apiHandler := api.NewHandler(repoManager, webServer)
 	
-	// Initialize webhook handler for Sprint 7 integration
-	var webhookHandler *webhooks.WebhookHandler
-	if adesService != nil {
-		webhookSecret := os.Getenv("ADES_WEBHOOK_SECRET")
-		webhookHandler = webhooks.NewWebhookHandler(adesService, webhookSecret)
-		log.Println("Webhook handler initialized")
+	// Background jobs for long-running remote operations
+	jobManager := jobs.NewManager()
+	apiHandler.SetJobManager(jobManager)
+	repoManager.SetJobManager(jobManager)
+	if auditLog != nil {
+		jobManager.OnFinish(auditLog.RecordJob)
 	}
 	
 	// Initialize GraphQL API for Sprint 7 integration (temporarily disabled)
@@ -182,6 +200,19 @@ func main() {
 	
 	// Create router for better route management
 	router := mux.NewRouter()
+	if auditLog != nil {
+		router.Use(auditLog.Middleware(func(r *http.Request) string {
+			if service := api.GitServiceFromContext(r.Context()); service != nil {
+				return service.GetRepoPath()
+			}
+			return ""
+		}))
+		router.HandleFunc("/api/audit", auditLog.HandleQuery).Methods("GET")
+		router.HandleFunc("/api/audit/export", auditLog.HandleExport).Methods("GET")
+	}
+	router.Use(auth.Authorize(auth.DefaultRules))
+	router.HandleFunc("/api/auth/me", auth.HandleMe).Methods("GET")
+	router.HandleFunc("/api/auth/routes", auth.HandleRoutes(router, auth.DefaultRules)).Methods("GET")
 	
 	// Repository management endpoints
 	router.HandleFunc("/api/repositories", repoManager.HandleGetRepositories).Methods("GET")
@@ -189,6 +220,13 @@ func main() {
 	router.HandleFunc("/api/repositories/clone", repoManager.HandleCloneRepository).Methods("POST")
 	router.HandleFunc("/api/repositories/remove", repoManager.HandleRemoveRepository).Methods("DELETE")
 	router.HandleFunc("/api/repositories/discover", repoManager.HandleDiscoverRepositories).Methods("POST")
+	router.HandleFunc("/api/repositories/discover/preview", repoManager.HandlePreviewDiscovery).Methods("POST")
+	router.HandleFunc("/api/repositories/metadata", repoManager.HandleUpdateRepositoryMetadata).Methods("PUT")
+	router.HandleFunc("/api/repositories/groups", repoManager.HandleGetGroups).Methods("GET")
+	router.HandleFunc("/api/repositories/commits", repoManager.HandleRecentCommits).Methods("GET")
+	router.HandleFunc("/api/repositories/uncommitted", repoManager.HandleUncommittedWork).Methods("GET")
+	router.HandleFunc("/api/repositories/behind", repoManager.HandleBehindUpstream).Methods("GET")
+	router.HandleFunc("/api/repositories/search", repoManager.HandleSearch).Methods("POST")
 	
 	// Enhanced repository switching with manager integration
 	router.HandleFunc("/api/repository/switch", func(w http.ResponseWriter, r *http.Request) {
@@ -205,20 +243,13 @@ func main() {
 			return
 		}
 
-		// Use repository manager to switch
+		// Change the default repository; requests that select their own are unaffected
 		newGitService, err := repoManager.SwitchRepository(req.Path)
 		if err != nil {
 			http.Error(w, err.Error(), http.StatusBadRequest)
 			return
 		}
-
-		// Update the main git service and handlers
-		gitService = newGitService
-		apiHandler.SetGitService(gitService)
-		webServer.UpdateRepoName(gitService.GetRepoPath())
-
-		// Update repositories list
-		repositories = repoManager.GetRepositories()
+		webServer.UpdateRepoName(newGitService.GetRepoPath())
 
 		w.Header().Set("Content-Type", "application/json")
 		json.NewEncoder(w).Encode(map[string]string{"status": "success"})
@@ -231,16 +262,9 @@ func main() {
 			return
 		}
 
-		// Clear the current git service
-		gitService = nil
-		apiHandler.SetGitService(nil)
-		webServer.UpdateRepoName("No Repository Selected")
-		
-		// Clear current repository in manager
+		// Clear the default repository; requests that select their own are unaffected
 		repoManager.ClearCurrentRepository()
-		
-		// Update repositories list
-		repositories = repoManager.GetRepositories()
+		webServer.UpdateRepoName("No Repository Selected")
 
 		w.Header().Set("Content-Type", "application/json")
 		json.NewEncoder(w).Encode(map[string]string{"status": "success"})
@@ -255,13 +279,32 @@ func main() {
 	router.HandleFunc("/api/commits/tag/{tag}", apiHandler.GetCommitsByTag)
 	router.HandleFunc("/api/commits/tag/{tag}/html", apiHandler.GetCommitsByTagHTML)
 	router.HandleFunc("/api/stashes", apiHandler.GetStashes)
+	router.HandleFunc("/api/remotes", apiHandler.AddRemote).Methods("POST")
 	router.HandleFunc("/api/remotes", apiHandler.GetRemotes)
 	router.HandleFunc("/api/commit/create", apiHandler.CreateCommit).Methods("POST")
+	router.HandleFunc("/api/commit/lint", apiHandler.LintCommitMessage).Methods("POST")
+	router.HandleFunc("/api/commit/lint/config", apiHandler.CommitLintConfig).Methods("GET", "POST")
+	router.HandleFunc("/api/commit/cherry-pick", apiHandler.CherryPickCommit).Methods("POST")
+	router.HandleFunc("/api/commit/revert", apiHandler.RevertCommit).Methods("POST")
+	router.HandleFunc("/api/commit/{hash}/verify", apiHandler.VerifyCommit).Methods("GET")
+	router.HandleFunc("/api/signature/config", apiHandler.SignatureConfig).Methods("GET", "POST")
+	router.HandleFunc("/api/protection/config", apiHandler.ProtectionConfig).Methods("GET", "POST")
+	router.HandleFunc("/api/protection/blocked", apiHandler.GetBlockedOperations).Methods("GET")
 	router.HandleFunc("/api/commit/{hash}", apiHandler.GetCommitDetails)
 	router.HandleFunc("/api/diff", apiHandler.GetFileDiff)
 	router.HandleFunc("/api/file-content", apiHandler.GetFileContent)
 	router.HandleFunc("/api/file-content/save", apiHandler.SaveFileContent)
 	
+	// Patch export and apply
+	router.HandleFunc("/api/patch/format", apiHandler.FormatPatch).Methods("GET")
+	router.HandleFunc("/api/patch/diff", apiHandler.GetWorkingTreeDiff).Methods("GET")
+	router.HandleFunc("/api/patch/apply", apiHandler.ApplyPatch).Methods("POST")
+	
+	// Bundle and archive export
+	router.HandleFunc("/api/export/bundle", apiHandler.ExportBundle).Methods("GET")
+	router.HandleFunc("/api/export/archive", apiHandler.ExportArchive).Methods("GET")
+	router.HandleFunc("/api/import/bundle", apiHandler.ImportBundle).Methods("POST")
+	
 	// Branch operations
 	router.HandleFunc("/api/branch/checkout", apiHandler.CheckoutBranch)
 	router.HandleFunc("/api/branch/create", apiHandler.CreateBranch)
@@ -270,14 +313,53 @@ func main() {
 	router.HandleFunc("/api/branch/rename", apiHandler.RenameBranch)
 	router.HandleFunc("/api/branch/reset", apiHandler.ResetBranch)
 	router.HandleFunc("/api/branch/rebase", apiHandler.RebaseBranch)
+	router.HandleFunc("/api/branch/sync", apiHandler.SyncBranch).Methods("POST")
+	router.HandleFunc("/api/branches/cleanup", apiHandler.AnalyzeBranchCleanup).Methods("GET")
+	router.HandleFunc("/api/branches/cleanup", apiHandler.CleanupBranches).Methods("POST")
+	
+	// Cherry-pick and revert
+	router.HandleFunc("/api/cherry-pick", apiHandler.CherryPick).Methods("POST")
+	router.HandleFunc("/api/revert", apiHandler.Revert).Methods("POST")
+	router.HandleFunc("/api/sequence/status", apiHandler.GetSequenceStatus).Methods("GET")
+	router.HandleFunc("/api/sequence/continue", apiHandler.ContinueSequence).Methods("POST")
+	router.HandleFunc("/api/sequence/abort", apiHandler.AbortSequence).Methods("POST")
+	
+	// Bisect
+	router.HandleFunc("/api/bisect", apiHandler.GetBisectStatus).Methods("GET")
+	router.HandleFunc("/api/bisect/start", apiHandler.BisectStart).Methods("POST")
+	router.HandleFunc("/api/bisect/mark", apiHandler.BisectMark).Methods("POST")
+	router.HandleFunc("/api/bisect/reset", apiHandler.BisectReset).Methods("POST")
+	router.HandleFunc("/api/bisect/log", apiHandler.GetBisectLog).Methods("GET")
 	
 	// Tag operations
 	router.HandleFunc("/api/tag/create", apiHandler.CreateTag)
 	router.HandleFunc("/api/tag/push", apiHandler.PushTag)
 	router.HandleFunc("/api/tag/{tag}", apiHandler.DeleteTag).Methods("DELETE")
 	router.HandleFunc("/api/tag/{tag}/details", apiHandler.GetTagDetails)
+	router.HandleFunc("/api/tag/{tag}/verify", apiHandler.VerifyTag)
+	router.HandleFunc("/api/tag/{tag}/release-notes", apiHandler.GetReleaseNotes)
+	router.HandleFunc("/api/version/suggest", apiHandler.SuggestVersion).Methods("GET")
+	router.HandleFunc("/api/version/tag", apiHandler.CreateVersionTag).Methods("POST")
 	
 	router.HandleFunc("/api/fetch", apiHandler.Fetch)
+	router.HandleFunc("/api/deepen", apiHandler.Deepen).Methods("POST")
+	router.HandleFunc("/api/clone-info", apiHandler.GetCloneInfo).Methods("GET")
+	router.HandleFunc("/api/sparse-checkout", apiHandler.GetSparseCheckout).Methods("GET")
+	router.HandleFunc("/api/sparse-checkout", apiHandler.SetSparseCheckout).Methods("PUT")
+	router.HandleFunc("/api/sparse-checkout", apiHandler.DisableSparseCheckout).Methods("DELETE")
+	router.HandleFunc("/api/sparse-checkout/add", apiHandler.AddSparseCheckout).Methods("POST")
+	
+	// Background jobs with progress streaming
+	router.HandleFunc("/api/jobs", apiHandler.ListJobs).Methods("GET")
+	router.HandleFunc("/api/jobs/fetch", apiHandler.StartFetchJob).Methods("POST")
+	router.HandleFunc("/api/jobs/pull", apiHandler.StartPullJob).Methods("POST")
+	router.HandleFunc("/api/jobs/push", apiHandler.StartPushJob).Methods("POST")
+	router.HandleFunc("/api/jobs/clone", repoManager.HandleCloneRepositoryJob).Methods("POST")
+	router.HandleFunc("/api/jobs/deepen", apiHandler.StartDeepenJob).Methods("POST")
+	router.HandleFunc("/api/jobs/bisect-run", apiHandler.StartBisectRunJob).Methods("POST")
+	router.HandleFunc("/api/jobs/{id}", apiHandler.GetJob).Methods("GET")
+	router.HandleFunc("/api/jobs/{id}", apiHandler.CancelJob).Methods("DELETE")
+	router.HandleFunc("/api/jobs/{id}/events", apiHandler.StreamJobEvents).Methods("GET")
 	
 	// Stash operations
 	router.HandleFunc("/api/stash/create", apiHandler.CreateStash)
@@ -285,6 +367,8 @@ func main() {
 	router.HandleFunc("/api/stash/apply", apiHandler.ApplyStash)
 	router.HandleFunc("/api/stash/pop", apiHandler.PopStash)
 	router.HandleFunc("/api/stash/drop", apiHandler.DropStash)
+	router.HandleFunc("/api/stash/{stash}/diff", apiHandler.GetStashFileDiff).Methods("GET")
+	router.HandleFunc("/api/stash/{stash}/apply-file", apiHandler.ApplyStashFile).Methods("POST")
 	router.HandleFunc("/api/stash/{stash}", apiHandler.ShowStash)
 	
 	// Working directory operations
@@ -298,48 +382,30 @@ func main() {
 	router.HandleFunc("/api/remote/pull", apiHandler.PullFromRemote)
 	router.HandleFunc("/api/remote/push", apiHandler.PushToRemote)
 	router.HandleFunc("/api/remote/{remote}/info", apiHandler.GetRemoteInfo)
+	router.HandleFunc("/api/remote/{remote}/rename", apiHandler.RenameRemote).Methods("POST")
+	router.HandleFunc("/api/remote/{remote}/url", apiHandler.SetRemoteURL).Methods("PUT")
+	router.HandleFunc("/api/remote/{remote}/refspecs", apiHandler.RemoteRefspecs).Methods("GET", "PUT")
+	router.HandleFunc("/api/remote/{remote}/prune", apiHandler.PruneRemote).Methods("POST")
+	router.HandleFunc("/api/remote/{remote}", apiHandler.RemoveRemote).Methods("DELETE")
+	router.HandleFunc("/api/credentials", apiHandler.GetCredentials).Methods("GET")
+	router.HandleFunc("/api/credentials/{remote}", apiHandler.SaveCredential).Methods("PUT")
+	router.HandleFunc("/api/credentials/{remote}", apiHandler.DeleteCredential).Methods("DELETE")
 	
 	router.HandleFunc("/api/gait", apiHandler.GetGait)
 	router.HandleFunc("/api/settings", apiHandler.GetSettings)
 	router.HandleFunc("/api/search", apiHandler.Search)
 	
-	// ADES API endpoints
-	if adesHandler != nil {
-		router.HandleFunc("/api/ades/analyze", adesHandler.AnalyzeRepository)
-		router.HandleFunc("/api/ades/insights", adesHandler.GetDevelopmentInsights)
-		router.HandleFunc("/api/ades/patterns", adesHandler.ExtractReusablePatterns)
-		router.HandleFunc("/api/ades/search", adesHandler.SearchExperiences)
-		router.HandleFunc("/api/ades/analytics", adesHandler.GetAnalytics)
-		router.HandleFunc("/api/ades/metrics", adesHandler.GetRepositoryMetrics)
-		router.HandleFunc("/api/ades/dashboard", adesHandler.GetDashboardData)
-		router.HandleFunc("/api/ades/similar", adesHandler.GetSimilarImplementations)
-		router.HandleFunc("/api/ades/semantic/analyze", adesHandler.AnalyzeCommitSemantics)
-		router.HandleFunc("/api/ades/knowledge/query", adesHandler.QueryKnowledgeGraph)
-		router.HandleFunc("/api/ades/knowledge/export", adesHandler.ExportKnowledgeGraph)
-		
-		// MCP endpoints for Sprint 3
-		if mcpServer != nil {
-			mcpServer.RegisterRoutes(router)
-		}
-		
-		// WebSocket endpoint for real-time dashboard updates
-		if dashboardHub != nil {
-			router.HandleFunc("/ws/dashboard", dashboardHub.HandleWebSocket)
-		}
-		
-		// Webhook endpoints for Sprint 7 integration
-		if webhookHandler != nil {
-			router.HandleFunc("/webhooks/github", webhookHandler.HandleGitHubWebhook)
-			router.HandleFunc("/webhooks/generic", webhookHandler.HandleGenericWebhook)
-		}
-		
-		// GraphQL endpoint for Sprint 7 integration (temporarily disabled)
-		// if graphqlHandler != nil {
-		// 	router.HandleFunc("/graphql", graphqlHandler.HandleGraphQL)
-		// 	router.HandleFunc("/graphiql", graphqlHandler.HandleGraphiQL)
-		// }
+	// ADES, MCP, dashboard WebSocket and webhook endpoints of the selected repository
+	for _, prefix := range []string{"/api/ades/", "/api/mcp/", "/mcp/", "/ws/", "/webhooks/"} {
+		router.PathPrefix(prefix).Handler(adesPool)
 	}
 	
+	// GraphQL endpoint for Sprint 7 integration (temporarily disabled)
+	// if graphqlHandler != nil {
+	// 	router.HandleFunc("/graphql", graphqlHandler.HandleGraphQL)
+	// 	router.HandleFunc("/graphiql", graphqlHandler.HandleGraphiQL)
+	// }
+	
 	// API documentation endpoints for Sprint 7
 	router.HandleFunc("/docs", docsHandler.ServeAPIDocs)
 	router.HandleFunc("/docs/swagger", docsHandler.ServeSwaggerUI)
@@ -370,9 +436,9 @@ func main() {
 	// Start server
 	fmt.Printf("🌳 GAIT Server Starting (Enhanced + Multi-Repository Support)\n")
 	fmt.Printf("Port: %s\n", *port)
-	if len(repositories) > 0 {
+	if repositories := repoManager.GetRepositories(); len(repositories) > 0 {
 		fmt.Printf("Repositories: %d managed\n", len(repositories))
-		fmt.Printf("Current: %s\n", filepath.Base(gitService.GetRepoPath()))
+		fmt.Printf("Default: %s\n", repoName)
 	} else {
 		fmt.Printf("No repositories found - you can add them through the web interface\n")
 	}
@@ -396,5 +462,105 @@ func main() {
 	fmt.Printf("📊 Dashboard: http://localhost:%s/dashboard\n", *port)
 	fmt.Printf("📚 API Docs: http://localhost:%s/docs\n", *port)
 
-	log.Fatal(http.ListenAndServe(":"+*port, router))
-} 
\ No newline at end of file
+	var handler http.Handler = repoManager.SelectRepository(router)
+	if authService != nil {
+		fmt.Printf("🔒 Authentication: %s\n", *authConfig)
+		handler = authService.Authenticate(handler)
+	} else {
+		fmt.Printf("⚠️  Authentication is off: anyone who can reach port %s has full access (see %s)\n", *port, *authConfig)
+	}
+	log.Fatal(http.ListenAndServe(":"+*port, handler))
+} 
+
+// repositoryADES holds the router serving the ADES endpoints of one repository
+type repositoryADES struct {
+	router *mux.Router
+}
+
+// adesPool starts ADES, MCP, the dashboard hub and webhooks for each repository on
+// first use and routes requests to the instance of the repository they select
+type adesPool struct {
+	config        *config.Config
+	webhookSecret string
+
+	mu        sync.Mutex
+	instances map[string]*repositoryADES
+}
+
+// newADESPool creates an empty ADES pool
+func newADESPool(adesConfig *config.Config, webhookSecret string) *adesPool {
+	return &adesPool{
+		config:        adesConfig,
+		webhookSecret: webhookSecret,
+		instances:     make(map[string]*repositoryADES),
+	}
+}
+
+// instance returns the ADES instance of a repository, starting it if needed. It
+// returns nil if ADES failed to start for the repository.
+func (p *adesPool) instance(gitService *git.Service) *repositoryADES {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	repoPath := gitService.GetRepoPath()
+	if instance, ok := p.instances[repoPath]; ok {
+		return instance
+	}
+
+	log.Printf("Initializing ADES service for %s...", repoPath)
+	// The instance outlives the request that starts it, so it must not use a service
+	// bound to that request's context
+	adesService, err := ades.NewService(gitService.WithContext(context.Background()), p.config)
+	if err != nil {
+		log.Printf("Warning: Failed to initialize ADES service: %v", err)
+		p.instances[repoPath] = nil
+		return nil
+	}
+
+	router := mux.NewRouter()
+	adesHandler := api.NewADESHandler(adesService)
+	router.HandleFunc("/api/ades/analyze", adesHandler.AnalyzeRepository)
+	router.HandleFunc("/api/ades/insights", adesHandler.GetDevelopmentInsights)
+	router.HandleFunc("/api/ades/patterns", adesHandler.ExtractReusablePatterns)
+	router.HandleFunc("/api/ades/search", adesHandler.SearchExperiences)
+	router.HandleFunc("/api/ades/analytics", adesHandler.GetAnalytics)
+	router.HandleFunc("/api/ades/metrics", adesHandler.GetRepositoryMetrics)
+	router.HandleFunc("/api/ades/dashboard", adesHandler.GetDashboardData)
+	router.HandleFunc("/api/ades/similar", adesHandler.GetSimilarImplementations)
+	router.HandleFunc("/api/ades/semantic/analyze", adesHandler.AnalyzeCommitSemantics)
+	router.HandleFunc("/api/ades/knowledge/query", adesHandler.QueryKnowledgeGraph)
+	router.HandleFunc("/api/ades/knowledge/export", adesHandler.ExportKnowledgeGraph)
+
+	// MCP endpoints for Sprint 3
+	mcp.NewMCPServer(adesService).RegisterRoutes(router)
+
+	// WebSocket endpoint for real-time dashboard updates
+	dashboardHub := web.NewDashboardHub(adesService)
+	go dashboardHub.Run()
+	router.HandleFunc("/ws/dashboard", dashboardHub.HandleWebSocket)
+
+	// Webhook endpoints for Sprint 7 integration
+	webhookHandler := webhooks.NewWebhookHandler(adesService, p.webhookSecret)
+	router.HandleFunc("/webhooks/github", webhookHandler.HandleGitHubWebhook)
+	router.HandleFunc("/webhooks/generic", webhookHandler.HandleGenericWebhook)
+
+	instance := &repositoryADES{router: router}
+	p.instances[repoPath] = instance
+	log.Printf("ADES service initialized for %s", repoPath)
+	return instance
+}
+
+// ServeHTTP serves an ADES endpoint of the repository selected for the request
+func (p *adesPool) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	gitService := api.GitServiceFromContext(r.Context())
+	if gitService == nil {
+		http.Error(w, "No repository selected", http.StatusNotFound)
+		return
+	}
+	instance := p.instance(gitService)
+	if instance == nil {
+		http.Error(w, "ADES is not available for this repository", http.StatusServiceUnavailable)
+		return
+	}
+	instance.router.ServeHTTP(w, r)
+}
@@ -4,9 +4,72 @@ import "time"
 
 // Repository represents a Git repository
 type Repository struct {
+	ID          string     `json:"id"`
+	Name        string     `json:"name"`
+	Path        string     `json:"path"`
+	Current     bool       `json:"current"`
+	DisplayName string     `json:"displayName,omitempty"`
+	Group       string     `json:"group,omitempty"`
+	Tags        []string   `json:"tags,omitempty"`
+	LastOpened  *time.Time `json:"lastOpened,omitempty"`
+	Pinned      bool       `json:"pinned"`
+	Layout      string     `json:"layout,omitempty"`
+	GitDir      string     `json:"gitDir,omitempty"`
+}
+
+// DiscoveredRepository is a repository found by discovery, before it is added
+type DiscoveredRepository struct {
 	Name    string `json:"name"`
 	Path    string `json:"path"`
-	Current bool   `json:"current"`
+	GitDir  string `json:"gitDir,omitempty"`
+	Layout  string `json:"layout"`
+	Depth   int    `json:"depth"`
+	Managed bool   `json:"managed"`
+}
+
+// RepositoryGroup summarises a named group of managed repositories
+type RepositoryGroup struct {
+	Name         string   `json:"name"`
+	Repositories int      `json:"repositories"`
+	Tags         []string `json:"tags"`
+}
+
+// RepositoryError is a failure to read one repository in a cross-repository view
+type RepositoryError struct {
+	RepositoryID   string `json:"repositoryId"`
+	RepositoryName string `json:"repositoryName"`
+	Error          string `json:"error"`
+}
+
+// RepositoryCommit is a commit in a cross-repository commit feed
+type RepositoryCommit struct {
+	RepositoryID   string `json:"repositoryId"`
+	RepositoryName string `json:"repositoryName"`
+	Commit
+}
+
+// RepositoryWork lists the uncommitted changes of a repository's working tree
+type RepositoryWork struct {
+	RepositoryID   string       `json:"repositoryId"`
+	RepositoryName string       `json:"repositoryName"`
+	Branch         string       `json:"branch"`
+	Changes        []FileChange `json:"changes"`
+}
+
+// RepositoryBehind lists the branches of a repository that are behind their upstream
+type RepositoryBehind struct {
+	RepositoryID   string   `json:"repositoryId"`
+	RepositoryName string   `json:"repositoryName"`
+	Branches       []Branch `json:"branches"`
+}
+
+// RepositoryView is the result of a view computed across several repositories
+type RepositoryView struct {
+	Group        string            `json:"group,omitempty"`
+	Tag          string            `json:"tag,omitempty"`
+	Repositories int               `json:"repositories"`
+	Items        interface{}       `json:"items"`
+	Errors       []RepositoryError `json:"errors"`
 }
 
 // Author represents a commit author
@@ -39,38 +102,108 @@ type FileChange struct {
 
 // Commit represents a Git commit
 type Commit struct {
-	Hash           string       `json:"hash"`
-	ShortHash      string       `json:"shortHash"`
-	Message        string       `json:"message"`
-	Author         Author       `json:"author"`
-	Committer      Author       `json:"committer"`
-	Date           time.Time    `json:"date"`
-	CommitDate     time.Time    `json:"commitDate"`
-	Parents        []string     `json:"parents"`
-	Refs           []string     `json:"refs"`
-	Stats          CommitStats  `json:"stats"`
-	FileChanges    []FileChange `json:"fileChanges,omitempty"`
-	IsUncommitted  bool         `json:"isUncommitted,omitempty"`
+	Hash          string           `json:"hash"`
+	ShortHash     string           `json:"shortHash"`
+	Message       string           `json:"message"`
+	Author        Author           `json:"author"`
+	Committer     Author           `json:"committer"`
+	Date          time.Time        `json:"date"`
+	CommitDate    time.Time        `json:"commitDate"`
+	Parents       []string         `json:"parents"`
+	Refs          []string         `json:"refs"`
+	Stats         CommitStats      `json:"stats"`
+	FileChanges   []FileChange     `json:"fileChanges,omitempty"`
+	IsUncommitted bool             `json:"isUncommitted,omitempty"`
+	Signature     *SignatureStatus `json:"signature,omitempty"`
 }
 
 // Branch represents a Git branch
 type Branch struct {
-	Name      string `json:"name"`
-	Hash      string `json:"hash"`
-	IsRemote  bool   `json:"isRemote"`
-	IsCurrent bool   `json:"isCurrent"`
-	Upstream  string `json:"upstream,omitempty"`
+	Name         string     `json:"name"`
+	Hash         string     `json:"hash"`
+	IsRemote     bool       `json:"isRemote"`
+	IsCurrent    bool       `json:"isCurrent"`
+	Upstream     string     `json:"upstream,omitempty"`
+	Ahead        int        `json:"ahead"`
+	Behind       int        `json:"behind"`
+	UpstreamGone bool       `json:"upstreamGone,omitempty"`
+	LastFetch    *time.Time `json:"lastFetch,omitempty"`
+}
+
+// BranchCleanupCandidate is a branch that branch cleanup suggests deleting
+type BranchCleanupCandidate struct {
+	Name           string    `json:"name"`
+	Remote         string    `json:"remote,omitempty"`
+	IsRemote       bool      `json:"isRemote"`
+	Hash           string    `json:"hash"`
+	Upstream       string    `json:"upstream,omitempty"`
+	LastCommitDate time.Time `json:"lastCommitDate"`
+	Reasons        []string  `json:"reasons"` // merged, squash-merged, stale, upstream-gone, orphaned
+}
+
+// BranchCleanupReport lists cleanup candidates relative to a base branch
+type BranchCleanupReport struct {
+	Base      string                   `json:"base"`
+	StaleDays int                      `json:"staleDays"`
+	Branches  []BranchCleanupCandidate `json:"branches"`
+}
+
+// BranchRef names a local branch, or a branch on a remote when Remote is set
+type BranchRef struct {
+	Name   string `json:"name"`
+	Remote string `json:"remote,omitempty"`
+}
+
+// BranchDeleteResult reports the outcome of deleting one branch
+type BranchDeleteResult struct {
+	Name    string `json:"name"`
+	Remote  string `json:"remote,omitempty"`
+	Command string `json:"command,omitempty"`
+	DryRun  bool   `json:"dryRun"`
+	Deleted bool   `json:"deleted"`
+	Output  string `json:"output,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+// SyncStep is the outcome of one step of a branch sync
+type SyncStep struct {
+	Name   string `json:"name"`   // fetch, pull, push
+	Status string `json:"status"` // success, failed, skipped
+	Output string `json:"output,omitempty"`
+}
+
+// SyncResult reports a pull-then-push sync of a branch with its upstream
+type SyncResult struct {
+	Branch   string     `json:"branch"`
+	Upstream string     `json:"upstream"`
+	Mode     string     `json:"mode"` // rebase, ff-only
+	Steps    []SyncStep `json:"steps"`
+	Ahead    int        `json:"ahead"`
+	Behind   int        `json:"behind"`
+	Success  bool       `json:"success"`
 }
 
 // Tag represents a Git tag
 type Tag struct {
-	Name       string    `json:"name"`
-	Hash       string    `json:"hash"`
-	Type       string    `json:"type"` // lightweight, annotated
-	Message    string    `json:"message,omitempty"`
-	Tagger     Author    `json:"tagger,omitempty"`
-	Date       time.Time `json:"date,omitempty"`
-	TargetHash string    `json:"targetHash,omitempty"`
+	Name       string           `json:"name"`
+	Hash       string           `json:"hash"`
+	Type       string           `json:"type"` // lightweight, annotated
+	Message    string           `json:"message,omitempty"`
+	Tagger     Author           `json:"tagger,omitempty"`
+	Date       time.Time        `json:"date,omitempty"`
+	TargetHash string           `json:"targetHash,omitempty"`
+	Signature  *SignatureStatus `json:"signature,omitempty"`
+}
+
+// SignatureStatus represents the result of verifying a GPG or SSH signature
+type SignatureStatus struct {
+	Signed   bool   `json:"signed"`
+	Verified bool   `json:"verified"`
+	Status   string `json:"status"`           // good, bad, untrusted, expired, revoked, unknown-key, none
+	Format   string `json:"format,omitempty"` // gpg, ssh
+	Signer   string `json:"signer,omitempty"`
+	Key      string `json:"key,omitempty"`
+	Output   string `json:"output,omitempty"`
 }
 
 // Stash represents a Git stash entry
@@ -86,9 +219,20 @@ type Stash struct {
 
 // Remote represents a Git remote
 type Remote struct {
-	Name     string `json:"name"`
-	FetchURL string `json:"fetchUrl"`
-	PushURL  string `json:"pushUrl"`
+	Name          string         `json:"name"`
+	FetchURL      string         `json:"fetchUrl"`
+	PushURL       string         `json:"pushUrl"`
+	HeadBranch    string         `json:"headBranch,omitempty"`
+	FetchRefspecs []string       `json:"fetchRefspecs,omitempty"`
+	Branches      []RemoteBranch `json:"branches,omitempty"`
+}
+
+// RemoteBranch represents a branch on a remote and how it is tracked locally
+type RemoteBranch struct {
+	Name           string   `json:"name"`
+	Status         string   `json:"status"`         // tracked, new, stale or unknown when the remote was not queried
+	TrackedLocally bool     `json:"trackedLocally"` // a local branch has it as upstream
+	LocalBranches  []string `json:"localBranches,omitempty"`
 }
 
 // FileDiff represents a file diff
@@ -144,17 +288,37 @@ type CommitGait struct {
 
 // SearchRequest represents a search request
 type SearchRequest struct {
-	Query      string `json:"query"`
-	Type       string `json:"type"` // message, author, hash, file
-	MaxResults int    `json:"maxResults"`
+	Query      string   `json:"query"`
+	Type       string   `json:"type"`  // message, author, hash, path (or file) or code; empty searches all but code
+	Types      []string `json:"types"` // several of the above; takes precedence over Type
+	MaxResults int      `json:"maxResults"`
+
+	// Repository selection for searches across managed repositories; empty searches all
+	Group        string   `json:"group,omitempty"`
+	Tag          string   `json:"tag,omitempty"`
+	Repositories []string `json:"repositories,omitempty"` // repository IDs or paths
 }
 
 // SearchResult represents a search result
 type SearchResult struct {
-	CommitHash string `json:"commitHash"`
-	Type       string `json:"type"`
-	Match      string `json:"match"`
-	Context    string `json:"context"`
+	CommitHash     string    `json:"commitHash"`
+	Type           string    `json:"type"`
+	Match          string    `json:"match"`
+	Context        string    `json:"context"`
+	Subject        string    `json:"subject"`
+	Author         string    `json:"author"`
+	Date           time.Time `json:"date"`
+	Paths          []string  `json:"paths,omitempty"`
+	Score          float64   `json:"score"`
+	RepositoryID   string    `json:"repositoryId,omitempty"`
+	RepositoryName string    `json:"repositoryName,omitempty"`
+}
+
+// SearchResponse holds ranked search results and the repositories that failed
+type SearchResponse struct {
+	Results      []SearchResult    `json:"results"`
+	Repositories int               `json:"repositories"`
+	Errors       []RepositoryError `json:"errors"`
 }
 
 // RepoSettings represents repository settings
@@ -165,4 +329,189 @@ type RepoSettings struct {
 	GaitColors        []string `json:"gaitColors"`
 	ShowUncommitted    bool     `json:"showUncommitted"`
 	ShowRemoteBranches bool     `json:"showRemoteBranches"`
-} 
\ No newline at end of file
+}
+
+// PatchHunkFailure represents a patch hunk that could not be applied
+type PatchHunkFailure struct {
+	Path    string `json:"path"`
+	Line    int    `json:"line"`
+	Hunk    int    `json:"hunk,omitempty"`
+	Context string `json:"context,omitempty"`
+}
+
+// PatchApplyResult represents the outcome of applying a patch or mbox
+type PatchApplyResult struct {
+	Mode     string             `json:"mode"` // apply, am
+	Checked  bool               `json:"checked"`
+	Applied  bool               `json:"applied"`
+	Files    []string           `json:"files"`
+	Failures []PatchHunkFailure `json:"failures"`
+	Output   string             `json:"output,omitempty"`
+}
+
+// SequenceResult represents the state of a multi-commit cherry-pick or revert
+type SequenceResult struct {
+	Operation     string   `json:"operation"` // cherry-pick, revert; empty when none is in progress
+	Branch        string   `json:"branch"`
+	Commits       []string `json:"commits"`
+	InProgress    bool     `json:"inProgress"`
+	CurrentCommit string   `json:"currentCommit,omitempty"` // the commit the sequence stopped at
+	ConflictFiles []string `json:"conflictFiles"`
+	Remaining     []string `json:"remaining"` // commits still to be picked after the current one
+	Output        string   `json:"output,omitempty"`
+}
+
+// BisectStatus represents the state of a git bisect session
+type BisectStatus struct {
+	InProgress     bool     `json:"inProgress"`
+	Bad            string   `json:"bad,omitempty"`
+	Good           []string `json:"good"`
+	Skipped        []string `json:"skipped"`
+	Current        string   `json:"current,omitempty"` // the commit checked out for testing
+	CurrentSubject string   `json:"currentSubject,omitempty"`
+	Remaining      int      `json:"remaining"` // candidate commits left to test
+	Steps          int      `json:"steps"`     // roughly how many more tests are needed
+	FirstBad       string   `json:"firstBad,omitempty"`
+	Output         string   `json:"output,omitempty"`
+}
+
+// ReleaseNoteEntry represents a commit listed in release notes
+type ReleaseNoteEntry struct {
+	Hash      string `json:"hash"`
+	ShortHash string `json:"shortHash"`
+	Type      string `json:"type"`
+	Scope     string `json:"scope,omitempty"`
+	Subject   string `json:"subject"`
+	Author    string `json:"author"`
+	Breaking  bool   `json:"breaking"`
+}
+
+// ReleaseNoteGroup represents release note entries sharing a conventional-commit type
+type ReleaseNoteGroup struct {
+	Type    string             `json:"type"`
+	Title   string             `json:"title"`
+	Entries []ReleaseNoteEntry `json:"entries"`
+}
+
+// ReleaseNotes represents the changes between a tag and its predecessor
+type ReleaseNotes struct {
+	Tag         string             `json:"tag"`
+	PreviousTag string             `json:"previousTag,omitempty"`
+	Date        time.Time          `json:"date"`
+	Groups      []ReleaseNoteGroup `json:"groups"`
+	Breaking    []ReleaseNoteEntry `json:"breaking"`
+	Markdown    string             `json:"markdown"`
+}
+
+// VersionBump represents a semantic version bump suggested from commit history
+type VersionBump struct {
+	CurrentTag     string             `json:"currentTag,omitempty"`
+	CurrentVersion string             `json:"currentVersion"`
+	Bump           string             `json:"bump"` // major, minor, patch, none
+	NextVersion    string             `json:"nextVersion"`
+	NextTag        string             `json:"nextTag"`
+	Candidates     map[string]string  `json:"candidates"`
+	Commits        []ReleaseNoteEntry `json:"commits"`
+	Changelog      string             `json:"changelog"`
+	Created        bool               `json:"created"`
+}
+
+// CommitLintViolation represents a commit message policy violation
+type CommitLintViolation struct {
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+	Line    int    `json:"line,omitempty"`
+}
+
+// JobProgress represents the latest progress reported by a background job
+type JobProgress struct {
+	Phase   string `json:"phase"` // e.g. "Receiving objects"
+	Percent int    `json:"percent"`
+	Current int64  `json:"current"`
+	Total   int64  `json:"total"`
+	Message string `json:"message,omitempty"`
+}
+
+// Job represents a background operation such as a fetch, pull, push or clone
+type Job struct {
+	ID           string       `json:"id"`
+	Type         string       `json:"type"`                   // fetch, pull, push, clone, bisect-run
+	RepositoryID string       `json:"repositoryId,omitempty"` // repository the job runs in; empty for clones
+	Target       string       `json:"target"`                 // repository path or clone URL
+	Status       string       `json:"status"`                 // running, succeeded, failed, cancelled
+	Progress     *JobProgress `json:"progress,omitempty"`
+	Result       interface{}  `json:"result,omitempty"`
+	Error        string       `json:"error,omitempty"`
+	ErrorCode    string       `json:"errorCode,omitempty"` // e.g. "auth_failed"
+	StartedAt    time.Time    `json:"startedAt"`
+	FinishedAt   *time.Time   `json:"finishedAt,omitempty"`
+}
+
+// RefUpdate represents one ref updated, or refused, by a fetch or push
+type RefUpdate struct {
+	Status  string `json:"status"` // fast-forward, forced, new, deleted, rejected, up-to-date, tag-update
+	From    string `json:"from,omitempty"`
+	To      string `json:"to"`
+	Summary string `json:"summary,omitempty"`
+	Reason  string `json:"reason,omitempty"`
+}
+
+// RemoteOperationResult represents the structured outcome of a fetch, pull, push or clone
+type RemoteOperationResult struct {
+	Operation string      `json:"operation"`
+	Remote    string      `json:"remote,omitempty"`
+	Updated   []RefUpdate `json:"updated"`
+	Rejected  []RefUpdate `json:"rejected,omitempty"`
+	Output    string      `json:"output,omitempty"`
+	Path      string      `json:"path,omitempty"` // clone destination
+}
+
+// CloneOptions narrows what a clone fetches and checks out
+type CloneOptions struct {
+	Branch       string   `json:"branch,omitempty"`       // branch to check out instead of the remote's HEAD
+	SingleBranch bool     `json:"singleBranch,omitempty"` // fetch only that branch
+	Depth        int      `json:"depth,omitempty"`        // shallow clone of this many commits
+	Filter       string   `json:"filter,omitempty"`       // partial clone filter, such as blob:none
+	Sparse       []string `json:"sparse,omitempty"`       // sparse-checkout cone directories
+}
+
+// SparseCheckout is the sparse-checkout state of a working tree
+type SparseCheckout struct {
+	Enabled  bool     `json:"enabled"`
+	Cone     bool     `json:"cone"`
+	Patterns []string `json:"patterns"`
+}
+
+// CloneInfo describes how much of its remote a clone holds
+type CloneInfo struct {
+	Shallow        bool           `json:"shallow"`
+	ShallowCommits int            `json:"shallowCommits,omitempty"` // commits whose parents are missing
+	Filter         string         `json:"filter,omitempty"`         // partial clone filter of origin
+	SingleBranch   bool           `json:"singleBranch"`
+	SparseCheckout SparseCheckout `json:"sparseCheckout"`
+}
+
+// RemoteCredential holds the credentials used for one remote's fetch, pull and push
+type RemoteCredential struct {
+	Remote     string `json:"remote"`
+	Username   string `json:"username,omitempty"`
+	Token      string `json:"token,omitempty"`      // HTTPS token or password; never returned by the API
+	SSHKeyPath string `json:"sshKeyPath,omitempty"` // private key used for SSH remotes
+}
+
+// CredentialInfo describes a stored remote credential without its secret
+type CredentialInfo struct {
+	Remote     string `json:"remote"`
+	Username   string `json:"username,omitempty"`
+	HasToken   bool   `json:"hasToken"`
+	SSHKeyPath string `json:"sshKeyPath,omitempty"`
+}
+
+// BlockedOperation records an attempt to force-push, delete or hard reset a protected branch
+type BlockedOperation struct {
+	Time      time.Time `json:"time"`
+	Operation string    `json:"operation"` // force-push, delete, hard-reset
+	Branch    string    `json:"branch"`
+	Remote    string    `json:"remote,omitempty"`
+	Pattern   string    `json:"pattern"`
+}